@@ -4,12 +4,15 @@ import (
   "rootlang/lexer"
   "bytes"
   "fmt"
+  "strconv"
   "strings"
+  "math/big"
 )
 
 type Node interface {
   TokenLiteral() string
   String() string
+  Position() lexer.Position
 }
 type Statement interface {
   Node
@@ -19,10 +22,16 @@ type Expression interface {
   Node
   expressionNode()
 }
+type Pattern interface {
+  Node
+  patternNode()
+}
 
+// IntegerLiteral keeps in Big the literals that do not fit in an int64
 type IntegerLiteral struct {
   Token lexer.Token
   Value int64
+  Big   *big.Int
 }
 
 func (int *IntegerLiteral) expressionNode() {
@@ -33,10 +42,37 @@ func (int *IntegerLiteral) TokenLiteral() string {
   return int.Token.Literal
 }
 
+func (int *IntegerLiteral) Position() lexer.Position {
+  return int.Token.Position
+}
+
 func (int *IntegerLiteral) String() string {
+  if int.Big != nil {
+    return int.Big.String()
+  }
   return fmt.Sprintf("%d", int.Value)
 }
 
+type FloatLiteral struct {
+  Token lexer.Token
+  Value float64
+}
+
+func (float *FloatLiteral) expressionNode() {
+
+}
+
+func (float *FloatLiteral) TokenLiteral() string {
+  return float.Token.Literal
+}
+
+func (float *FloatLiteral) Position() lexer.Position {
+  return float.Token.Position
+}
+
+func (float *FloatLiteral) String() string {
+  return strconv.FormatFloat(float.Value, 'g', -1, 64)
+}
 
 type  ParamsExpression struct{
   Token lexer.Token
@@ -53,6 +89,10 @@ func (params *ParamsExpression) TokenLiteral() string {
   return ""
 }
 
+func (params *ParamsExpression) Position() lexer.Position {
+  return params.Token.Position
+}
+
 func (params *ParamsExpression) String() string {
   buffer := bytes.NewBufferString("(")
   paramsText := make([]string, 0)
@@ -79,6 +119,10 @@ func (str *StringExpression) TokenLiteral() string {
   return str.Token.Literal
 }
 
+func (str *StringExpression) Position() lexer.Position {
+  return str.Token.Position
+}
+
 func (str *StringExpression) String() string {
   return str.Value
 }
@@ -93,6 +137,10 @@ func (prefix *PrefixExpression) TokenLiteral() string {
   return prefix.Token.Literal
 }
 
+func (prefix *PrefixExpression) Position() lexer.Position {
+  return prefix.Token.Position
+}
+
 func (prefix *PrefixExpression) expressionNode() {
 
 }
@@ -105,18 +153,60 @@ func (prefix *PrefixExpression) String() string {
   return buffer.String()
 }
 
+// LetStatement with Exported set was declared with export let, its name can be used from the modules that import it
 type LetStatement struct {
+  Token    lexer.Token
+  Name     *Identifier
+  Value    Expression
+  Exported bool
+}
+
+// ExportStatement lists names declared in the module that can be used from the modules that import it
+type ExportStatement struct {
   Token lexer.Token
-  Name  *Identifier
-  Value Expression
+  Names []*Identifier
+}
+
+func (ex *ExportStatement) statementNode() {
+}
+
+func (ex *ExportStatement) TokenLiteral() string {
+  return ex.Token.Literal
 }
 
+func (ex *ExportStatement) Position() lexer.Position {
+  return ex.Token.Position
+}
+
+func (ex *ExportStatement) String() string {
+  names := make([]string, 0)
+  for _, name := range ex.Names {
+    names = append(names, name.String())
+  }
+  return fmt.Sprintf("export (%s);", strings.Join(names, ", "))
+}
+
+// ImportStatement binds the module to Name, or only the chosen Symbols when it is import { a, b as c } from "path"
 type ImportStatement struct{
   Token lexer.Token
   Path string
   Name  *Identifier
+  Symbols []*ImportSymbol
 };
 
+// ImportSymbol is a name of the module bound to Alias in the module that imports it
+type ImportSymbol struct {
+  Name  *Identifier
+  Alias *Identifier
+}
+
+func (symbol *ImportSymbol) String() string {
+  if symbol.Name.Value == symbol.Alias.Value {
+    return symbol.Name.String()
+  }
+  return fmt.Sprintf("%s as %s", symbol.Name.String(), symbol.Alias.String())
+}
+
 func (im *ImportStatement) statementNode() {
 }
 
@@ -124,8 +214,18 @@ func (im *ImportStatement) TokenLiteral() string {
   return im.Token.Literal
 }
 
-func (im *ImportStatement) String() string {
+func (im *ImportStatement) Position() lexer.Position {
+  return im.Token.Position
+}
 
+func (im *ImportStatement) String() string {
+  if im.Symbols != nil {
+    symbols := make([]string, 0)
+    for _, symbol := range im.Symbols {
+      symbols = append(symbols, symbol.String())
+    }
+    return fmt.Sprintf(`import { %s } from "%s"`, strings.Join(symbols, ", "), im.Path)
+  }
   return fmt.Sprintf(`import "%s" as %s`, im.Path, im.Name.String())
 }
 
@@ -136,8 +236,15 @@ func (let *LetStatement) TokenLiteral() string {
   return let.Token.Literal
 }
 
+func (let *LetStatement) Position() lexer.Position {
+  return let.Token.Position
+}
+
 func (let *LetStatement) String() string {
   var buffer *bytes.Buffer = bytes.NewBufferString("let ");
+  if let.Exported {
+    buffer = bytes.NewBufferString("export let ")
+  }
   buffer.WriteString(let.Name.String())
   buffer.WriteString(" = ")
   buffer.WriteString(let.Value.String())
@@ -164,6 +271,10 @@ func (ret *ReturnStatement) TokenLiteral() string {
   return ret.Token.Literal
 }
 
+func (ret *ReturnStatement) Position() lexer.Position {
+  return ret.Token.Position
+}
+
 type ExpressionStatement struct {
   Exp Expression
 }
@@ -180,6 +291,13 @@ func (exp *ExpressionStatement) TokenLiteral() string {
   return exp.Exp.TokenLiteral()
 }
 
+func (exp *ExpressionStatement) Position() lexer.Position {
+  if exp.Exp == nil {
+    return lexer.Position{}
+  }
+  return exp.Exp.Position()
+}
+
 type BlockStatement struct {
   Token      lexer.Token
   Statements []Statement
@@ -199,6 +317,10 @@ func (block *BlockStatement) TokenLiteral() string {
   return block.Token.Literal
 }
 
+func (block *BlockStatement) Position() lexer.Position {
+  return block.Token.Position
+}
+
 type IfExpression struct {
   Token            lexer.Token
   Condition        Expression
@@ -221,6 +343,149 @@ func (ifExp *IfExpression) TokenLiteral() string {
   return ifExp.Token.Literal
 }
 
+func (ifExp *IfExpression) Position() lexer.Position {
+  return ifExp.Token.Position
+}
+
+type TryExpression struct {
+  Token      lexer.Token
+  Block      *BlockStatement
+  ErrorName  *Identifier
+  CatchBlock *BlockStatement
+  Locals     []string
+}
+
+func (try *TryExpression) expressionNode() {}
+func (try *TryExpression) String() string {
+  buffer := bytes.NewBufferString("try")
+  buffer.WriteString(try.Block.String())
+  buffer.WriteString("catch")
+  if try.ErrorName != nil {
+    buffer.WriteString(fmt.Sprintf("(%s)", try.ErrorName.String()))
+  }
+  buffer.WriteString(try.CatchBlock.String())
+  return buffer.String()
+}
+
+func (try *TryExpression) TokenLiteral() string {
+  return try.Token.Literal
+}
+
+func (try *TryExpression) Position() lexer.Position {
+  return try.Token.Position
+}
+
+// Locals of functions and match arms are the slots of their frame filled by the resolver, nil when the program was not resolved
+type MatchArm struct {
+  Pattern Pattern
+  Guard   Expression
+  Body    Node
+  Locals  []string
+}
+
+func (arm *MatchArm) String() string {
+  buffer := bytes.NewBufferString(arm.Pattern.String())
+  if arm.Guard != nil {
+    buffer.WriteString(fmt.Sprintf(" if %s", arm.Guard.String()))
+  }
+  buffer.WriteString(" => ")
+  buffer.WriteString(arm.Body.String())
+  return buffer.String()
+}
+
+type MatchExpression struct {
+  Token lexer.Token
+  Value Expression
+  Arms  []*MatchArm
+}
+
+func (match *MatchExpression) expressionNode() {}
+func (match *MatchExpression) String() string {
+  arms := make([]string, 0)
+  for _, arm := range match.Arms {
+    arms = append(arms, arm.String())
+  }
+  return fmt.Sprintf("match(%s){%s}", match.Value.String(), strings.Join(arms, ", "))
+}
+
+func (match *MatchExpression) TokenLiteral() string {
+  return match.Token.Literal
+}
+
+func (match *MatchExpression) Position() lexer.Position {
+  return match.Token.Position
+}
+
+type LiteralPattern struct {
+  Token lexer.Token
+  Value Expression
+}
+
+func (literal *LiteralPattern) patternNode() {}
+func (literal *LiteralPattern) String() string {
+  if str, ok := literal.Value.(*StringExpression); ok {
+    return fmt.Sprintf("%q", str.Value)
+  }
+  return literal.Value.String()
+}
+
+func (literal *LiteralPattern) TokenLiteral() string {
+  return literal.Token.Literal
+}
+
+func (literal *LiteralPattern) Position() lexer.Position {
+  return literal.Token.Position
+}
+
+type WildcardPattern struct {
+  Token lexer.Token
+}
+
+func (wildcard *WildcardPattern) patternNode()         {}
+func (wildcard *WildcardPattern) String() string       { return "_" }
+func (wildcard *WildcardPattern) TokenLiteral() string { return wildcard.Token.Literal }
+func (wildcard *WildcardPattern) Position() lexer.Position {
+  return wildcard.Token.Position
+}
+
+type IdentifierPattern struct {
+  Token lexer.Token
+  Name  *Identifier
+}
+
+func (identifier *IdentifierPattern) patternNode()         {}
+func (identifier *IdentifierPattern) String() string       { return identifier.Name.String() }
+func (identifier *IdentifierPattern) TokenLiteral() string { return identifier.Token.Literal }
+func (identifier *IdentifierPattern) Position() lexer.Position {
+  return identifier.Token.Position
+}
+
+type ListPattern struct {
+  Token    lexer.Token
+  Elements []Pattern
+  Rest     Pattern
+}
+
+func (list *ListPattern) patternNode() {}
+func (list *ListPattern) String() string {
+  elements := make([]string, 0)
+  for _, element := range list.Elements {
+    elements = append(elements, element.String())
+  }
+  if list.Rest != nil {
+    elements = append(elements, "..."+list.Rest.String())
+  }
+  return fmt.Sprintf("[%s]", strings.Join(elements, ", "))
+}
+
+func (list *ListPattern) TokenLiteral() string {
+  return list.Token.Literal
+}
+
+func (list *ListPattern) Position() lexer.Position {
+  return list.Token.Position
+}
+
 type InfixExpression struct {
   Token           lexer.Token
   LeftExpression  Expression
@@ -228,6 +493,26 @@ type InfixExpression struct {
   RightExpression Expression
 }
 
+type PipeExpression struct {
+  Token lexer.Token
+  Left  Expression
+  Right Expression
+}
+
+func (pipe *PipeExpression) expressionNode() {}
+
+func (pipe *PipeExpression) String() string {
+  return fmt.Sprintf("(%s |> %s)", pipe.Left.String(), pipe.Right.String())
+}
+
+func (pipe *PipeExpression) TokenLiteral() string {
+  return pipe.Token.Literal
+}
+
+func (pipe *PipeExpression) Position() lexer.Position {
+  return pipe.Token.Position
+}
+
 type CallFunctionExpression struct {
   Token     lexer.Token
   Function  Expression
@@ -251,6 +536,13 @@ func (funcCall *CallFunctionExpression) TokenLiteral() string {
   return funcCall.Token.Literal
 }
 
+func (funcCall *CallFunctionExpression) Position() lexer.Position {
+  if funcCall.Function == nil {
+    return funcCall.Token.Position
+  }
+  return funcCall.Function.Position()
+}
+
 func (infix *InfixExpression) expressionNode() {}
 
 func (infix *InfixExpression) String() string {
@@ -265,9 +557,127 @@ func (infix *InfixExpression) TokenLiteral() string {
   return infix.Token.Literal
 }
 
+func (infix *InfixExpression) Position() lexer.Position {
+  return infix.Token.Position
+}
+
+type DictLiteral struct {
+  Token  lexer.Token
+  Keys   []Expression
+  Values []Expression
+}
+
+func (dict *DictLiteral) expressionNode() {}
+
+func (dict *DictLiteral) String() string {
+  pairs := make([]string, 0)
+  for i, key := range dict.Keys {
+    pairs = append(pairs, fmt.Sprintf("%s:%s", key.String(), dict.Values[i].String()))
+  }
+  buffer := bytes.NewBufferString("{")
+  buffer.WriteString(strings.Join(pairs, ","))
+  buffer.WriteString("}")
+  return buffer.String()
+}
+
+func (dict *DictLiteral) TokenLiteral() string {
+  return dict.Token.Literal
+}
+
+func (dict *DictLiteral) Position() lexer.Position {
+  return dict.Token.Position
+}
+
+type ListLiteral struct {
+  Token    lexer.Token
+  Elements []Expression
+}
+
+func (list *ListLiteral) expressionNode() {}
+
+func (list *ListLiteral) String() string {
+  elements := make([]string, 0)
+  for _, element := range list.Elements {
+    elements = append(elements, element.String())
+  }
+  buffer := bytes.NewBufferString("[")
+  buffer.WriteString(strings.Join(elements, ","))
+  buffer.WriteString("]")
+  return buffer.String()
+}
+
+func (list *ListLiteral) TokenLiteral() string {
+  return list.Token.Literal
+}
+
+func (list *ListLiteral) Position() lexer.Position {
+  return list.Token.Position
+}
+
+type IndexExpression struct {
+  Token lexer.Token
+  Left  Expression
+  Index Expression
+}
+
+func (index *IndexExpression) expressionNode() {}
+
+func (index *IndexExpression) String() string {
+  return fmt.Sprintf("(%s[%s])", index.Left.String(), index.Index.String())
+}
+
+func (index *IndexExpression) TokenLiteral() string {
+  return index.Token.Literal
+}
+
+func (index *IndexExpression) Position() lexer.Position {
+  return index.Token.Position
+}
+
+type SliceExpression struct {
+  Token lexer.Token
+  Left  Expression
+  Start Expression
+  End   Expression
+}
+
+func (slice *SliceExpression) expressionNode() {}
+
+func (slice *SliceExpression) String() string {
+  start := ""
+  if slice.Start != nil {
+    start = slice.Start.String()
+  }
+  end := ""
+  if slice.End != nil {
+    end = slice.End.String()
+  }
+  return fmt.Sprintf("(%s[%s:%s])", slice.Left.String(), start, end)
+}
+
+func (slice *SliceExpression) TokenLiteral() string {
+  return slice.Token.Literal
+}
+
+func (slice *SliceExpression) Position() lexer.Position {
+  return slice.Token.Position
+}
+
+// IdentifierScope tells where the resolver found the declaration of an identifier, unresolved identifiers are looked up by name
+type IdentifierScope int
+
+const (
+  UnresolvedScope IdentifierScope = iota
+  LocalScope
+  GlobalScope
+)
+
 type Identifier struct {
   Token lexer.Token
   Value string
+  Scope IdentifierScope
+  Depth int
+  Slot  int
 }
 
 func (id *Identifier) expressionNode() {
@@ -277,6 +687,10 @@ func (id *Identifier) TokenLiteral() string {
   return id.Token.Literal
 }
 
+func (id *Identifier) Position() lexer.Position {
+  return id.Token.Position
+}
+
 func (id *Identifier) String() string {
   return id.Value
 }
@@ -285,6 +699,7 @@ type FunctionExpression struct {
   Token  lexer.Token
   Params []*Identifier
   Block  *BlockStatement
+  Locals []string
 }
 
 func (fnExpression *FunctionExpression) String() string {
@@ -307,6 +722,10 @@ func (fnExpression *FunctionExpression) TokenLiteral() string {
   return fnExpression.Token.Literal
 }
 
+func (fnExpression *FunctionExpression) Position() lexer.Position {
+  return fnExpression.Token.Position
+}
+
 type BoolExpression struct {
   Token lexer.Token
   Value string
@@ -324,6 +743,10 @@ func (boolExpression *BoolExpression) TokenLiteral() string {
   return boolExpression.Token.Literal
 }
 
+func (boolExpression *BoolExpression) Position() lexer.Position {
+  return boolExpression.Token.Position
+}
+
 type Program struct {
   Statements []Statement
 }
@@ -335,6 +758,13 @@ func (p *Program) TokenLiteral() string {
   return ""
 }
 
+func (p *Program) Position() lexer.Position {
+  if len(p.Statements) > 0 {
+    return p.Statements[0].Position()
+  }
+  return lexer.Position{}
+}
+
 func (p *Program) String() string {
   var buffer *bytes.Buffer = bytes.NewBufferString("");
   for _, statement := range p.Statements {
@@ -84,7 +84,7 @@ func _create_writer(env *object.Environment, b *Builtin, eval func(node ast.Node
 		switch valueType := value.(type) {
 		case *object.String:
 			buffer.WriteString(valueType.Value)
-		case *object.Integer:
+		case *object.Integer, *object.Float:
 			buffer.WriteString(valueType.Inspect())
 		default:
 			return &object.ErrorObject{Error: fmt.Sprintf("can not writer to buffer type %s", value.Type())}
@@ -0,0 +1,127 @@
+package builtin
+
+import (
+	"rootlang/object"
+	"rootlang/ast"
+	"fmt"
+)
+
+func _keys(_ *object.Environment, _ *Builtin, _ func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
+	if len(params) != 1 {
+		return &object.ErrorObject{Error: fmt.Sprintf("keys only recive 1 params and got %d", len(params))}
+	}
+	dict, ok := params[0].(*object.Dict)
+	if !ok {
+		return &object.ErrorObject{Error: fmt.Sprintf("keys first params expected to be a dict and got %s", params[0].Type())}
+	}
+	elements := make([]object.Object, 0)
+	for _, key := range dict.Keys {
+		elements = append(elements, dict.Pairs[key].Key)
+	}
+	return object.NewList(elements)
+}
+
+func _values(_ *object.Environment, _ *Builtin, _ func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
+	if len(params) != 1 {
+		return &object.ErrorObject{Error: fmt.Sprintf("values only recive 1 params and got %d", len(params))}
+	}
+	dict, ok := params[0].(*object.Dict)
+	if !ok {
+		return &object.ErrorObject{Error: fmt.Sprintf("values first params expected to be a dict and got %s", params[0].Type())}
+	}
+	elements := make([]object.Object, 0)
+	for _, key := range dict.Keys {
+		elements = append(elements, dict.Pairs[key].Value)
+	}
+	return object.NewList(elements)
+}
+
+func _get(_ *object.Environment, _ *Builtin, _ func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
+	if len(params) != 2 && len(params) != 3 {
+		return &object.ErrorObject{Error: fmt.Sprintf("get expect the dict, the key and an optional default value and got %d params", len(params))}
+	}
+	dict, key, err := _dictAndKey("get", params)
+	if err != nil {
+		return err
+	}
+	value, ok := dict.Get(key)
+	if ok {
+		return value
+	}
+	if len(params) == 3 {
+		return params[2]
+	}
+	return object.NULL
+}
+
+func _put(_ *object.Environment, _ *Builtin, _ func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
+	if len(params) != 3 {
+		return &object.ErrorObject{Error: fmt.Sprintf("put expect 3 params and got %d", len(params))}
+	}
+	if list, ok := params[0].(*object.List); ok {
+		return _putInList(list, params[1], params[2])
+	}
+	dict, key, err := _dictAndKey("put", params)
+	if err != nil {
+		return err
+	}
+	newDict := dict.Copy()
+	newDict.Set(key, params[2])
+	return newDict
+}
+
+// _putInList returns a list with value at index, negative indexes count from the end like the index operator
+func _putInList(list *object.List, index, value object.Object) object.Object {
+	integerIndex, ok := index.(*object.Integer)
+	if !ok {
+		return &object.ErrorObject{Error: fmt.Sprintf("index should be integer and got %s", index.Type())}
+	}
+	position := integerIndex.Value
+	if position < 0 {
+		position += int64(list.Len())
+	}
+	if position < 0 || position >= int64(list.Len()) {
+		return &object.ErrorObject{Error: fmt.Sprintf("index %d out of range with length %d", integerIndex.Value, list.Len())}
+	}
+	return list.Set(int(position), value)
+}
+
+func _remove(_ *object.Environment, _ *Builtin, _ func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
+	if len(params) != 2 {
+		return &object.ErrorObject{Error: fmt.Sprintf("remove expect 2 params and got %d", len(params))}
+	}
+	dict, key, err := _dictAndKey("remove", params)
+	if err != nil {
+		return err
+	}
+	newDict := dict.Copy()
+	newDict.Delete(key)
+	return newDict
+}
+
+func _has(_ *object.Environment, _ *Builtin, _ func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
+	if len(params) != 2 {
+		return &object.ErrorObject{Error: fmt.Sprintf("has expect 2 params and got %d", len(params))}
+	}
+	dict, key, err := _dictAndKey("has", params)
+	if err != nil {
+		return err
+	}
+	_, ok := dict.Get(key)
+	if ok {
+		return object.TRUE
+	}
+	return object.FALSE
+}
+
+func _dictAndKey(name string, params []object.Object) (*object.Dict, object.Hashable, *object.ErrorObject) {
+	dict, ok := params[0].(*object.Dict)
+	if !ok {
+		return nil, nil, &object.ErrorObject{Error: fmt.Sprintf("%s first params expected to be a dict and got %s", name, params[0].Type())}
+	}
+	key, ok := params[1].(object.Hashable)
+	if !ok {
+		return nil, nil, &object.ErrorObject{Error: fmt.Sprintf("unusable as dict key %s", params[1].Type())}
+	}
+	return dict, key, nil
+}
@@ -0,0 +1,46 @@
+package builtin
+
+import (
+	"rootlang/object"
+	"rootlang/ast"
+	"fmt"
+)
+
+func _error(_ *object.Environment, _ *Builtin, _ func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
+	if len(params) != 1 {
+		return &object.ErrorObject{Error: fmt.Sprintf("error only recive 1 params and got %d", len(params))}
+	}
+	return &object.ErrorValue{Message: params[0].Inspect()}
+}
+
+func _is_error(_ *object.Environment, _ *Builtin, _ func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
+	if len(params) != 1 {
+		return &object.ErrorObject{Error: fmt.Sprintf("is_error only recive 1 params and got %d", len(params))}
+	}
+	if params[0].Type() == object.ERROR_VALUE_OBJ {
+		return object.TRUE
+	}
+	return object.FALSE
+}
+
+func _error_message(_ *object.Environment, _ *Builtin, _ func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
+	if len(params) != 1 {
+		return &object.ErrorObject{Error: fmt.Sprintf("error_message only recive 1 params and got %d", len(params))}
+	}
+	errorValue, ok := params[0].(*object.ErrorValue)
+	if !ok {
+		return &object.ErrorObject{Error: fmt.Sprintf("error_message first params expected to be an error and got %s", params[0].Type())}
+	}
+	return &object.String{Value: errorValue.Message}
+}
+
+// _raise fails with the message like any runtime error so try catches it, an error value is raised with its message
+func _raise(_ *object.Environment, _ *Builtin, _ func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
+	if len(params) != 1 {
+		return &object.ErrorObject{Error: fmt.Sprintf("raise only recive 1 params and got %d", len(params))}
+	}
+	if errorValue, ok := params[0].(*object.ErrorValue); ok {
+		return &object.ErrorObject{Error: errorValue.Message}
+	}
+	return &object.ErrorObject{Error: params[0].Inspect()}
+}
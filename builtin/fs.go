@@ -0,0 +1,301 @@
+package builtin
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"rootlang/object"
+)
+
+const (
+	FILE_OBJ = "FILE"
+)
+
+// File is a handle returned by fs::open, lines are read through a buffer so read_line and read can be mixed
+type File struct {
+	path   string
+	file   *os.File
+	reader *bufio.Reader
+	closed bool
+}
+
+func (file *File) Type() object.ObjectType {
+	return FILE_OBJ
+}
+
+func (file *File) Inspect() string {
+	return fmt.Sprintf("file(%s)", file.path)
+}
+
+var fileModes = map[string]int{
+	"r":  os.O_RDONLY,
+	"w":  os.O_WRONLY | os.O_CREATE | os.O_TRUNC,
+	"a":  os.O_WRONLY | os.O_CREATE | os.O_APPEND,
+	"r+": os.O_RDWR,
+}
+
+func buildFsModule() *object.Module {
+	env := object.NewEnvironment()
+	functions := map[string]NativeFunction{
+		"read_file":   _read_file,
+		"write_file":  _write_file,
+		"append_file": _append_file,
+		"exists":      _exists,
+		"list_dir":    _list_dir,
+		"mkdir":       _mkdir,
+		"remove":      _remove_file,
+		"stat":        _stat,
+		"open":        _open,
+		"read_line":   _read_line,
+		"read":        _read,
+		"write":       _write,
+		"close":       _close,
+	}
+	for name, function := range functions {
+		env.SetVar(name, NewFunction(name, function))
+	}
+	return &object.Module{Env: env, Name: FS, Path: "/" + FS}
+}
+
+// pathParam checks the call has only a path
+func pathParam(name string, params []object.Object) (string, *object.ErrorObject) {
+	if err := CheckArity(name, params, 1, 1); err != nil {
+		return "", err
+	}
+	if err := CheckTypes(name, params, object.STRING_OBJ); err != nil {
+		return "", err
+	}
+	return params[0].(*object.String).Value, nil
+}
+
+// dataToWrite returns the text of a string or the content of a writer buffer
+func dataToWrite(name string, data object.Object) ([]byte, *object.ErrorObject) {
+	switch dataType := data.(type) {
+	case *object.String:
+		return []byte(dataType.Value), nil
+	case *WriterBufferObject:
+		return dataType.data.Bytes(), nil
+	}
+	return nil, &object.ErrorObject{Error: fmt.Sprintf("%s expected string or writer_buffer and got %s", name, data.Type())}
+}
+
+func writeToPath(name string, flags int, params []object.Object) object.Object {
+	if err := CheckArity(name, params, 2, 2); err != nil {
+		return err
+	}
+	if err := CheckTypes(name, params, object.STRING_OBJ); err != nil {
+		return err
+	}
+	data, errorObject := dataToWrite(name, params[1])
+	if errorObject != nil {
+		return errorObject
+	}
+	file, err := os.OpenFile(params[0].(*object.String).Value, flags, 0644)
+	if err != nil {
+		return &object.ErrorObject{Error: err.Error()}
+	}
+	defer file.Close()
+	written, err := file.Write(data)
+	if err != nil {
+		return &object.ErrorObject{Error: err.Error()}
+	}
+	return &object.Integer{Value: int64(written)}
+}
+
+func _read_file(_ *Caller, params ...object.Object) object.Object {
+	path, errorObject := pathParam("read_file", params)
+	if errorObject != nil {
+		return errorObject
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return &object.ErrorObject{Error: err.Error()}
+	}
+	return &object.String{Value: string(content)}
+}
+
+func _write_file(_ *Caller, params ...object.Object) object.Object {
+	return writeToPath("write_file", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, params)
+}
+
+func _append_file(_ *Caller, params ...object.Object) object.Object {
+	return writeToPath("append_file", os.O_WRONLY|os.O_CREATE|os.O_APPEND, params)
+}
+
+func _exists(_ *Caller, params ...object.Object) object.Object {
+	path, errorObject := pathParam("exists", params)
+	if errorObject != nil {
+		return errorObject
+	}
+	if _, err := os.Stat(path); err != nil {
+		return object.FALSE
+	}
+	return object.TRUE
+}
+
+// _list_dir returns the names of the entries of the directory sorted by name
+func _list_dir(_ *Caller, params ...object.Object) object.Object {
+	path, errorObject := pathParam("list_dir", params)
+	if errorObject != nil {
+		return errorObject
+	}
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return &object.ErrorObject{Error: err.Error()}
+	}
+	names := make([]object.Object, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, &object.String{Value: entry.Name()})
+	}
+	return object.NewList(names)
+}
+
+// _mkdir creates the directory and the parents it is missing
+func _mkdir(_ *Caller, params ...object.Object) object.Object {
+	path, errorObject := pathParam("mkdir", params)
+	if errorObject != nil {
+		return errorObject
+	}
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return &object.ErrorObject{Error: err.Error()}
+	}
+	return nil
+}
+
+// _remove_file removes a file or an empty directory
+func _remove_file(_ *Caller, params ...object.Object) object.Object {
+	path, errorObject := pathParam("remove", params)
+	if errorObject != nil {
+		return errorObject
+	}
+	if err := os.Remove(path); err != nil {
+		return &object.ErrorObject{Error: err.Error()}
+	}
+	return nil
+}
+
+// _stat returns a dict with the name, size, is_dir, mode and modified keys, modified is a unix time in seconds
+func _stat(_ *Caller, params ...object.Object) object.Object {
+	path, errorObject := pathParam("stat", params)
+	if errorObject != nil {
+		return errorObject
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return &object.ErrorObject{Error: err.Error()}
+	}
+	dict := object.NewDict()
+	dict.Set(&object.String{Value: "name"}, &object.String{Value: info.Name()})
+	dict.Set(&object.String{Value: "size"}, &object.Integer{Value: info.Size()})
+	isDir := object.FALSE
+	if info.IsDir() {
+		isDir = object.TRUE
+	}
+	dict.Set(&object.String{Value: "is_dir"}, isDir)
+	dict.Set(&object.String{Value: "mode"}, &object.String{Value: info.Mode().String()})
+	dict.Set(&object.String{Value: "modified"}, &object.Integer{Value: info.ModTime().Unix()})
+	return dict
+}
+
+// _open opens a file with the mode "r" to read, the default, "w" to write it from the start, "a" to append or "r+"
+func _open(_ *Caller, params ...object.Object) object.Object {
+	if err := CheckArity("open", params, 1, 2); err != nil {
+		return err
+	}
+	if err := CheckTypes("open", params, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+	mode := "r"
+	if len(params) == 2 {
+		mode = params[1].(*object.String).Value
+	}
+	flags, ok := fileModes[mode]
+	if !ok {
+		return &object.ErrorObject{Error: fmt.Sprintf("open unknown mode %s, expected r, w, a or r+", mode)}
+	}
+	path := params[0].(*object.String).Value
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return &object.ErrorObject{Error: err.Error()}
+	}
+	return &File{path: path, file: file, reader: bufio.NewReader(file)}
+}
+
+func fileParam(name string, params []object.Object, count int) (*File, *object.ErrorObject) {
+	if err := CheckArity(name, params, count, count); err != nil {
+		return nil, err
+	}
+	if err := CheckTypes(name, params, FILE_OBJ); err != nil {
+		return nil, err
+	}
+	file := params[0].(*File)
+	if file.closed {
+		return nil, &object.ErrorObject{Error: fmt.Sprintf("%s the file %s is closed", name, file.path)}
+	}
+	return file, nil
+}
+
+// _read_line returns the next line with its line break like bytes::read_string, at the end of the file it is ""
+func _read_line(_ *Caller, params ...object.Object) object.Object {
+	file, errorObject := fileParam("read_line", params, 1)
+	if errorObject != nil {
+		return errorObject
+	}
+	line, err := file.reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return &object.ErrorObject{Error: err.Error()}
+	}
+	return &object.String{Value: line}
+}
+
+// _read returns a reader buffer with the rest of the file for bytes::read_string
+func _read(_ *Caller, params ...object.Object) object.Object {
+	file, errorObject := fileParam("read", params, 1)
+	if errorObject != nil {
+		return errorObject
+	}
+	content, err := ioutil.ReadAll(file.reader)
+	if err != nil {
+		return &object.ErrorObject{Error: err.Error()}
+	}
+	return &ReaderBufferObject{bytes.NewBuffer(content)}
+}
+
+// _write writes a string or the content of a writer buffer after the last byte read and returns the number of bytes
+// written, the file is moved back over what the reader buffered but was not read yet
+func _write(_ *Caller, params ...object.Object) object.Object {
+	file, errorObject := fileParam("write", params, 2)
+	if errorObject != nil {
+		return errorObject
+	}
+	data, errorObject := dataToWrite("write", params[1])
+	if errorObject != nil {
+		return errorObject
+	}
+	if buffered := file.reader.Buffered(); buffered != 0 {
+		if _, err := file.file.Seek(-int64(buffered), io.SeekCurrent); err != nil {
+			return &object.ErrorObject{Error: err.Error()}
+		}
+		file.reader.Reset(file.file)
+	}
+	written, err := file.file.Write(data)
+	if err != nil {
+		return &object.ErrorObject{Error: err.Error()}
+	}
+	return &object.Integer{Value: int64(written)}
+}
+
+func _close(_ *Caller, params ...object.Object) object.Object {
+	file, errorObject := fileParam("close", params, 1)
+	if errorObject != nil {
+		return errorObject
+	}
+	file.closed = true
+	if err := file.file.Close(); err != nil {
+		return &object.ErrorObject{Error: err.Error()}
+	}
+	return nil
+}
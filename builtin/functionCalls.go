@@ -3,49 +3,88 @@ package builtin
 import (
 	"rootlang/object"
 	"rootlang/ast"
+	"rootlang/lexer"
 	"fmt"
 )
 
-func __callFunction(function *object.Function, env *object.Environment, b *Builtin, eval func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, param object.Object) [][]object.Object {
+func __callFunction(function object.Object, env *object.Environment, b *Builtin, eval func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, param object.Object) [][]object.Object {
 	elements := make([][]object.Object, 0)
 	switch paramTye := param.(type) {
 	case *object.List:
-		for _, paramElement := range paramTye.Elements {
-			returnValues := applyArgumentsToFunctionAndCall(function, []object.Object{paramElement}, b, eval)
+		for _, paramElement := range paramTye.Elements() {
+			returnValues := callFunction(function, []object.Object{paramElement}, b, eval)
 			if returnValues.Type() == object.ERROR_OBJ {
 				return [][]object.Object{[]object.Object{returnValues}};
 			}
 			elements = append(elements, []object.Object{returnValues, paramElement})
 		}
 	default:
-		returnValues := applyArgumentsToFunctionAndCall(function, []object.Object{paramTye}, b, eval)
+		returnValues := callFunction(function, []object.Object{paramTye}, b, eval)
 		elements = append(elements, []object.Object{returnValues, paramTye})
 	}
 	return elements
 }
 
+// callFunction calls a rootlang function or a callable from another backend like the vm
+func callFunction(function object.Object, params []object.Object, builtinSymbols *Builtin, eval func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object) object.Object {
+	switch functionType := function.(type) {
+	case *object.Function:
+		return applyArgumentsToFunctionAndCall(functionType, params, builtinSymbols, eval)
+	case object.Callable:
+		if len(params) > len(functionType.Params()) {
+			return &object.ErrorObject{Error: (fmt.Sprintf("this function takes at least %d arguments (%d given)", len(functionType.Params()), len(params)))}
+		}
+		returnValue := functionType.Call(params...)
+		if errorObject, ok := returnValue.(*object.ErrorObject); ok {
+			errorObject.AddFrame(functionType.FunctionName(), "", lexer.Position{})
+		}
+		return returnValue
+	}
+	return &object.ErrorObject{Error: fmt.Sprintf("expected function %s", function.Inspect())}
+}
+
+func functionParams(function object.Object) ([]*ast.Identifier, bool) {
+	switch functionType := function.(type) {
+	case *object.Function:
+		return functionType.Params, true
+	case object.Callable:
+		return functionType.Params(), true
+	}
+	return nil, false
+}
+
 func applyArgumentsToFunctionAndCall(function *object.Function, params []object.Object, builtinSymbols *Builtin, eval func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object) object.Object {
 
 	if len(params) > len(function.Params) {
 		return &object.ErrorObject{Error: (fmt.Sprintf("this function takes at least %d arguments (%d given)", len(function.Params), len(params)))}
 	}
-	newEnvironment := applyArguments(function, params)
-	if len(function.Params) == len(params) {
+	caller := function
+	callPosition := lexer.Position{}
+	for {
+		if len(function.Params) != len(params) {
+			return function.Partial(params)
+		}
+		newEnvironment := applyArguments(function, params)
 		returnValue := eval(function.Body, newEnvironment, builtinSymbols)
 		if returnValue != nil && returnValue.Type() == object.RETURN_OBJ {
-			return returnValue.(*object.ReturnObject).Value
+			returnValue = returnValue.(*object.ReturnObject).Value
 		}
-		return returnValue
+		if errorObject, ok := returnValue.(*object.ErrorObject); ok {
+			errorObject.AddFrame(function.Name, "", callPosition)
+			if function != caller {
+				errorObject.AddFrame(caller.Name, "", lexer.Position{})
+			}
+		}
+		tailCall, ok := returnValue.(*object.TailCall)
+		if !ok {
+			return returnValue
+		}
+		function, params, callPosition = tailCall.Function, tailCall.Params, tailCall.Position
 	}
-	return function.Clone(function.Params[len(params):], newEnvironment)
 }
 
 func applyArguments(function *object.Function, params []object.Object) *object.Environment {
-	newEnvironment := function.Env.ExtendNewEnvironment()
-	for i := 0; i < len(params); i++ {
-		newEnvironment.SetVar(function.Params[i].Value, params[i])
-	}
-	return newEnvironment
+	return function.CallEnvironment(params)
 }
 
 func isErrorObject(obj object.Object) bool {
@@ -13,19 +13,25 @@ func _zip(env *object.Environment, b *Builtin, eval func(node ast.Node, environm
 	if len(params) < 1 {
 		return &object.ErrorObject{Error: fmt.Sprintf("zip expect more than 1 params and got %d", len(params))}
 	}
+	if _hasSequence(params) && _allParamsAreListOrSequence(params) {
+		return lazyZip(params)
+	}
 	if !_allParamsAreList(params) {
 		return &object.ErrorObject{Error: fmt.Sprintf("zip all arguments expected to be list", len(params))}
 	}
 	minIndex := _getListMinIndex(params)
+	lists := make([][]object.Object, len(params))
+	for j := range params {
+		lists[j] = params[j].(*object.List).Elements()
+	}
 	for i := uint64(0); i < minIndex; i++ {
 		zipArray := make([]object.Object, 0)
 		for j := 0; j < len(params); j++ {
-			list := params[j].(*object.List)
-			zipArray = append(zipArray, list.Elements[i])
+			zipArray = append(zipArray, lists[j][i])
 		}
-		elements = append(elements, &object.List{Elements: zipArray})
+		elements = append(elements, object.NewList(zipArray))
 	}
-	return &object.List{Elements: elements}
+	return object.NewList(elements)
 }
 
 func _print(env *object.Environment, b *Builtin, eval func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
@@ -41,7 +47,7 @@ func _getListMinIndex(params []object.Object) uint64 {
 	minIndex := uint64(math.MaxUint64)
 	for i := 0; i < len(params); i++ {
 		list := params[0].(*object.List)
-		minIndex = _min(minIndex, uint64(len(list.Elements)))
+		minIndex = _min(minIndex, uint64(list.Len()))
 	}
 	return minIndex
 }
@@ -62,15 +68,27 @@ func _allParamsAreList(params []object.Object) bool {
 	return true
 }
 
+func _allParamsAreListOrSequence(params []object.Object) bool {
+	for _, element := range params {
+		if element.Type() != object.LIST_OBJ && element.Type() != object.SEQUENCE_OBJ {
+			return false
+		}
+	}
+	return true
+}
+
 func _map(env *object.Environment, b *Builtin, eval func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
 	elements := make([]object.Object, 0)
 	if len(params) < 1 {
 		return &object.ErrorObject{Error: fmt.Sprintf("map expect more than 1 params and got %d", len(params))}
 	}
-	function, ok := params[0].(*object.Function)
-	if !ok {
+	function := params[0]
+	if _, ok := functionParams(function); !ok {
 		return &object.ErrorObject{Error: fmt.Sprintf("map first params should be function and got %s", params[0].Type())}
 	}
+	if _hasSequence(params[1:]) {
+		return lazyMap(function, params[1:], b, eval)
+	}
 	for _, objectParam := range params[1:] {
 		returnValues := __callFunction(function, env, b, eval, objectParam)
 		if len(returnValues) == 1 && len(returnValues[0]) == 1 && returnValues[0][0].Type() == object.ERROR_OBJ {
@@ -78,40 +96,46 @@ func _map(env *object.Environment, b *Builtin, eval func(node ast.Node, environm
 		}
 		elements = append(elements, _get_only_values(returnValues)...)
 	}
-	return &object.List{Elements: elements}
+	return object.NewList(elements)
 }
 
 func _reduce(env *object.Environment, b *Builtin, eval func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
 	if len(params) < 2 {
 		return &object.ErrorObject{Error: fmt.Sprintf("reduce expect more than 1 params and got %d", len(params))}
 	}
-	function, ok := params[0].(*object.Function)
+	function := params[0]
+	functionArguments, ok := functionParams(function)
 	if !ok {
 		return &object.ErrorObject{Error: fmt.Sprintf("reduce first params should be function and got %s", params[0].Type())}
 	}
-	if len(function.Params) != 2 {
-		return &object.ErrorObject{Error: fmt.Sprintf("reduce function should recive 2 params and recive %d", len(function.Params))}
+	if len(functionArguments) != 2 {
+		return &object.ErrorObject{Error: fmt.Sprintf("reduce function should recive 2 params and recive %d", len(functionArguments))}
 	}
 	if len(params[1:]) > 2 {
 		return &object.ErrorObject{Error: fmt.Sprintf("reduce function should has max 2 arguments the list and initizial value and got %d", len(params[1:]))}
 	}
-	list, ok := params[1].(*object.List)
-	if !ok {
+	if params[1].Type() != object.LIST_OBJ && params[1].Type() != object.SEQUENCE_OBJ {
 		return &object.ErrorObject{Error: fmt.Sprintf("the second arguments is expected to be a list")}
 	}
+	next := toIterator(params[1])
 	var initialValue object.Object = nil
-	var reduceParams []object.Object = list.Elements
 	if len(params) == 3 {
 		initialValue = params[2]
 	} else {
-		if len(reduceParams) == 0 {
+		value, ok := next()
+		if !ok {
 			return &object.ErrorObject{Error: fmt.Sprintf("you provide empty list and not initial value, please dont be a fucking ass hole")}
 		}
-		initialValue = reduceParams[0]
-		reduceParams = reduceParams[1:]
+		if value.Type() == object.ERROR_OBJ {
+			return value
+		}
+		initialValue = value
 	}
-	for _, objectParam := range reduceParams {
-		initialValue = applyArgumentsToFunctionAndCall(function, []object.Object{initialValue, objectParam}, b, eval)
+	for objectParam, ok := next(); ok; objectParam, ok = next() {
+		if objectParam.Type() == object.ERROR_OBJ {
+			return objectParam
+		}
+		initialValue = callFunction(function, []object.Object{initialValue, objectParam}, b, eval)
 		if initialValue.Type() == object.ERROR_OBJ {
 			return initialValue
 		}
@@ -133,10 +157,13 @@ func _filter(env *object.Environment, b *Builtin, eval func(node ast.Node, envir
 	if len(params) < 1 {
 		return &object.ErrorObject{Error: fmt.Sprintf("filter expect more than 1 params and got %d", len(params))}
 	}
-	function, ok := params[0].(*object.Function)
-	if !ok {
+	function := params[0]
+	if _, ok := functionParams(function); !ok {
 		return &object.ErrorObject{Error: fmt.Sprintf("filter first params should be function and got %s", params[0].Type())}
 	}
+	if _hasSequence(params[1:]) {
+		return lazyFilter(function, params[1:], b, eval)
+	}
 	for _, objectParam := range params[1:] {
 		returnValues := __callFunction(function, env, b, eval, objectParam)
 		if len(returnValues) == 1 && len(returnValues[0]) == 1 && returnValues[0][0].Type() == object.ERROR_OBJ {
@@ -144,7 +171,7 @@ func _filter(env *object.Environment, b *Builtin, eval func(node ast.Node, envir
 		}
 		elements = append(elements, filterValues(returnValues)...)
 	}
-	return &object.List{Elements: elements}
+	return object.NewList(elements)
 }
 
 func filterValues(values [][]object.Object) []object.Object {
@@ -164,6 +191,8 @@ func evalTruthValue(value object.Object) bool {
 		return valueType.Value
 	case *object.Integer:
 		return valueType.Value != 0
+	case *object.Float:
+		return valueType.Value != 0
 	case *object.String:
 		return len(valueType.Value) != 0
 	default:
@@ -172,7 +201,7 @@ func evalTruthValue(value object.Object) bool {
 }
 
 func _list(_ *object.Environment, _ *Builtin, _ func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
-	return &object.List{Elements: params}
+	return object.NewList(params)
 }
 
 func _append(_ *object.Environment, _ *Builtin, _ func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
@@ -184,7 +213,32 @@ func _append(_ *object.Environment, _ *Builtin, _ func(node ast.Node, environmen
 		return &object.ErrorObject{Error: fmt.Sprintf("first params expected to be a list and got %s", params[0].Type())}
 	}
 	for _, element := range params[1:] {
-		list.Elements = append(list.Elements, element)
+		list = list.Append(element)
+	}
+	return list
+}
+
+func _prepend(_ *object.Environment, _ *Builtin, _ func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
+	if len(params) != 2 {
+		return &object.ErrorObject{Error: fmt.Sprintf("prepend expect 2 params and got %d", len(params))}
+	}
+	list, ok := params[0].(*object.List)
+	if !ok {
+		return &object.ErrorObject{Error: fmt.Sprintf("first params expected to be a list and got %s", params[0].Type())}
+	}
+	return list.Prepend(params[1])
+}
+
+func _concat(_ *object.Environment, _ *Builtin, _ func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
+	if len(params) < 1 {
+		return &object.ErrorObject{Error: fmt.Sprintf("concat expect more than 1 params and got %d", len(params))}
+	}
+	if !_allParamsAreList(params) {
+		return &object.ErrorObject{Error: fmt.Sprintf("concat all arguments expected to be list")}
+	}
+	list := params[0].(*object.List)
+	for _, param := range params[1:] {
+		list = list.Concat(param.(*object.List))
 	}
 	return list
 }
@@ -199,9 +253,11 @@ func _len(_ *object.Environment, b *Builtin, eval func(node ast.Node, environmen
 	case *object.String:
 		return &object.Integer{Value: int64(len(valueType.Value))}
 	case *object.List:
-		return &object.Integer{Value: int64(len(valueType.Elements))}
+		return &object.Integer{Value: int64(valueType.Len())}
+	case *object.Dict:
+		return &object.Integer{Value: int64(len(valueType.Keys))}
 	default:
-		return &object.ErrorObject{Error: fmt.Sprintf("expected string or list type and got %s", value.Type())}
+		return &object.ErrorObject{Error: fmt.Sprintf("expected string, list or dict type and got %s", value.Type())}
 	}
 
 }
@@ -3,6 +3,7 @@ package builtin
 import (
   "testing"
   "rootlang/object"
+  "rootlang/ast"
 )
 
 func TestLenBuildExpression(t *testing.T) {
@@ -37,3 +38,223 @@ func TestLenBuildExpression(t *testing.T) {
 
 
 }
+
+func TestListsAreNotModified(t *testing.T) {
+  b := New()
+  list := object.NewList([]object.Object{&object.Integer{Value: 1}, &object.Integer{Value: 2}})
+  tests := []struct {
+    name     string
+    params   []object.Object
+    expected string
+  }{
+    {"append", []object.Object{list, &object.Integer{Value: 3}}, "[1,2,3]"},
+    {"prepend", []object.Object{list, &object.Integer{Value: 0}}, "[0,1,2]"},
+    {"concat", []object.Object{list, list, list}, "[1,2,1,2,1,2]"},
+    {"put", []object.Object{list, &object.Integer{Value: -1}, &object.Integer{Value: 5}}, "[1,5]"},
+    {"put", []object.Object{list, &object.Integer{Value: 2}, &object.Integer{Value: 5}}, "index 2 out of range with length 2"},
+  }
+  for _, test := range tests {
+    function, _ := b.GetObject(test.name)
+    returnValue := function.(*BuiltinFunction).Function(object.NewEnvironment(), b, nil, test.params...)
+    if returnValue.Inspect() != test.expected {
+      t.Errorf("%s: expected %s and got %s", test.name, test.expected, returnValue.Inspect())
+    }
+    if list.Inspect() != "[1,2]" {
+      t.Errorf("%s changed the list to %s", test.name, list.Inspect())
+    }
+  }
+}
+
+// benchmarkEval stands for the evaluator, the functions it runs return their argument plus one
+func benchmarkEval(node ast.Node, environment *object.Environment, b *Builtin) object.Object {
+  x, _ := environment.GetVar("x")
+  if y, ok := environment.GetVar("y"); ok {
+    return &object.Integer{Value: x.(*object.Integer).Value + y.(*object.Integer).Value}
+  }
+  return &object.Integer{Value: x.(*object.Integer).Value + 1}
+}
+
+func benchmarkElements() []object.Object {
+  elements := make([]object.Object, 10000)
+  for i := range elements {
+    elements[i] = &object.Integer{Value: int64(i)}
+  }
+  return elements
+}
+
+func benchmarkFunction(params ...string) *object.Function {
+  identifiers := make([]*ast.Identifier, 0)
+  for _, param := range params {
+    identifiers = append(identifiers, &ast.Identifier{Value: param})
+  }
+  return &object.Function{Params: identifiers, Env: object.NewEnvironment()}
+}
+
+// the slice versions are the combinators as they were when lists were backed by a slice, they are the baseline of the benchmarks
+
+func sliceCall(function object.Object, elements []object.Object, b *Builtin) [][]object.Object {
+  values := make([][]object.Object, 0)
+  for _, element := range elements {
+    values = append(values, []object.Object{callFunction(function, []object.Object{element}, b, benchmarkEval), element})
+  }
+  return values
+}
+
+func sliceMap(function object.Object, elements []object.Object, b *Builtin) []object.Object {
+  return append(make([]object.Object, 0), _get_only_values(sliceCall(function, elements, b))...)
+}
+
+func sliceFilter(function object.Object, elements []object.Object, b *Builtin) []object.Object {
+  return append(make([]object.Object, 0), filterValues(sliceCall(function, elements, b))...)
+}
+
+func sliceZip(lists [][]object.Object) [][]object.Object {
+  zipped := make([][]object.Object, 0)
+  for i := range lists[0] {
+    pair := make([]object.Object, 0)
+    for _, list := range lists {
+      pair = append(pair, list[i])
+    }
+    zipped = append(zipped, pair)
+  }
+  return zipped
+}
+
+func sliceReduce(function object.Object, elements []object.Object, b *Builtin) object.Object {
+  value := elements[0]
+  for _, element := range elements[1:] {
+    value = callFunction(function, []object.Object{value, element}, b, benchmarkEval)
+  }
+  return value
+}
+
+func BenchmarkMap(bench *testing.B) {
+  b, elements, function := New(), benchmarkElements(), benchmarkFunction("x")
+  list := object.NewList(elements)
+  bench.Run("vector", func(bench *testing.B) {
+    for i := 0; i < bench.N; i++ {
+      _map(object.NewEnvironment(), b, benchmarkEval, function, list)
+    }
+  })
+  bench.Run("slice", func(bench *testing.B) {
+    for i := 0; i < bench.N; i++ {
+      sliceMap(function, elements, b)
+    }
+  })
+}
+
+func BenchmarkFilter(bench *testing.B) {
+  b, elements, function := New(), benchmarkElements(), benchmarkFunction("x")
+  list := object.NewList(elements)
+  bench.Run("vector", func(bench *testing.B) {
+    for i := 0; i < bench.N; i++ {
+      _filter(object.NewEnvironment(), b, benchmarkEval, function, list)
+    }
+  })
+  bench.Run("slice", func(bench *testing.B) {
+    for i := 0; i < bench.N; i++ {
+      sliceFilter(function, elements, b)
+    }
+  })
+}
+
+func BenchmarkZip(bench *testing.B) {
+  b, elements := New(), benchmarkElements()
+  list := object.NewList(elements)
+  bench.Run("vector", func(bench *testing.B) {
+    for i := 0; i < bench.N; i++ {
+      _zip(object.NewEnvironment(), b, benchmarkEval, list, list)
+    }
+  })
+  bench.Run("slice", func(bench *testing.B) {
+    for i := 0; i < bench.N; i++ {
+      sliceZip([][]object.Object{elements, elements})
+    }
+  })
+}
+
+func BenchmarkReduce(bench *testing.B) {
+  b, elements, function := New(), benchmarkElements(), benchmarkFunction("x", "y")
+  list := object.NewList(elements)
+  bench.Run("vector", func(bench *testing.B) {
+    for i := 0; i < bench.N; i++ {
+      _reduce(object.NewEnvironment(), b, benchmarkEval, function, list)
+    }
+  })
+  bench.Run("slice", func(bench *testing.B) {
+    for i := 0; i < bench.N; i++ {
+      sliceReduce(function, elements, b)
+    }
+  })
+}
+
+func BenchmarkAppend(bench *testing.B) {
+  b := New()
+  bench.Run("vector", func(bench *testing.B) {
+    for i := 0; i < bench.N; i++ {
+      list := object.NewList(nil)
+      for j := 0; j < 10000; j++ {
+        list = _append(object.NewEnvironment(), b, nil, list, &object.Integer{Value: int64(j)}).(*object.List)
+      }
+    }
+  })
+  bench.Run("copied slice", func(bench *testing.B) {
+    for i := 0; i < bench.N; i++ {
+      elements := make([]object.Object, 0)
+      for j := 0; j < 10000; j++ {
+        copied := make([]object.Object, len(elements), len(elements)+1)
+        copy(copied, elements)
+        elements = append(copied, &object.Integer{Value: int64(j)})
+      }
+    }
+  })
+}
+
+func TestNativeChecks(t *testing.T) {
+  params := []object.Object{&object.Integer{Value: 1}, &object.String{Value: "a"}}
+  tests := []struct {
+    err      *object.ErrorObject
+    expected string
+  }{
+    {CheckArity("f", params, 2, 2), ""},
+    {CheckArity("f", params, 1, -1), ""},
+    {CheckArity("f", params, 1, 1), "f expected 1 params and got 2"},
+    {CheckArity("f", params, 3, -1), "f expected at least 3 params and got 2"},
+    {CheckArity("f", params, 0, 1), "f expected between 0 and 1 params and got 2"},
+    {CheckTypes("f", params, object.INTEGER_OBJ, object.STRING_OBJ), ""},
+    {CheckTypes("f", params, ANY), ""},
+    {CheckTypes("f", params, ANY, object.INTEGER_OBJ), "f expected INTEGER as param 2 and got STRING"},
+  }
+  for _, test := range tests {
+    if test.expected == "" && test.err != nil {
+      t.Errorf("expected no error and got %s", test.err.Error)
+    } else if test.expected != "" && (test.err == nil || test.err.Error != test.expected) {
+      t.Errorf("expected error %s and got %v", test.expected, test.err)
+    }
+  }
+}
+
+func TestRegisterFunction(t *testing.T) {
+  b := New()
+  b.RegisterFunction("answer", func(caller *Caller, params ...object.Object) object.Object {
+    return &object.Integer{Value: 42}
+  })
+  b.RegisterFunction("nothing", func(caller *Caller, params ...object.Object) object.Object {
+    return nil
+  })
+  answer, _ := b.GetObject("answer")
+  if value := answer.(*BuiltinFunction).Function(object.NewEnvironment(), b, nil); value.Inspect() != "42" {
+    t.Errorf("expected 42 and got %s", value.Inspect())
+  }
+  nothing, _ := b.GetObject("nothing")
+  if value := nothing.(*BuiltinFunction).Function(object.NewEnvironment(), b, nil); value != object.NULL {
+    t.Errorf("a nil result should be null and got %v", value)
+  }
+  module := b.RegisterModule("company", map[string]NativeFunction{"id": func(caller *Caller, params ...object.Object) object.Object { return params[0] }})
+  if registered, ok := b.GetObject("company"); !ok || registered != module || module.Path != "/company" {
+    t.Errorf("the module should be registered as company")
+  }
+  if _, ok := module.Env.GetVar("id"); !ok {
+    t.Errorf("the module should have the function id")
+  }
+}
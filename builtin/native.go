@@ -0,0 +1,90 @@
+package builtin
+
+import (
+	"rootlang/object"
+	"rootlang/ast"
+	"fmt"
+)
+
+// ANY is the type CheckTypes accepts for a param of any type
+const ANY = object.ObjectType("ANY")
+
+// NativeFunction is the signature of the functions written in Go that a host adds to rootlang. params are the values
+// of the call, a returned *object.ErrorObject is raised as a rootlang error and a nil result is null. The caller calls
+// back the rootlang functions the native function receives.
+type NativeFunction func(caller *Caller, params ...object.Object) object.Object
+
+// Caller is given to a native function on every call to call back rootlang from Go
+type Caller struct {
+	environment *object.Environment
+	builtins    *Builtin
+	eval        func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object
+}
+
+// Call calls a rootlang function, a builtin or a native function with params, an error is returned as an *object.ErrorObject
+func (c *Caller) Call(function object.Object, params ...object.Object) object.Object {
+	if builtinFunction, ok := function.(*BuiltinFunction); ok {
+		return builtinFunction.Function(c.environment, c.builtins, c.eval, params...)
+	}
+	return callFunction(function, params, c.builtins, c.eval)
+}
+
+// Builtins returns the builtin symbols of the program that made the call
+func (c *Caller) Builtins() *Builtin {
+	return c.builtins
+}
+
+// NewFunction wraps a native function so it can be bound to a name or put in a module environment
+func NewFunction(name string, native NativeFunction) *BuiltinFunction {
+	return getBuiltinFunction(func(env *object.Environment, b *Builtin, eval func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
+		result := native(&Caller{environment: env, builtins: b, eval: eval}, params...)
+		if result == nil {
+			return object.NULL
+		}
+		return result
+	}, name)
+}
+
+// RegisterFunction makes the native function visible in every module with name, a builtin with the same name is replaced
+func (b *Builtin) RegisterFunction(name string, native NativeFunction) {
+	b.symbols[name] = NewFunction(name, native)
+}
+
+// RegisterModule adds a module with the native functions that is imported like /net, import "/name" as name;
+func (b *Builtin) RegisterModule(name string, functions map[string]NativeFunction) *object.Module {
+	env := object.NewEnvironment()
+	for functionName, native := range functions {
+		env.SetVar(functionName, NewFunction(functionName, native))
+	}
+	module := &object.Module{Env: env, Name: name, Path: "/" + name}
+	b.symbols[name] = module
+	return module
+}
+
+// CheckArity returns an error when the call has less than min params or more than max, a negative max has no limit
+func CheckArity(name string, params []object.Object, min, max int) *object.ErrorObject {
+	if len(params) < min || (max >= 0 && len(params) > max) {
+		if min == max {
+			return &object.ErrorObject{Error: fmt.Sprintf("%s expected %d params and got %d", name, min, len(params))}
+		}
+		if max < 0 {
+			return &object.ErrorObject{Error: fmt.Sprintf("%s expected at least %d params and got %d", name, min, len(params))}
+		}
+		return &object.ErrorObject{Error: fmt.Sprintf("%s expected between %d and %d params and got %d", name, min, max, len(params))}
+	}
+	return nil
+}
+
+// CheckTypes returns an error when a param is not of the type in the same position, the params without a type are not
+// checked and ANY accepts every type
+func CheckTypes(name string, params []object.Object, types ...object.ObjectType) *object.ErrorObject {
+	for i, param := range params {
+		if i >= len(types) || types[i] == ANY {
+			continue
+		}
+		if param.Type() != types[i] {
+			return &object.ErrorObject{Error: fmt.Sprintf("%s expected %s as param %d and got %s", name, types[i], i+1, param.Type())}
+		}
+	}
+	return nil
+}
@@ -6,6 +6,8 @@ import "net"
 import (
 	"fmt"
 	"bufio"
+	"os"
+	"sync"
 )
 
 const (
@@ -26,9 +28,11 @@ func (client *Client) Inspect() string {
 	return fmt.Sprintf("%s", client.id)
 }
 
+// Server keeps the connected clients, every client is read in its own goroutine so the map is guarded by mutex
 type Server struct {
 	listener net.Listener
 	port     int64
+	mutex    sync.Mutex
 	clients  map[string]*Client
 }
 
@@ -80,10 +84,12 @@ func _get_clients(env *object.Environment, b *Builtin, eval func(node ast.Node,
 	}
 	server := params[0].(*Server)
 	values := make([]object.Object, 0)
+	server.mutex.Lock()
 	for _, value := range server.clients {
 		values = append(values, value)
 	}
-	return &object.List{Elements: values}
+	server.mutex.Unlock()
+	return object.NewList(values)
 }
 
 func _listen(env *object.Environment, b *Builtin, eval func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
@@ -94,8 +100,8 @@ func _listen(env *object.Environment, b *Builtin, eval func(node ast.Node, envir
 		return &object.ErrorObject{Error: "the signature expected is net::listen(port, (server, new-client) => {}, (client, data) => {});"}
 	}
 	port := params[0].(*object.Integer).Value
-	onClientConnect := params[1].(*object.Function)
-	onClientWrite := params[2].(*object.Function)
+	onClientConnect := params[1]
+	onClientWrite := params[2]
 	serverConnection, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
 		return &object.ErrorObject{Error: err.Error() }
@@ -109,8 +115,10 @@ func _listen(env *object.Environment, b *Builtin, eval func(node ast.Node, envir
 		}
 		client := createClient(conn)
 		params := []object.Object{server, client}
+		server.mutex.Lock()
 		server.clients[client.id] = client
-		returnValue := applyArgumentsToFunctionAndCall(onClientConnect, params, b, eval)
+		server.mutex.Unlock()
+		returnValue := callFunction(onClientConnect, params, b, eval)
 		if returnValue != nil && isErrorObject(returnValue) {
 			return returnValue
 		}
@@ -119,12 +127,24 @@ func _listen(env *object.Environment, b *Builtin, eval func(node ast.Node, envir
 
 }
 
-func handleClient(server *Server, client *Client, onClientWrite *object.Function, b *Builtin, eval func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object) {
+func handleClient(server *Server, client *Client, onClientWrite object.Object, b *Builtin, eval func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object) {
+	reader := bufio.NewReader(client.con)
 	for {
 
-		message, _ := bufio.NewReader(client.con).ReadString('\n')
+		message, err := reader.ReadString('\n')
+		if err != nil {
+			server.mutex.Lock()
+			delete(server.clients, client.id)
+			server.mutex.Unlock()
+			client.con.Close()
+			return
+		}
 		buffer := createReaderBufferFromString(message)
-		applyArgumentsToFunctionAndCall(onClientWrite, []object.Object{server, client, buffer}, b, eval)
+		returnValue := callFunction(onClientWrite, []object.Object{server, client, buffer}, b, eval)
+		if errorObject, ok := returnValue.(*object.ErrorObject); ok {
+			os.Stderr.WriteString(fmt.Sprintf("Error On Client %s  --> %s\n", client.id, errorObject.Inspect()))
+			os.Stderr.WriteString(errorObject.StackTrace())
+		}
 	}
 }
 
@@ -0,0 +1,328 @@
+package builtin
+
+import (
+	"rootlang/object"
+	"rootlang/ast"
+	"fmt"
+	"math/big"
+)
+
+type iterator func() (object.Object, bool)
+
+// toIterator starts a pass over a sequence or a list, any other value is a sequence with only that value
+func toIterator(value object.Object) iterator {
+	switch valueType := value.(type) {
+	case *object.Sequence:
+		return valueType.Iterator()
+	case *object.List:
+		i := 0
+		return func() (object.Object, bool) {
+			if i >= valueType.Len() {
+				return nil, false
+			}
+			i++
+			return valueType.Get(i - 1), true
+		}
+	default:
+		done := false
+		return func() (object.Object, bool) {
+			if done {
+				return nil, false
+			}
+			done = true
+			return value, true
+		}
+	}
+}
+
+// chainIterator passes over the values one after the other like map and filter do with their params
+func chainIterator(values []object.Object) iterator {
+	i := 0
+	var current iterator
+	return func() (object.Object, bool) {
+		for {
+			if current == nil {
+				if i >= len(values) {
+					return nil, false
+				}
+				current = toIterator(values[i])
+				i++
+			}
+			if value, ok := current(); ok {
+				return value, true
+			}
+			current = nil
+		}
+	}
+}
+
+func _hasSequence(params []object.Object) bool {
+	for _, param := range params {
+		if param.Type() == object.SEQUENCE_OBJ {
+			return true
+		}
+	}
+	return false
+}
+
+func _checkIntegers(name string, params []object.Object) *object.ErrorObject {
+	for _, param := range params {
+		if _, ok := param.(*object.Integer); !ok {
+			return &object.ErrorObject{Error: fmt.Sprintf("%s expect integer params and got %s", name, param.Type())}
+		}
+	}
+	return nil
+}
+
+// _integerParams returns the counts given to a sequence function, a big integer is an error instead of a clamped count
+func _integerParams(name string, params []object.Object) ([]int64, *object.ErrorObject) {
+	if err := _checkIntegers(name, params); err != nil {
+		return nil, err
+	}
+	values := make([]int64, 0)
+	for _, param := range params {
+		integer := param.(*object.Integer)
+		if integer.Big != nil {
+			return nil, &object.ErrorObject{Error: fmt.Sprintf("%s count %s does not fit in 64 bits", name, integer.Inspect())}
+		}
+		values = append(values, integer.Value)
+	}
+	return values, nil
+}
+
+func _range(_ *object.Environment, _ *Builtin, _ func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
+	if len(params) < 1 || len(params) > 3 {
+		return &object.ErrorObject{Error: fmt.Sprintf("range expect the start, an optional end and an optional step and got %d params", len(params))}
+	}
+	if err := _checkIntegers("range", params); err != nil {
+		return err
+	}
+	start, step := params[0].(*object.Integer), &object.Integer{Value: 1}
+	if len(params) == 3 {
+		step = params[2].(*object.Integer)
+	}
+	direction := step.Cmp(&object.Integer{})
+	if direction == 0 {
+		return &object.ErrorObject{Error: "range step can not be 0"}
+	}
+	return &object.Sequence{Iterator: func() func() (object.Object, bool) {
+		next := start
+		return func() (object.Object, bool) {
+			if len(params) > 1 && next.Cmp(params[1].(*object.Integer)) != -direction {
+				return nil, false
+			}
+			current := next
+			next = addIntegers(current, step)
+			return current, true
+		}
+	}}
+}
+
+// addIntegers goes on with a big integer when the sum overflows an int64 like the + operator
+func addIntegers(left, right *object.Integer) *object.Integer {
+	if left.Big == nil && right.Big == nil {
+		if sum := left.Value + right.Value; (sum > left.Value) == (right.Value > 0) {
+			return &object.Integer{Value: sum}
+		}
+	}
+	return object.NewBigInteger(new(big.Int).Add(left.BigValue(), right.BigValue()))
+}
+
+func _iterate(_ *object.Environment, b *Builtin, eval func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
+	if len(params) != 2 {
+		return &object.ErrorObject{Error: fmt.Sprintf("iterate expect the function and the seed and got %d params", len(params))}
+	}
+	function := params[0]
+	if _, ok := functionParams(function); !ok {
+		return &object.ErrorObject{Error: fmt.Sprintf("iterate first params should be function and got %s", params[0].Type())}
+	}
+	return &object.Sequence{Iterator: func() func() (object.Object, bool) {
+		var value object.Object
+		return func() (object.Object, bool) {
+			if value == nil {
+				value = params[1]
+			} else if value.Type() != object.ERROR_OBJ {
+				value = callFunction(function, []object.Object{value}, b, eval)
+			}
+			return value, true
+		}
+	}}
+}
+
+func _repeat(_ *object.Environment, _ *Builtin, _ func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
+	if len(params) != 1 && len(params) != 2 {
+		return &object.ErrorObject{Error: fmt.Sprintf("repeat expect the value and an optional count and got %d params", len(params))}
+	}
+	count := int64(-1)
+	if len(params) == 2 {
+		counts, err := _integerParams("repeat", params[1:])
+		if err != nil {
+			return err
+		}
+		count = counts[0]
+	}
+	return &object.Sequence{Iterator: func() func() (object.Object, bool) {
+		repeated := int64(0)
+		return func() (object.Object, bool) {
+			if count >= 0 && repeated >= count {
+				return nil, false
+			}
+			repeated++
+			return params[0], true
+		}
+	}}
+}
+
+func _take(_ *object.Environment, _ *Builtin, _ func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
+	if len(params) != 2 {
+		return &object.ErrorObject{Error: fmt.Sprintf("take expect the count and the sequence and got %d params", len(params))}
+	}
+	counts, err := _integerParams("take", params[:1])
+	if err != nil {
+		return err
+	}
+	return &object.Sequence{Iterator: func() func() (object.Object, bool) {
+		next := toIterator(params[1])
+		taken := int64(0)
+		return func() (object.Object, bool) {
+			if taken >= counts[0] {
+				return nil, false
+			}
+			taken++
+			return next()
+		}
+	}}
+}
+
+func _drop(_ *object.Environment, _ *Builtin, _ func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
+	if len(params) != 2 {
+		return &object.ErrorObject{Error: fmt.Sprintf("drop expect the count and the sequence and got %d params", len(params))}
+	}
+	counts, err := _integerParams("drop", params[:1])
+	if err != nil {
+		return err
+	}
+	return &object.Sequence{Iterator: func() func() (object.Object, bool) {
+		next := toIterator(params[1])
+		dropped := false
+		return func() (object.Object, bool) {
+			for i := int64(0); !dropped && i < counts[0]; i++ {
+				if value, ok := next(); !ok || value.Type() == object.ERROR_OBJ {
+					return value, ok
+				}
+			}
+			dropped = true
+			return next()
+		}
+	}}
+}
+
+func _take_while(_ *object.Environment, b *Builtin, eval func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
+	if len(params) != 2 {
+		return &object.ErrorObject{Error: fmt.Sprintf("take_while expect the function and the sequence and got %d params", len(params))}
+	}
+	function := params[0]
+	if _, ok := functionParams(function); !ok {
+		return &object.ErrorObject{Error: fmt.Sprintf("take_while first params should be function and got %s", params[0].Type())}
+	}
+	return &object.Sequence{Iterator: func() func() (object.Object, bool) {
+		next := toIterator(params[1])
+		taking := true
+		return func() (object.Object, bool) {
+			if !taking {
+				return nil, false
+			}
+			value, ok := next()
+			if !ok || value.Type() == object.ERROR_OBJ {
+				return value, ok
+			}
+			condition := callFunction(function, []object.Object{value}, b, eval)
+			if condition.Type() == object.ERROR_OBJ {
+				return condition, true
+			}
+			taking = evalTruthValue(condition)
+			if !taking {
+				return nil, false
+			}
+			return value, true
+		}
+	}}
+}
+
+func _collect(_ *object.Environment, _ *Builtin, _ func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object {
+	if len(params) != 1 {
+		return &object.ErrorObject{Error: fmt.Sprintf("collect only recive 1 params and got %d", len(params))}
+	}
+	switch param := params[0].(type) {
+	case *object.List:
+		return param
+	case *object.Sequence:
+		elements := make([]object.Object, 0)
+		next := param.Iterator()
+		for value, ok := next(); ok; value, ok = next() {
+			if value.Type() == object.ERROR_OBJ {
+				return value
+			}
+			elements = append(elements, value)
+		}
+		return object.NewList(elements)
+	default:
+		return &object.ErrorObject{Error: fmt.Sprintf("collect expected a sequence or a list and got %s", params[0].Type())}
+	}
+}
+
+// lazyMap is map over params with a sequence, the function is called when the value is read
+func lazyMap(function object.Object, params []object.Object, b *Builtin, eval func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object) object.Object {
+	return &object.Sequence{Iterator: func() func() (object.Object, bool) {
+		next := chainIterator(params)
+		return func() (object.Object, bool) {
+			value, ok := next()
+			if !ok || value.Type() == object.ERROR_OBJ {
+				return value, ok
+			}
+			return callFunction(function, []object.Object{value}, b, eval), true
+		}
+	}}
+}
+
+func lazyFilter(function object.Object, params []object.Object, b *Builtin, eval func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object) object.Object {
+	return &object.Sequence{Iterator: func() func() (object.Object, bool) {
+		next := chainIterator(params)
+		return func() (object.Object, bool) {
+			for {
+				value, ok := next()
+				if !ok || value.Type() == object.ERROR_OBJ {
+					return value, ok
+				}
+				condition := callFunction(function, []object.Object{value}, b, eval)
+				if condition.Type() == object.ERROR_OBJ {
+					return condition, true
+				}
+				if evalTruthValue(condition) {
+					return value, true
+				}
+			}
+		}
+	}}
+}
+
+// lazyZip reads one value of each param at a time and ends with the shortest of them
+func lazyZip(params []object.Object) object.Object {
+	return &object.Sequence{Iterator: func() func() (object.Object, bool) {
+		iterators := make([]iterator, len(params))
+		for i, param := range params {
+			iterators[i] = toIterator(param)
+		}
+		return func() (object.Object, bool) {
+			zipArray := make([]object.Object, 0)
+			for _, next := range iterators {
+				value, ok := next()
+				if !ok || value.Type() == object.ERROR_OBJ {
+					return value, ok
+				}
+				zipArray = append(zipArray, value)
+			}
+			return object.NewList(zipArray), true
+		}
+	}}
+}
@@ -12,13 +12,33 @@ const (
 	LEN    = "len"
 	LIST   = "list"
 	APPEND = "append"
+	PREPEND = "prepend"
+	CONCAT = "concat"
+	RANGE = "range"
+	ITERATE = "iterate"
+	REPEAT = "repeat"
+	TAKE = "take"
+	DROP = "drop"
+	TAKE_WHILE = "take_while"
+	COLLECT = "collect"
 	MAP    = "map"
 	FILTER = "filter"
 	ZIP    = "zip"
 	REDUCE = "reduce"
 	PRINT  = "print"
+	KEYS   = "keys"
+	VALUES = "values"
+	GET    = "get"
+	PUT    = "put"
+	REMOVE = "remove"
+	HAS    = "has"
+	ERROR  = "error"
+	IS_ERROR = "is_error"
+	ERROR_MESSAGE = "error_message"
+	RAISE  = "raise"
 	NET    = "net"
 	BYTES = "bytes"
+	FS = "fs"
 )
 
 type function func(env *object.Environment, b *Builtin, eval func(node ast.Node, environment *object.Environment, builtinSymbols *Builtin) object.Object, params ...object.Object) object.Object
@@ -40,13 +60,14 @@ func (builtinFunction *BuiltinFunction) Inspect() string {
 type Builtin struct {
 	symbols map[string]object.Object
 	modules map[string]*object.Module
+	loading []string
 	paths   []string
 }
 
 func New() *Builtin {
 	symbols := registerSymbols()
 	paths := make([]string, 0)
-	return &Builtin{symbols: symbols, paths: paths}
+	return &Builtin{symbols: symbols, modules: make(map[string]*object.Module), loading: make([]string, 0), paths: paths}
 }
 
 func registerSymbols() map[string]object.Object {
@@ -54,13 +75,33 @@ func registerSymbols() map[string]object.Object {
 	symbols[LEN] = getBuiltinFunction(_len, LEN)
 	symbols[LIST] = getBuiltinFunction(_list, LIST)
 	symbols[APPEND] = getBuiltinFunction(_append, APPEND)
+	symbols[PREPEND] = getBuiltinFunction(_prepend, PREPEND)
+	symbols[CONCAT] = getBuiltinFunction(_concat, CONCAT)
+	symbols[RANGE] = getBuiltinFunction(_range, RANGE)
+	symbols[ITERATE] = getBuiltinFunction(_iterate, ITERATE)
+	symbols[REPEAT] = getBuiltinFunction(_repeat, REPEAT)
+	symbols[TAKE] = getBuiltinFunction(_take, TAKE)
+	symbols[DROP] = getBuiltinFunction(_drop, DROP)
+	symbols[TAKE_WHILE] = getBuiltinFunction(_take_while, TAKE_WHILE)
+	symbols[COLLECT] = getBuiltinFunction(_collect, COLLECT)
 	symbols[MAP] = getBuiltinFunction(_map, MAP)
 	symbols[FILTER] = getBuiltinFunction(_filter, FILTER)
 	symbols[ZIP] = getBuiltinFunction(_zip, ZIP)
 	symbols[REDUCE] = getBuiltinFunction(_reduce, REDUCE)
 	symbols[PRINT] = getBuiltinFunction(_print, PRINT)
+	symbols[KEYS] = getBuiltinFunction(_keys, KEYS)
+	symbols[VALUES] = getBuiltinFunction(_values, VALUES)
+	symbols[GET] = getBuiltinFunction(_get, GET)
+	symbols[PUT] = getBuiltinFunction(_put, PUT)
+	symbols[REMOVE] = getBuiltinFunction(_remove, REMOVE)
+	symbols[HAS] = getBuiltinFunction(_has, HAS)
+	symbols[ERROR] = getBuiltinFunction(_error, ERROR)
+	symbols[IS_ERROR] = getBuiltinFunction(_is_error, IS_ERROR)
+	symbols[ERROR_MESSAGE] = getBuiltinFunction(_error_message, ERROR_MESSAGE)
+	symbols[RAISE] = getBuiltinFunction(_raise, RAISE)
 	symbols[NET] = buildNetModule()
 	symbols[BYTES] = buildBytesModule()
+	symbols[FS] = buildFsModule()
 	return symbols
 }
 
@@ -72,6 +113,33 @@ func (b *Builtin) GetPaths() []string {
 	return b.paths
 }
 
+// GetModule returns the module already loaded from the canonical path of its file
+func (b *Builtin) GetModule(path string) (*object.Module, bool) {
+	module, ok := b.modules[path]
+	return module, ok
+}
+
+func (b *Builtin) SetModule(path string, module *object.Module) {
+	b.modules[path] = module
+}
+
+// StartLoading marks the module of path as being evaluated, when it already is the import is a cycle and the chain of
+// imports that leads back to it is returned
+func (b *Builtin) StartLoading(path string) ([]string, bool) {
+	for i, loading := range b.loading {
+		if loading == path {
+			chain := append([]string{}, b.loading[i:]...)
+			return append(chain, path), true
+		}
+	}
+	b.loading = append(b.loading, path)
+	return nil, false
+}
+
+func (b *Builtin) EndLoading() {
+	b.loading = b.loading[:len(b.loading)-1]
+}
+
 func (b *Builtin) GetObject(name string) (object.Object, bool) {
 	value, ok := b.symbols[name]
 	return value, ok
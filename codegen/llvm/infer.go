@@ -0,0 +1,205 @@
+package llvm
+
+import (
+	"rootlang/ast"
+)
+
+// unknownType is the result of a call to a function whose return type is not inferred yet, it is ignored when joined
+const unknownType = "?"
+
+type inferenceScope struct {
+	types     map[string]string
+	functions map[string]*ast.FunctionExpression
+	outer     *inferenceScope
+}
+
+func newInferenceScope(outer *inferenceScope) *inferenceScope {
+	return &inferenceScope{types: make(map[string]string), functions: make(map[string]*ast.FunctionExpression), outer: outer}
+}
+
+func (s *inferenceScope) lookup(name string) (string, *ast.FunctionExpression) {
+	for scope := s; scope != nil; scope = scope.outer {
+		if typ, ok := scope.types[name]; ok {
+			return typ, nil
+		}
+		if fn, ok := scope.functions[name]; ok {
+			return "", fn
+		}
+	}
+	return unknownType, nil
+}
+
+// inference finds the type every function returns following the rules of the generator, the types of recursive
+// functions are refined until none of them changes
+type inference struct {
+	returns map[*ast.FunctionExpression]string
+	current []string
+	changed bool
+}
+
+// inferReturnTypes returns i1 for the functions that always return a boolean and i64 for the others
+func inferReturnTypes(program *ast.Program) map[*ast.FunctionExpression]string {
+	in := &inference{returns: make(map[*ast.FunctionExpression]string)}
+	for in.changed = true; in.changed; {
+		in.changed = false
+		top := newInferenceScope(nil)
+		for _, statement := range program.Statements {
+			if let, ok := statement.(*ast.LetStatement); ok {
+				if fn, ok := let.Value.(*ast.FunctionExpression); ok {
+					top.functions[let.Name.Value] = fn
+				}
+			}
+		}
+		for _, statement := range program.Statements {
+			if let, ok := statement.(*ast.LetStatement); ok {
+				if _, ok := let.Value.(*ast.FunctionExpression); !ok {
+					top.types[let.Name.Value] = in.expression(let.Value, top)
+				}
+			}
+		}
+		for _, statement := range program.Statements {
+			if let, ok := statement.(*ast.LetStatement); ok {
+				if fn, ok := let.Value.(*ast.FunctionExpression); ok {
+					in.function(fn, top)
+				}
+			}
+		}
+	}
+	for fn, typ := range in.returns {
+		if typ == unknownType {
+			in.returns[fn] = "i64"
+		}
+	}
+	return in.returns
+}
+
+func (in *inference) function(fn *ast.FunctionExpression, outer *inferenceScope) {
+	scope := newInferenceScope(outer)
+	for _, param := range fn.Params {
+		scope.types[param.Value] = "i64"
+	}
+	in.current = append(in.current, unknownType)
+	result, terminated := in.block(fn.Block, scope)
+	returned := in.current[len(in.current)-1]
+	in.current = in.current[:len(in.current)-1]
+	if !terminated {
+		if result == "" {
+			result = "i64"
+		}
+		returned = joinTypes(returned, result)
+	}
+	if previous, ok := in.returns[fn]; !ok || previous != returned {
+		in.returns[fn] = returned
+		in.changed = true
+	}
+}
+
+// block returns the type of the last statement and whether the block always returns
+func (in *inference) block(block *ast.BlockStatement, scope *inferenceScope) (string, bool) {
+	result := ""
+	for _, statement := range block.Statements {
+		result = ""
+		switch node := statement.(type) {
+		case *ast.ExpressionStatement:
+			if ifExpression, ok := node.Exp.(*ast.IfExpression); ok {
+				var terminated bool
+				if result, terminated = in.ifExpression(ifExpression, scope); terminated {
+					return "", true
+				}
+				continue
+			}
+			result = in.expression(node.Exp, scope)
+		case *ast.LetStatement:
+			if fn, ok := node.Value.(*ast.FunctionExpression); ok {
+				scope.functions[node.Name.Value] = fn
+				in.function(fn, scope)
+				continue
+			}
+			scope.types[node.Name.Value] = in.expression(node.Value, scope)
+		case *ast.ReturnStatement:
+			returned := in.expression(node.Value, scope)
+			if returned == "" {
+				returned = "i64"
+			}
+			in.current[len(in.current)-1] = joinTypes(in.current[len(in.current)-1], returned)
+			return "", true
+		}
+	}
+	return result, false
+}
+
+func (in *inference) ifExpression(ifExpression *ast.IfExpression, scope *inferenceScope) (string, bool) {
+	in.expression(ifExpression.Condition, scope)
+	thenType, thenTerminated := in.block(ifExpression.ConditionalBlock, scope)
+	if ifExpression.AlternativeBlock == nil {
+		return "", false
+	}
+	elseType, elseTerminated := in.block(ifExpression.AlternativeBlock, scope)
+	switch {
+	case thenTerminated && elseTerminated:
+		return "", true
+	case elseTerminated:
+		return thenType, false
+	case thenTerminated:
+		return elseType, false
+	case thenType == "" || elseType == "":
+		return "", false
+	}
+	return joinTypes(thenType, elseType), false
+}
+
+func (in *inference) expression(expression ast.Expression, scope *inferenceScope) string {
+	switch node := expression.(type) {
+	case *ast.IntegerLiteral:
+		return "i64"
+	case *ast.BoolExpression:
+		return "i1"
+	case *ast.Identifier:
+		typ, _ := scope.lookup(node.Value)
+		return typ
+	case *ast.PrefixExpression:
+		in.expression(node.RightExpression, scope)
+		if node.Operator == "!" {
+			return "i1"
+		}
+		return "i64"
+	case *ast.InfixExpression:
+		in.expression(node.LeftExpression, scope)
+		in.expression(node.RightExpression, scope)
+		if _, compare := comparisons[node.Operator]; compare {
+			return "i1"
+		}
+		return "i64"
+	case *ast.IfExpression:
+		typ, _ := in.ifExpression(node, scope)
+		return typ
+	case *ast.CallFunctionExpression:
+		for _, argument := range node.Arguments {
+			in.expression(argument, scope)
+		}
+		identifier, ok := node.Function.(*ast.Identifier)
+		if !ok {
+			return unknownType
+		}
+		_, fn := scope.lookup(identifier.Value)
+		if fn == nil {
+			return ""
+		}
+		if typ, ok := in.returns[fn]; ok {
+			return typ
+		}
+		return unknownType
+	}
+	return unknownType
+}
+
+// joinTypes is the type of a value that can come from both types, a boolean mixed with integers is widened
+func joinTypes(a, b string) string {
+	switch {
+	case a == unknownType:
+		return b
+	case b == unknownType || a == b:
+		return a
+	}
+	return "i64"
+}
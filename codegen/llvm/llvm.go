@@ -0,0 +1,730 @@
+// Package llvm emits textual LLVM IR for the subset of rootlang made of integers, booleans, if, let and functions called by name.
+// Integers are i64 and booleans i1, every function takes and returns i64 words so a boolean crossing a call is widened to 0 or 1,
+// the result of a function that always returns a boolean is truncated back to i1 after the call.
+// Functions declared with let inside another function are lowered to an environment struct holding pointers to the
+// variables they capture, they can only be called by name so the struct lives in the stack frame of the function declaring them.
+package llvm
+
+import (
+	"rootlang/ast"
+	"rootlang/lexer"
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	variableBinding = iota
+	globalBinding
+	functionBinding
+)
+
+var typeNames = map[string]string{"i64": "INTEGER", "i1": "BOOLEAN"}
+
+var infixInstructions = map[string]string{"+": "add", "-": "sub", "*": "mul", "/": "sdiv", "%": "srem"}
+
+// checkedInstructions are the operations that can overflow, they run through the intrinsic that reports it
+var checkedInstructions = map[string]string{"add": "sadd", "sub": "ssub", "mul": "smul"}
+
+var comparisons = map[string]string{"==": "eq", "!=": "ne", "<": "slt", ">": "sgt"}
+
+// value is the result of an expression, typ is empty when the expression has no value and reason tells why
+type value struct {
+	typ      string
+	ref      string
+	constant int64
+	reason   string
+}
+
+var noValue = value{reason: "expression has no value"}
+
+// binding is what a name refers to, ref is the address of variables and globals and the symbol of functions,
+// env is the pointer to the environment struct passed to closures, empty for top level functions. returns is the type
+// of the value a function returns once it is truncated back from the i64 word.
+type binding struct {
+	kind     int
+	typ      string
+	ref      string
+	env      string
+	arity    int
+	returns  string
+	function *function
+}
+
+type function struct {
+	symbol     string
+	outer      *function
+	top        bool
+	done       bool
+	names      map[string]*binding
+	captures   []string
+	allocas    *bytes.Buffer
+	body       []string
+	block      string
+	terminated bool
+	temps      int
+	labels     int
+	returned   string
+}
+
+type Generator struct {
+	module    string
+	globals   map[string]*binding
+	types     *bytes.Buffer
+	constants *bytes.Buffer
+	functions *bytes.Buffer
+	strings   map[string]string
+	declares  map[string]string
+	returns   map[*ast.FunctionExpression]string
+	current   *function
+}
+
+func New(module string) *Generator {
+	return &Generator{module: module, globals: make(map[string]*binding), types: bytes.NewBufferString(""),
+		constants: bytes.NewBufferString(""), functions: bytes.NewBufferString(""), strings: make(map[string]string),
+		declares: make(map[string]string)}
+}
+
+type emitError struct {
+	message string
+}
+
+func (e emitError) Error() string {
+	return e.message
+}
+
+func (g *Generator) fail(position lexer.Position, message string) {
+	panic(emitError{fmt.Sprintf("%s: %s", position, message)})
+}
+
+func (g *Generator) unsupported(node ast.Node, construct string) {
+	g.fail(node.Position(), fmt.Sprintf("%s is not supported by the llvm backend", construct))
+}
+
+// Emit lowers a program to a module whose main runs the top level lets and then calls the main function
+func (g *Generator) Emit(program *ast.Program) (ir string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			emitErr, ok := r.(emitError)
+			if !ok {
+				panic(r)
+			}
+			ir, err = "", emitErr
+		}
+	}()
+	g.returns = inferReturnTypes(program)
+	functions := make([]*ast.LetStatement, 0)
+	for _, statement := range program.Statements {
+		let, ok := statement.(*ast.LetStatement)
+		if !ok {
+			continue
+		}
+		if fn, ok := let.Value.(*ast.FunctionExpression); ok {
+			if _, declared := g.globals[let.Name.Value]; declared {
+				g.fail(let.Position(), fmt.Sprintf("%s is already declared", let.Name.Value))
+			}
+			g.globals[let.Name.Value] = &binding{kind: functionBinding, ref: "@rl." + let.Name.Value, arity: len(fn.Params), returns: g.returns[fn]}
+			functions = append(functions, let)
+		}
+	}
+	initFunction := g.enterFunction("@rt.init", nil)
+	initFunction.top = true
+	for _, statement := range program.Statements {
+		if let, ok := statement.(*ast.LetStatement); ok {
+			if _, ok := let.Value.(*ast.FunctionExpression); ok {
+				continue
+			}
+		}
+		g.compileStatement(statement)
+	}
+	if !initFunction.terminated {
+		g.emit("ret void")
+	}
+	g.leaveFunction()
+	for _, let := range functions {
+		g.compileFunction(let.Name.Value, let.Value.(*ast.FunctionExpression), g.globals[let.Name.Value])
+	}
+	return g.assemble(initFunction), nil
+}
+
+func (g *Generator) assemble(initFunction *function) string {
+	buffer := bytes.NewBufferString("")
+	buffer.WriteString(fmt.Sprintf("; ModuleID = '%s'\nsource_filename = \"%s\"\n", g.module, g.module))
+	if g.types.Len() > 0 {
+		buffer.WriteString("\n")
+		buffer.WriteString(g.types.String())
+	}
+	if g.constants.Len() > 0 {
+		buffer.WriteString("\n")
+		buffer.WriteString(g.constants.String())
+	}
+	buffer.WriteString(g.functions.String())
+	buffer.WriteString(fmt.Sprintf("\ndefine internal void @rt.init() {\nentry:\n%s%s}\n", initFunction.allocas, initFunction.text()))
+	buffer.WriteString("\ndefine i32 @main() {\nentry:\n  call void @rt.init()\n")
+	if main, ok := g.globals["main"]; ok && main.kind == functionBinding && main.arity == 0 {
+		buffer.WriteString("  call i64 @rl.main()\n")
+	}
+	buffer.WriteString("  ret i32 0\n}\n")
+	if len(g.declares) > 0 {
+		names := make([]string, 0, len(g.declares))
+		for name := range g.declares {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		buffer.WriteString("\n")
+		for _, name := range names {
+			buffer.WriteString(g.declares[name])
+			buffer.WriteString("\n")
+		}
+	}
+	return buffer.String()
+}
+
+func (g *Generator) enterFunction(symbol string, outer *function) *function {
+	f := &function{symbol: symbol, outer: outer, names: make(map[string]*binding), captures: make([]string, 0),
+		allocas: bytes.NewBufferString(""), body: make([]string, 0), block: "entry", returned: unknownType}
+	g.current = f
+	return f
+}
+
+func (f *function) text() string {
+	return strings.Join(f.body, "\n") + "\n"
+}
+
+func (g *Generator) leaveFunction() {
+	g.current.done = true
+	g.current = g.current.outer
+}
+
+func (g *Generator) compileFunction(name string, fn *ast.FunctionExpression, declared *binding) *function {
+	outer := g.current
+	f := g.enterFunction(declared.ref, outer)
+	declared.function = f
+	params := make([]string, 0, len(fn.Params)+1)
+	if outer != nil {
+		params = append(params, "ptr %env.ptr")
+		f.names[name] = &binding{kind: functionBinding, ref: f.symbol, env: "%env.ptr", arity: declared.arity, returns: declared.returns, function: f}
+	}
+	for _, param := range fn.Params {
+		params = append(params, fmt.Sprintf("i64 %%%s", param.Value))
+		paramBinding := &binding{kind: variableBinding, typ: "i64", ref: fmt.Sprintf("%%%s.addr", param.Value)}
+		f.names[param.Value] = paramBinding
+		fmt.Fprintf(f.allocas, "  %s = alloca i64\n  store i64 %%%s, ptr %s\n", paramBinding.ref, param.Value, paramBinding.ref)
+	}
+	result := g.compileBlock(fn.Block)
+	if !f.terminated {
+		if result.typ == "" {
+			f.returned = joinTypes(f.returned, "i64")
+			g.emit("ret i64 0")
+		} else {
+			f.returned = joinTypes(f.returned, result.typ)
+			g.emit("ret i64 %s", g.word(fn.Block, result).ref)
+		}
+	}
+	if f.returned != declared.returns && f.returned != unknownType {
+		g.fail(fn.Position(), fmt.Sprintf("the return type of %s could not be inferred", name))
+	}
+	linkage := ""
+	if outer != nil {
+		linkage = "internal "
+	}
+	fmt.Fprintf(g.functions, "\ndefine %si64 %s(%s) {\nentry:\n%s%s}\n", linkage, f.symbol, strings.Join(params, ", "), f.allocas, f.text())
+	g.leaveFunction()
+	return f
+}
+
+func (g *Generator) emit(format string, params ...interface{}) {
+	g.current.body = append(g.current.body, "  "+fmt.Sprintf(format, params...))
+}
+
+// insert adds an instruction before the line at index, used to widen a branch value once the type of the other branch is known
+func (g *Generator) insert(index int, format string, params ...interface{}) {
+	body := append(g.current.body[:index:index], "  "+fmt.Sprintf(format, params...))
+	g.current.body = append(body, g.current.body[index:]...)
+}
+
+func (g *Generator) temp() string {
+	g.current.temps++
+	return fmt.Sprintf("%%t.%d", g.current.temps)
+}
+
+func (g *Generator) label(name string) string {
+	g.current.labels++
+	return fmt.Sprintf("%s.%d", name, g.current.labels)
+}
+
+func (g *Generator) startBlock(label string) {
+	g.current.body = append(g.current.body, label+":")
+	g.current.block = label
+	g.current.terminated = false
+}
+
+func (g *Generator) branch(format string, params ...interface{}) {
+	g.emit(format, params...)
+	g.current.terminated = true
+}
+
+func (g *Generator) compileBlock(block *ast.BlockStatement) value {
+	result := noValue
+	for _, statement := range block.Statements {
+		if g.current.terminated {
+			break
+		}
+		result = g.compileStatement(statement)
+	}
+	return result
+}
+
+func (g *Generator) compileStatement(statement ast.Statement) value {
+	switch node := statement.(type) {
+	case *ast.ExpressionStatement:
+		return g.compileExpression(node.Exp)
+	case *ast.LetStatement:
+		g.compileLet(node)
+	case *ast.ExportStatement:
+	case *ast.ReturnStatement:
+		if g.current.top {
+			g.unsupported(node, "return at the top level")
+		}
+		result := g.use(node.Value, g.compileExpression(node.Value))
+		g.current.returned = joinTypes(g.current.returned, result.typ)
+		g.branch("ret i64 %s", g.word(node.Value, result).ref)
+	default:
+		g.unsupported(statement, describe(statement))
+	}
+	return noValue
+}
+
+func (g *Generator) compileLet(let *ast.LetStatement) {
+	name := let.Name.Value
+	if fn, ok := let.Value.(*ast.FunctionExpression); ok && !g.current.top {
+		g.compileClosure(let, fn)
+		return
+	}
+	result := g.use(let.Value, g.compileExpression(let.Value))
+	names := g.current.names
+	if g.current.top {
+		names = g.globals
+	}
+	letBinding, ok := names[name]
+	if !ok {
+		if g.current.top {
+			letBinding = &binding{kind: globalBinding, typ: result.typ, ref: "@rl." + name}
+			fmt.Fprintf(g.constants, "%s = internal global %s %s\n", letBinding.ref, result.typ, zeroValue(result.typ))
+		} else {
+			letBinding = &binding{kind: variableBinding, typ: result.typ, ref: fmt.Sprintf("%%%s.addr", name)}
+			fmt.Fprintf(g.current.allocas, "  %s = alloca %s\n", letBinding.ref, result.typ)
+		}
+		names[name] = letBinding
+	} else if letBinding.kind == functionBinding || letBinding.typ != result.typ {
+		g.fail(let.Position(), fmt.Sprintf("%s is already declared with another type", name))
+	}
+	g.emit("store %s %s, ptr %s", result.typ, result.ref, letBinding.ref)
+}
+
+// compileClosure emits the function of a let nested in another function and fills its environment struct with the addresses it captures
+func (g *Generator) compileClosure(let *ast.LetStatement, fn *ast.FunctionExpression) {
+	name := let.Name.Value
+	outer := g.current
+	if _, declared := outer.names[name]; declared {
+		g.fail(let.Position(), fmt.Sprintf("%s is already declared", name))
+	}
+	closure := &binding{kind: functionBinding, ref: fmt.Sprintf("%s.%s", outer.symbol, name), env: fmt.Sprintf("%%%s.env", name),
+		arity: len(fn.Params), returns: g.returns[fn]}
+	outer.names[name] = closure
+	f := g.compileFunction(name, fn, closure)
+	if len(f.captures) == 0 {
+		closure.env = "null"
+		return
+	}
+	envType := environmentType(f)
+	fmt.Fprintf(g.types, "%s = type { %s }\n", envType, strings.TrimSuffix(strings.Repeat("ptr, ", len(f.captures)), ", "))
+	fmt.Fprintf(outer.allocas, "  %s = alloca %s\n", closure.env, envType)
+	for i, captured := range f.captures {
+		capturedBinding, owner := g.lookup(captured)
+		address := g.address(captured, capturedBinding, owner)
+		field := g.temp()
+		g.emit("%s = getelementptr %s, ptr %s, i32 0, i32 %d", field, envType, closure.env, i)
+		g.emit("store ptr %s, ptr %s", address, field)
+	}
+}
+
+func environmentType(f *function) string {
+	return fmt.Sprintf("%%%s.env", strings.TrimPrefix(f.symbol, "@"))
+}
+
+// lookup returns the binding of a name and the function declaring it, nil for the top level
+func (g *Generator) lookup(name string) (*binding, *function) {
+	for f := g.current; f != nil; f = f.outer {
+		if found, ok := f.names[name]; ok {
+			return found, f
+		}
+	}
+	return g.globals[name], nil
+}
+
+// address returns the pointer to a variable or to the environment of a closure, names of outer functions are captured in the environment of the current one
+func (g *Generator) address(name string, found *binding, owner *function) string {
+	if owner == nil {
+		return found.ref
+	}
+	if found.kind == functionBinding {
+		if found.function.done && len(found.function.captures) == 0 {
+			return "null"
+		}
+		if owner == g.current {
+			return found.env
+		}
+	} else if owner == g.current {
+		return found.ref
+	}
+	index := len(g.current.captures)
+	for i, captured := range g.current.captures {
+		if captured == name {
+			index = i
+		}
+	}
+	if index == len(g.current.captures) {
+		g.current.captures = append(g.current.captures, name)
+	}
+	field, address := g.temp(), g.temp()
+	g.emit("%s = getelementptr %s, ptr %%env.ptr, i32 0, i32 %d", field, environmentType(g.current), index)
+	g.emit("%s = load ptr, ptr %s", address, field)
+	return address
+}
+
+func (g *Generator) compileExpression(expression ast.Expression) value {
+	switch node := expression.(type) {
+	case *ast.IntegerLiteral:
+		if node.Big != nil {
+			g.fail(node.Position(), fmt.Sprintf("integer %s does not fit in 64 bits", node.Big.String()))
+		}
+		return value{typ: "i64", ref: fmt.Sprintf("%d", node.Value), constant: node.Value}
+	case *ast.BoolExpression:
+		return value{typ: "i1", ref: node.Value}
+	case *ast.Identifier:
+		found, owner := g.lookup(node.Value)
+		if found == nil {
+			g.fail(node.Position(), fmt.Sprintf("%s was not declare", node.Value))
+		}
+		if found.kind == functionBinding {
+			g.unsupported(node, "function value")
+		}
+		address := g.address(node.Value, found, owner)
+		result := g.temp()
+		g.emit("%s = load %s, ptr %s", result, found.typ, address)
+		return value{typ: found.typ, ref: result}
+	case *ast.PrefixExpression:
+		return g.compilePrefix(node)
+	case *ast.InfixExpression:
+		return g.compileInfix(node)
+	case *ast.IfExpression:
+		return g.compileIf(node)
+	case *ast.CallFunctionExpression:
+		return g.compileCall(node)
+	}
+	g.unsupported(expression, describe(expression))
+	return noValue
+}
+
+func (g *Generator) compilePrefix(prefix *ast.PrefixExpression) value {
+	right := g.use(prefix.RightExpression, g.compileExpression(prefix.RightExpression))
+	result := g.temp()
+	switch {
+	case prefix.Operator == "-" && right.typ == "i64" && isConstant(right):
+		return value{typ: "i64", ref: fmt.Sprintf("%d", -right.constant), constant: -right.constant}
+	case prefix.Operator == "-" && right.typ == "i64":
+		return g.checkedArithmetic(prefix.Position(), "sub", "0", right.ref)
+	case prefix.Operator == "!":
+		condition := g.truth(right)
+		g.emit("%s = xor i1 %s, true", result, condition.ref)
+		return value{typ: "i1", ref: result}
+	}
+	g.fail(prefix.Position(), fmt.Sprintf("unknow operator for %s%s", prefix.Operator, typeNames[right.typ]))
+	return noValue
+}
+
+func (g *Generator) compileInfix(infix *ast.InfixExpression) value {
+	left := g.use(infix.LeftExpression, g.compileExpression(infix.LeftExpression))
+	right := g.use(infix.RightExpression, g.compileExpression(infix.RightExpression))
+	instruction, arithmetic := infixInstructions[infix.Operator]
+	comparison, compare := comparisons[infix.Operator]
+	if !arithmetic && !compare {
+		g.unsupported(infix, fmt.Sprintf("operator %s", infix.Operator))
+	}
+	equality := infix.Operator == "==" || infix.Operator == "!="
+	if equality && left.typ != right.typ {
+		left, right = g.word(infix.LeftExpression, left), g.word(infix.RightExpression, right)
+	}
+	if !equality && (left.typ != "i64" || right.typ != "i64") {
+		g.fail(infix.Position(), fmt.Sprintf("unknow operator for %s %s %s", typeNames[left.typ], infix.Operator, typeNames[right.typ]))
+	}
+	if (infix.Operator == "/" || infix.Operator == "%") && (!isConstant(right) || right.constant == 0) {
+		isZero := g.temp()
+		divisionByZero, division := g.label("division.zero"), g.label("division")
+		g.emit("%s = icmp eq i64 %s, 0", isZero, right.ref)
+		g.branch("br i1 %s, label %%%s, label %%%s", isZero, divisionByZero, division)
+		g.startBlock(divisionByZero)
+		g.emitFailure(infix.Position(), "division by zero")
+		g.startBlock(division)
+	}
+	if infix.Operator == "/" && (!isConstant(right) || right.constant == -1) {
+		g.checkDivisionOverflow(infix.Position(), left, right)
+	}
+	if _, checked := checkedInstructions[instruction]; checked {
+		return g.checkedArithmetic(infix.Position(), instruction, left.ref, right.ref)
+	}
+	result := g.temp()
+	if arithmetic {
+		g.emit("%s = %s i64 %s, %s", result, instruction, left.ref, right.ref)
+		return value{typ: "i64", ref: result}
+	}
+	g.emit("%s = icmp %s %s %s, %s", result, comparison, left.typ, left.ref, right.ref)
+	return value{typ: "i1", ref: result}
+}
+
+// checkedArithmetic fails with integer overflow when the result does not fit in 64 bits, the evaluator switches to big
+// integers there and a wrapped result would be a wrong answer
+func (g *Generator) checkedArithmetic(position lexer.Position, instruction, left, right string) value {
+	intrinsic := fmt.Sprintf("@llvm.%s.with.overflow.i64", checkedInstructions[instruction])
+	g.declares[intrinsic] = fmt.Sprintf("declare { i64, i1 } %s(i64, i64)", intrinsic)
+	pair, result, overflow := g.temp(), g.temp(), g.temp()
+	g.emit("%s = call { i64, i1 } %s(i64 %s, i64 %s)", pair, intrinsic, left, right)
+	g.emit("%s = extractvalue { i64, i1 } %s, 0", result, pair)
+	g.emit("%s = extractvalue { i64, i1 } %s, 1", overflow, pair)
+	g.failWhen(position, overflow, "integer overflow")
+	return value{typ: "i64", ref: result}
+}
+
+// checkDivisionOverflow fails on the smallest integer divided by -1, its quotient does not fit in 64 bits
+func (g *Generator) checkDivisionOverflow(position lexer.Position, left, right value) {
+	isMinimum, isMinusOne, overflow := g.temp(), g.temp(), g.temp()
+	g.emit("%s = icmp eq i64 %s, -9223372036854775808", isMinimum, left.ref)
+	g.emit("%s = icmp eq i64 %s, -1", isMinusOne, right.ref)
+	g.emit("%s = and i1 %s, %s", overflow, isMinimum, isMinusOne)
+	g.failWhen(position, overflow, "integer overflow")
+}
+
+// failWhen branches to a failure with message when condition is true and goes on in a new block otherwise
+func (g *Generator) failWhen(position lexer.Position, condition, message string) {
+	failure, next := g.label("overflow"), g.label("checked")
+	g.branch("br i1 %s, label %%%s, label %%%s", condition, failure, next)
+	g.startBlock(failure)
+	g.emitFailure(position, message)
+	g.startBlock(next)
+}
+
+// emitFailure writes the error with its position to stderr and exits, as the evaluator does for uncaught errors
+func (g *Generator) emitFailure(position lexer.Position, message string) {
+	text := fmt.Sprintf("%s: %s\n", position, message)
+	g.declares["exit"] = "declare void @exit(i32)"
+	g.declares["write"] = "declare i64 @write(i32, ptr, i64)"
+	g.emit("call i64 @write(i32 2, ptr %s, i64 %d)", g.stringConstant(text), len(text))
+	g.emit("call void @exit(i32 1)")
+	g.branch("unreachable")
+}
+
+func (g *Generator) compileIf(ifExpression *ast.IfExpression) value {
+	condition := g.truth(g.use(ifExpression.Condition, g.compileExpression(ifExpression.Condition)))
+	then, merge := g.label("then"), g.label("merge")
+	otherwise := merge
+	if ifExpression.AlternativeBlock != nil {
+		otherwise = g.label("else")
+	}
+	g.branch("br i1 %s, label %%%s, label %%%s", condition.ref, then, otherwise)
+	g.startBlock(then)
+	thenValue := g.compileBlock(ifExpression.ConditionalBlock)
+	thenBlock, thenOpen, thenBranch := g.current.block, !g.current.terminated, len(g.current.body)
+	if thenOpen {
+		g.branch("br label %%%s", merge)
+	}
+	if ifExpression.AlternativeBlock == nil {
+		g.startBlock(merge)
+		return value{reason: "if without else has no value"}
+	}
+	g.startBlock(otherwise)
+	elseValue := g.compileBlock(ifExpression.AlternativeBlock)
+	elseBlock, elseOpen, elseBranch := g.current.block, !g.current.terminated, len(g.current.body)
+	if elseOpen {
+		g.branch("br label %%%s", merge)
+	}
+	if !thenOpen && !elseOpen {
+		return noValue
+	}
+	g.startBlock(merge)
+	switch {
+	case !elseOpen:
+		return thenValue
+	case !thenOpen:
+		return elseValue
+	case thenValue.typ == "":
+		return thenValue
+	case elseValue.typ == "":
+		return elseValue
+	case thenValue.typ == "i1" && elseValue.typ == "i64":
+		thenValue = g.widen(thenValue, thenBranch)
+	case thenValue.typ == "i64" && elseValue.typ == "i1":
+		elseValue = g.widen(elseValue, elseBranch)
+	}
+	result := g.temp()
+	g.emit("%s = phi %s [ %s, %%%s ], [ %s, %%%s ]", result, thenValue.typ, thenValue.ref, thenBlock, elseValue.ref, elseBlock)
+	return value{typ: thenValue.typ, ref: result}
+}
+
+func (g *Generator) compileCall(call *ast.CallFunctionExpression) value {
+	identifier, ok := call.Function.(*ast.Identifier)
+	if !ok {
+		g.unsupported(call.Function, "call of a function value")
+	}
+	found, owner := g.lookup(identifier.Value)
+	if found == nil && identifier.Value == "print" {
+		return g.compilePrint(call)
+	}
+	if found == nil {
+		g.fail(identifier.Position(), fmt.Sprintf("%s was not declare", identifier.Value))
+	}
+	if found.kind != functionBinding {
+		g.fail(identifier.Position(), fmt.Sprintf("%s is not a function", identifier.Value))
+	}
+	if len(call.Arguments) < found.arity {
+		g.unsupported(call, "partial application")
+	}
+	if len(call.Arguments) > found.arity {
+		g.fail(call.Position(), fmt.Sprintf("this function takes at least %d arguments (%d given)", found.arity, len(call.Arguments)))
+	}
+	arguments := make([]string, 0, len(call.Arguments)+1)
+	if found.env != "" {
+		arguments = append(arguments, "ptr "+g.address(identifier.Value, found, owner))
+	}
+	for _, argument := range call.Arguments {
+		arguments = append(arguments, "i64 "+g.word(argument, g.compileExpression(argument)).ref)
+	}
+	result := g.temp()
+	g.emit("%s = call i64 %s(%s)", result, found.ref, strings.Join(arguments, ", "))
+	if found.returns != "i1" {
+		return value{typ: "i64", ref: result}
+	}
+	truncated := g.temp()
+	g.emit("%s = trunc i64 %s to i1", truncated, result)
+	return value{typ: "i1", ref: truncated}
+}
+
+// compilePrint prints the arguments one after the other and a new line like the print builtin
+func (g *Generator) compilePrint(call *ast.CallFunctionExpression) value {
+	format := bytes.NewBufferString("")
+	arguments := make([]string, 0, len(call.Arguments)+1)
+	for _, argument := range call.Arguments {
+		printed := g.use(argument, g.compileExpression(argument))
+		if printed.typ == "i64" {
+			format.WriteString("%lld")
+			arguments = append(arguments, "i64 "+printed.ref)
+			continue
+		}
+		text := g.temp()
+		g.emit("%s = select i1 %s, ptr %s, ptr %s", text, printed.ref, g.stringConstant("true"), g.stringConstant("false"))
+		format.WriteString("%s")
+		arguments = append(arguments, "ptr "+text)
+	}
+	format.WriteString("\n")
+	arguments = append([]string{"ptr " + g.stringConstant(format.String())}, arguments...)
+	g.declares["printf"] = "declare i32 @printf(ptr, ...)"
+	g.emit("call i32 (ptr, ...) @printf(%s)", strings.Join(arguments, ", "))
+	return noValue
+}
+
+func (g *Generator) stringConstant(text string) string {
+	if name, ok := g.strings[text]; ok {
+		return name
+	}
+	name := fmt.Sprintf("@rt.str.%d", len(g.strings))
+	g.strings[text] = name
+	escaped := bytes.NewBufferString("")
+	for _, c := range []byte(text) {
+		if c < ' ' || c > '~' || c == '"' || c == '\\' {
+			escaped.WriteString(fmt.Sprintf("\\%02X", c))
+		} else {
+			escaped.WriteByte(c)
+		}
+	}
+	fmt.Fprintf(g.constants, "%s = private unnamed_addr constant [%d x i8] c\"%s\\00\"\n", name, len(text)+1, escaped)
+	return name
+}
+
+func (g *Generator) use(node ast.Node, result value) value {
+	if result.typ == "" {
+		g.fail(node.Position(), result.reason)
+	}
+	return result
+}
+
+// word widens a value to the i64 used to pass values to functions and return them
+func (g *Generator) word(node ast.Node, result value) value {
+	result = g.use(node, result)
+	if result.typ == "i64" {
+		return result
+	}
+	return g.widen(result, len(g.current.body))
+}
+
+// widen converts a boolean to i64 with the instruction placed at index of the body, constants are converted in place
+func (g *Generator) widen(result value, index int) value {
+	switch result.ref {
+	case "true":
+		return value{typ: "i64", ref: "1", constant: 1}
+	case "false":
+		return value{typ: "i64", ref: "0"}
+	}
+	widened := g.temp()
+	g.insert(index, "%s = zext i1 %s to i64", widened, result.ref)
+	return value{typ: "i64", ref: widened}
+}
+
+// truth follows the evaluator, an integer is true when it is not zero
+func (g *Generator) truth(result value) value {
+	if result.typ == "i1" {
+		return result
+	}
+	condition := g.temp()
+	g.emit("%s = icmp ne i64 %s, 0", condition, result.ref)
+	return value{typ: "i1", ref: condition}
+}
+
+func isConstant(result value) bool {
+	return result.typ == "i64" && !strings.HasPrefix(result.ref, "%") && !strings.HasPrefix(result.ref, "@")
+}
+
+func zeroValue(typ string) string {
+	if typ == "i1" {
+		return "false"
+	}
+	return "0"
+}
+
+func describe(node ast.Node) string {
+	switch node.(type) {
+	case *ast.StringExpression:
+		return "string literal"
+	case *ast.FloatLiteral:
+		return "float literal"
+	case *ast.ListLiteral:
+		return "list literal"
+	case *ast.DictLiteral:
+		return "dict literal"
+	case *ast.IndexExpression:
+		return "index expression"
+	case *ast.SliceExpression:
+		return "slice expression"
+	case *ast.FunctionExpression:
+		return "function value"
+	case *ast.PipeExpression:
+		return "pipe operator"
+	case *ast.TryExpression:
+		return "try expression"
+	case *ast.MatchExpression:
+		return "match expression"
+	case *ast.ImportStatement:
+		return "import"
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", node), "*ast.")
+}
@@ -0,0 +1,79 @@
+package llvm
+
+import (
+	"testing"
+	"rootlang/lexer"
+	"rootlang/parser"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"flag"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the emitted IR")
+
+func emit(input string) (string, error) {
+	l := lexer.NewWithFile(input, "main.rl")
+	programParser := parser.New(l)
+	program := programParser.ParseProgram()
+	return New("main.rl").Emit(program)
+}
+
+func TestGoldenFiles(t *testing.T) {
+	sources, _ := filepath.Glob("testdata/*.rl")
+	if len(sources) == 0 {
+		t.Fatal("no golden files found")
+	}
+	for _, source := range sources {
+		input, err := ioutil.ReadFile(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ir, err := emit(string(input))
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", source, err)
+			continue
+		}
+		golden := strings.TrimSuffix(source, ".rl") + ".ll"
+		if *update {
+			ioutil.WriteFile(golden, []byte(ir), 0644)
+			continue
+		}
+		expected, err := ioutil.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ir != string(expected) {
+			t.Errorf("%s: emitted IR differs from %s\n%s", source, golden, ir)
+		}
+	}
+}
+
+func TestUnsupportedConstruct(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let s = "text";`, "main.rl:1:9: string literal is not supported by the llvm backend"},
+		{"let x = 1;\nlet f = y => [y, x];", "main.rl:2:14: list literal is not supported by the llvm backend"},
+		{`let f = x => x; let g = f;`, "main.rl:1:25: function value is not supported by the llvm backend"},
+		{`let add = (x, y) => x + y; let inc = () => add(1);`, "main.rl:1:44: partial application is not supported by the llvm backend"},
+		{`let f = () => map(x => x, [1]);`, "main.rl:1:15: map was not declare"},
+		{`let f = () => match 1 { _ => 1 };`, "main.rl:1:15: match expression is not supported by the llvm backend"},
+		{`let x = 1 + true;`, "main.rl:1:11: unknow operator for INTEGER + BOOLEAN"},
+		{`let x = if (true) { 1; };`, "main.rl:1:9: if without else has no value"},
+		{`let x = 1; let x = false;`, "main.rl:1:12: x is already declared with another type"},
+		{`let x = 18446744073709551616;`, "main.rl:1:9: integer 18446744073709551616 does not fit in 64 bits"},
+		{`import "lib" as lib;`, "main.rl:1:1: import is not supported by the llvm backend"},
+	}
+	for _, test := range tests {
+		_, err := emit(test.input)
+		if err == nil {
+			t.Errorf("%s: expected error %s", test.input, test.expected)
+			continue
+		}
+		if err.Error() != test.expected {
+			t.Errorf("%s: expected error %s and got %s", test.input, test.expected, err.Error())
+		}
+	}
+}
@@ -0,0 +1,77 @@
+; ModuleID = 'main.rl'
+source_filename = "main.rl"
+
+@rt.str.0 = private unnamed_addr constant [32 x i8] c"main.rl:1:11: integer overflow\0A\00"
+@rl.x = internal global i64 0
+@rt.str.1 = private unnamed_addr constant [31 x i8] c"main.rl:2:9: integer overflow\0A\00"
+@rt.str.2 = private unnamed_addr constant [32 x i8] c"main.rl:2:12: integer overflow\0A\00"
+@rl.y = internal global i64 0
+@rt.str.3 = private unnamed_addr constant [6 x i8] c"%lld\0A\00"
+@rt.str.4 = private unnamed_addr constant [10 x i8] c"%lld%lld\0A\00"
+@rt.str.5 = private unnamed_addr constant [5 x i8] c"true\00"
+@rt.str.6 = private unnamed_addr constant [6 x i8] c"false\00"
+@rt.str.7 = private unnamed_addr constant [6 x i8] c"%s%s\0A\00"
+
+define internal void @rt.init() {
+entry:
+  %t.1 = call { i64, i1 } @llvm.smul.with.overflow.i64(i64 6, i64 7)
+  %t.2 = extractvalue { i64, i1 } %t.1, 0
+  %t.3 = extractvalue { i64, i1 } %t.1, 1
+  br i1 %t.3, label %overflow.1, label %checked.2
+overflow.1:
+  call i64 @write(i32 2, ptr @rt.str.0, i64 31)
+  call void @exit(i32 1)
+  unreachable
+checked.2:
+  store i64 %t.2, ptr @rl.x
+  %t.4 = load i64, ptr @rl.x
+  %t.6 = call { i64, i1 } @llvm.ssub.with.overflow.i64(i64 0, i64 %t.4)
+  %t.7 = extractvalue { i64, i1 } %t.6, 0
+  %t.8 = extractvalue { i64, i1 } %t.6, 1
+  br i1 %t.8, label %overflow.3, label %checked.4
+overflow.3:
+  call i64 @write(i32 2, ptr @rt.str.1, i64 30)
+  call void @exit(i32 1)
+  unreachable
+checked.4:
+  %t.9 = srem i64 100, 7
+  %t.10 = call { i64, i1 } @llvm.sadd.with.overflow.i64(i64 %t.7, i64 %t.9)
+  %t.11 = extractvalue { i64, i1 } %t.10, 0
+  %t.12 = extractvalue { i64, i1 } %t.10, 1
+  br i1 %t.12, label %overflow.5, label %checked.6
+overflow.5:
+  call i64 @write(i32 2, ptr @rt.str.2, i64 31)
+  call void @exit(i32 1)
+  unreachable
+checked.6:
+  store i64 %t.11, ptr @rl.y
+  %t.13 = load i64, ptr @rl.x
+  call i32 (ptr, ...) @printf(ptr @rt.str.3, i64 %t.13)
+  %t.14 = load i64, ptr @rl.x
+  %t.15 = sdiv i64 %t.14, 2
+  %t.16 = load i64, ptr @rl.y
+  call i32 (ptr, ...) @printf(ptr @rt.str.4, i64 %t.15, i64 %t.16)
+  %t.17 = load i64, ptr @rl.x
+  %t.18 = load i64, ptr @rl.y
+  %t.19 = icmp sgt i64 %t.17, %t.18
+  %t.20 = select i1 %t.19, ptr @rt.str.5, ptr @rt.str.6
+  %t.21 = load i64, ptr @rl.x
+  %t.22 = icmp eq i64 %t.21, 42
+  %t.23 = xor i1 %t.22, true
+  %t.24 = select i1 %t.23, ptr @rt.str.5, ptr @rt.str.6
+  call i32 (ptr, ...) @printf(ptr @rt.str.7, ptr %t.20, ptr %t.24)
+  ret void
+}
+
+define i32 @main() {
+entry:
+  call void @rt.init()
+  ret i32 0
+}
+
+declare { i64, i1 } @llvm.sadd.with.overflow.i64(i64, i64)
+declare { i64, i1 } @llvm.smul.with.overflow.i64(i64, i64)
+declare { i64, i1 } @llvm.ssub.with.overflow.i64(i64, i64)
+declare void @exit(i32)
+declare i32 @printf(ptr, ...)
+declare i64 @write(i32, ptr, i64)
@@ -0,0 +1,5 @@
+let x = 6 * 7;
+let y = -x + 100 % 7;
+print(x);
+print(x / 2, y);
+print(x > y, !(x == 42));
@@ -0,0 +1,80 @@
+; ModuleID = 'main.rl'
+source_filename = "main.rl"
+
+@rt.str.0 = private unnamed_addr constant [5 x i8] c"true\00"
+@rt.str.1 = private unnamed_addr constant [6 x i8] c"false\00"
+@rt.str.2 = private unnamed_addr constant [12 x i8] c"%s%s%s%s%s\0A\00"
+
+define i64 @rl.positive(i64 %n) {
+entry:
+  %n.addr = alloca i64
+  store i64 %n, ptr %n.addr
+  %t.1 = load i64, ptr %n.addr
+  %t.2 = icmp slt i64 %t.1, 0
+  br i1 %t.2, label %then.1, label %merge.2
+then.1:
+  ret i64 0
+merge.2:
+  %t.3 = load i64, ptr %n.addr
+  %t.4 = icmp sgt i64 %t.3, 0
+  %t.5 = zext i1 %t.4 to i64
+  ret i64 %t.5
+}
+
+define internal i64 @rl.main.both(ptr %env.ptr, i64 %a, i64 %b) {
+entry:
+  %a.addr = alloca i64
+  store i64 %a, ptr %a.addr
+  %b.addr = alloca i64
+  store i64 %b, ptr %b.addr
+  %t.1 = load i64, ptr %a.addr
+  %t.2 = call i64 @rl.positive(i64 %t.1)
+  %t.3 = trunc i64 %t.2 to i1
+  br i1 %t.3, label %then.1, label %else.3
+then.1:
+  %t.4 = load i64, ptr %b.addr
+  %t.5 = call i64 @rl.positive(i64 %t.4)
+  %t.6 = trunc i64 %t.5 to i1
+  br label %merge.2
+else.3:
+  br label %merge.2
+merge.2:
+  %t.7 = phi i1 [ %t.6, %then.1 ], [ false, %else.3 ]
+  %t.8 = zext i1 %t.7 to i64
+  ret i64 %t.8
+}
+
+define i64 @rl.main() {
+entry:
+  %t.1 = call i64 @rl.positive(i64 5)
+  %t.2 = trunc i64 %t.1 to i1
+  %t.3 = select i1 %t.2, ptr @rt.str.0, ptr @rt.str.1
+  %t.5 = call i64 @rl.positive(i64 -1)
+  %t.6 = trunc i64 %t.5 to i1
+  %t.7 = select i1 %t.6, ptr @rt.str.0, ptr @rt.str.1
+  %t.8 = call i64 @rl.positive(i64 0)
+  %t.9 = trunc i64 %t.8 to i1
+  %t.10 = select i1 %t.9, ptr @rt.str.0, ptr @rt.str.1
+  %t.11 = call i64 @rl.main.both(ptr null, i64 1, i64 2)
+  %t.12 = trunc i64 %t.11 to i1
+  %t.13 = select i1 %t.12, ptr @rt.str.0, ptr @rt.str.1
+  %t.15 = call i64 @rl.main.both(ptr null, i64 1, i64 -2)
+  %t.16 = trunc i64 %t.15 to i1
+  %t.17 = select i1 %t.16, ptr @rt.str.0, ptr @rt.str.1
+  call i32 (ptr, ...) @printf(ptr @rt.str.2, ptr %t.3, ptr %t.7, ptr %t.10, ptr %t.13, ptr %t.17)
+  ret i64 0
+}
+
+define internal void @rt.init() {
+entry:
+  ret void
+}
+
+define i32 @main() {
+entry:
+  call void @rt.init()
+  call i64 @rl.main()
+  ret i32 0
+}
+
+declare i32 @printf(ptr, ...)
@@ -0,0 +1,10 @@
+let positive = n => {
+  if (n < 0) {
+    return false;
+  }
+  n > 0;
+};
+let main = () => {
+  let both = (a, b) => if (positive(a)) { positive(b); } else { false; };
+  print(positive(5), positive(-1), positive(0), both(1, 2), both(1, -2));
+};
@@ -0,0 +1,137 @@
+; ModuleID = 'main.rl'
+source_filename = "main.rl"
+
+%rl.main.add.env = type { ptr }
+%rl.main.twice.env = type { ptr }
+%rl.main.count.env = type { ptr }
+
+@rt.str.0 = private unnamed_addr constant [32 x i8] c"main.rl:3:20: integer overflow\0A\00"
+@rt.str.1 = private unnamed_addr constant [32 x i8] c"main.rl:5:57: integer overflow\0A\00"
+@rt.str.2 = private unnamed_addr constant [32 x i8] c"main.rl:6:23: integer overflow\0A\00"
+@rt.str.3 = private unnamed_addr constant [18 x i8] c"%lld%lld%lld%lld\0A\00"
+@rt.str.4 = private unnamed_addr constant [6 x i8] c"%lld\0A\00"
+
+define internal i64 @rl.main.add(ptr %env.ptr, i64 %x) {
+entry:
+  %x.addr = alloca i64
+  store i64 %x, ptr %x.addr
+  %t.1 = load i64, ptr %x.addr
+  %t.2 = getelementptr %rl.main.add.env, ptr %env.ptr, i32 0, i32 0
+  %t.3 = load ptr, ptr %t.2
+  %t.4 = load i64, ptr %t.3
+  %t.5 = call { i64, i1 } @llvm.sadd.with.overflow.i64(i64 %t.1, i64 %t.4)
+  %t.6 = extractvalue { i64, i1 } %t.5, 0
+  %t.7 = extractvalue { i64, i1 } %t.5, 1
+  br i1 %t.7, label %overflow.1, label %checked.2
+overflow.1:
+  call i64 @write(i32 2, ptr @rt.str.0, i64 31)
+  call void @exit(i32 1)
+  unreachable
+checked.2:
+  ret i64 %t.6
+}
+
+define internal i64 @rl.main.twice(ptr %env.ptr, i64 %x) {
+entry:
+  %x.addr = alloca i64
+  store i64 %x, ptr %x.addr
+  %t.1 = getelementptr %rl.main.twice.env, ptr %env.ptr, i32 0, i32 0
+  %t.2 = load ptr, ptr %t.1
+  %t.3 = getelementptr %rl.main.twice.env, ptr %env.ptr, i32 0, i32 0
+  %t.4 = load ptr, ptr %t.3
+  %t.5 = load i64, ptr %x.addr
+  %t.6 = call i64 @rl.main.add(ptr %t.4, i64 %t.5)
+  %t.7 = call i64 @rl.main.add(ptr %t.2, i64 %t.6)
+  ret i64 %t.7
+}
+
+define internal i64 @rl.main.count(ptr %env.ptr, i64 %n) {
+entry:
+  %n.addr = alloca i64
+  store i64 %n, ptr %n.addr
+  %t.1 = load i64, ptr %n.addr
+  %t.2 = icmp eq i64 %t.1, 0
+  br i1 %t.2, label %then.1, label %else.3
+then.1:
+  %t.3 = getelementptr %rl.main.count.env, ptr %env.ptr, i32 0, i32 0
+  %t.4 = load ptr, ptr %t.3
+  %t.5 = load i64, ptr %t.4
+  br label %merge.2
+else.3:
+  %t.6 = load i64, ptr %n.addr
+  %t.7 = call { i64, i1 } @llvm.ssub.with.overflow.i64(i64 %t.6, i64 1)
+  %t.8 = extractvalue { i64, i1 } %t.7, 0
+  %t.9 = extractvalue { i64, i1 } %t.7, 1
+  br i1 %t.9, label %overflow.4, label %checked.5
+overflow.4:
+  call i64 @write(i32 2, ptr @rt.str.1, i64 31)
+  call void @exit(i32 1)
+  unreachable
+checked.5:
+  %t.10 = call i64 @rl.main.count(ptr %env.ptr, i64 %t.8)
+  br label %merge.2
+merge.2:
+  %t.11 = phi i64 [ %t.5, %then.1 ], [ %t.10, %checked.5 ]
+  ret i64 %t.11
+}
+
+define internal i64 @rl.main.square(ptr %env.ptr, i64 %x) {
+entry:
+  %x.addr = alloca i64
+  store i64 %x, ptr %x.addr
+  %t.1 = load i64, ptr %x.addr
+  %t.2 = load i64, ptr %x.addr
+  %t.3 = call { i64, i1 } @llvm.smul.with.overflow.i64(i64 %t.1, i64 %t.2)
+  %t.4 = extractvalue { i64, i1 } %t.3, 0
+  %t.5 = extractvalue { i64, i1 } %t.3, 1
+  br i1 %t.5, label %overflow.1, label %checked.2
+overflow.1:
+  call i64 @write(i32 2, ptr @rt.str.2, i64 31)
+  call void @exit(i32 1)
+  unreachable
+checked.2:
+  ret i64 %t.4
+}
+
+define i64 @rl.main() {
+entry:
+  %base.addr = alloca i64
+  %add.env = alloca %rl.main.add.env
+  %twice.env = alloca %rl.main.twice.env
+  %count.env = alloca %rl.main.count.env
+  store i64 10, ptr %base.addr
+  %t.1 = getelementptr %rl.main.add.env, ptr %add.env, i32 0, i32 0
+  store ptr %base.addr, ptr %t.1
+  %t.2 = getelementptr %rl.main.twice.env, ptr %twice.env, i32 0, i32 0
+  store ptr %add.env, ptr %t.2
+  %t.3 = getelementptr %rl.main.count.env, ptr %count.env, i32 0, i32 0
+  store ptr %base.addr, ptr %t.3
+  %t.4 = call i64 @rl.main.add(ptr %add.env, i64 1)
+  %t.5 = call i64 @rl.main.twice(ptr %twice.env, i64 1)
+  %t.6 = call i64 @rl.main.count(ptr %count.env, i64 3)
+  %t.7 = call i64 @rl.main.square(ptr null, i64 4)
+  call i32 (ptr, ...) @printf(ptr @rt.str.3, i64 %t.4, i64 %t.5, i64 %t.6, i64 %t.7)
+  store i64 20, ptr %base.addr
+  %t.8 = call i64 @rl.main.add(ptr %add.env, i64 1)
+  call i32 (ptr, ...) @printf(ptr @rt.str.4, i64 %t.8)
+  ret i64 0
+}
+
+define internal void @rt.init() {
+entry:
+  ret void
+}
+
+define i32 @main() {
+entry:
+  call void @rt.init()
+  call i64 @rl.main()
+  ret i32 0
+}
+
+declare { i64, i1 } @llvm.sadd.with.overflow.i64(i64, i64)
+declare { i64, i1 } @llvm.smul.with.overflow.i64(i64, i64)
+declare { i64, i1 } @llvm.ssub.with.overflow.i64(i64, i64)
+declare void @exit(i32)
+declare i32 @printf(ptr, ...)
+declare i64 @write(i32, ptr, i64)
@@ -0,0 +1,10 @@
+let main = () => {
+  let base = 10;
+  let add = x => x + base;
+  let twice = x => add(add(x));
+  let count = n => if (n == 0) { base; } else { count(n - 1); };
+  let square = x => x * x;
+  print(add(1), twice(1), count(3), square(4));
+  let base = 20;
+  print(add(1));
+};
@@ -0,0 +1,142 @@
+; ModuleID = 'main.rl'
+source_filename = "main.rl"
+
+@rl.limit = internal global i64 0
+@rt.str.0 = private unnamed_addr constant [32 x i8] c"main.rl:1:53: integer overflow\0A\00"
+@rt.str.1 = private unnamed_addr constant [32 x i8] c"main.rl:1:44: integer overflow\0A\00"
+@rt.str.2 = private unnamed_addr constant [32 x i8] c"main.rl:2:58: integer overflow\0A\00"
+@rt.str.3 = private unnamed_addr constant [32 x i8] c"main.rl:3:59: integer overflow\0A\00"
+@rt.str.4 = private unnamed_addr constant [6 x i8] c"%lld\0A\00"
+@rt.str.5 = private unnamed_addr constant [5 x i8] c"true\00"
+@rt.str.6 = private unnamed_addr constant [6 x i8] c"false\00"
+@rt.str.7 = private unnamed_addr constant [6 x i8] c"%s%s\0A\00"
+
+define i64 @rl.fact(i64 %n) {
+entry:
+  %n.addr = alloca i64
+  store i64 %n, ptr %n.addr
+  %t.1 = load i64, ptr %n.addr
+  %t.2 = icmp slt i64 %t.1, 2
+  br i1 %t.2, label %then.1, label %else.3
+then.1:
+  br label %merge.2
+else.3:
+  %t.3 = load i64, ptr %n.addr
+  %t.4 = load i64, ptr %n.addr
+  %t.5 = call { i64, i1 } @llvm.ssub.with.overflow.i64(i64 %t.4, i64 1)
+  %t.6 = extractvalue { i64, i1 } %t.5, 0
+  %t.7 = extractvalue { i64, i1 } %t.5, 1
+  br i1 %t.7, label %overflow.4, label %checked.5
+overflow.4:
+  call i64 @write(i32 2, ptr @rt.str.0, i64 31)
+  call void @exit(i32 1)
+  unreachable
+checked.5:
+  %t.8 = call i64 @rl.fact(i64 %t.6)
+  %t.9 = call { i64, i1 } @llvm.smul.with.overflow.i64(i64 %t.3, i64 %t.8)
+  %t.10 = extractvalue { i64, i1 } %t.9, 0
+  %t.11 = extractvalue { i64, i1 } %t.9, 1
+  br i1 %t.11, label %overflow.6, label %checked.7
+overflow.6:
+  call i64 @write(i32 2, ptr @rt.str.1, i64 31)
+  call void @exit(i32 1)
+  unreachable
+checked.7:
+  br label %merge.2
+merge.2:
+  %t.12 = phi i64 [ 1, %then.1 ], [ %t.10, %checked.7 ]
+  ret i64 %t.12
+}
+
+define i64 @rl.is_even(i64 %n) {
+entry:
+  %n.addr = alloca i64
+  store i64 %n, ptr %n.addr
+  %t.1 = load i64, ptr %n.addr
+  %t.2 = icmp eq i64 %t.1, 0
+  br i1 %t.2, label %then.1, label %else.3
+then.1:
+  br label %merge.2
+else.3:
+  %t.3 = load i64, ptr %n.addr
+  %t.4 = call { i64, i1 } @llvm.ssub.with.overflow.i64(i64 %t.3, i64 1)
+  %t.5 = extractvalue { i64, i1 } %t.4, 0
+  %t.6 = extractvalue { i64, i1 } %t.4, 1
+  br i1 %t.6, label %overflow.4, label %checked.5
+overflow.4:
+  call i64 @write(i32 2, ptr @rt.str.2, i64 31)
+  call void @exit(i32 1)
+  unreachable
+checked.5:
+  %t.7 = call i64 @rl.is_odd(i64 %t.5)
+  %t.8 = trunc i64 %t.7 to i1
+  br label %merge.2
+merge.2:
+  %t.9 = phi i1 [ true, %then.1 ], [ %t.8, %checked.5 ]
+  %t.10 = zext i1 %t.9 to i64
+  ret i64 %t.10
+}
+
+define i64 @rl.is_odd(i64 %n) {
+entry:
+  %n.addr = alloca i64
+  store i64 %n, ptr %n.addr
+  %t.1 = load i64, ptr %n.addr
+  %t.2 = icmp eq i64 %t.1, 0
+  br i1 %t.2, label %then.1, label %else.3
+then.1:
+  br label %merge.2
+else.3:
+  %t.3 = load i64, ptr %n.addr
+  %t.4 = call { i64, i1 } @llvm.ssub.with.overflow.i64(i64 %t.3, i64 1)
+  %t.5 = extractvalue { i64, i1 } %t.4, 0
+  %t.6 = extractvalue { i64, i1 } %t.4, 1
+  br i1 %t.6, label %overflow.4, label %checked.5
+overflow.4:
+  call i64 @write(i32 2, ptr @rt.str.3, i64 31)
+  call void @exit(i32 1)
+  unreachable
+checked.5:
+  %t.7 = call i64 @rl.is_even(i64 %t.5)
+  %t.8 = trunc i64 %t.7 to i1
+  br label %merge.2
+merge.2:
+  %t.9 = phi i1 [ false, %then.1 ], [ %t.8, %checked.5 ]
+  %t.10 = zext i1 %t.9 to i64
+  ret i64 %t.10
+}
+
+define i64 @rl.main() {
+entry:
+  %t.1 = load i64, ptr @rl.limit
+  %t.2 = call i64 @rl.fact(i64 %t.1)
+  call i32 (ptr, ...) @printf(ptr @rt.str.4, i64 %t.2)
+  %t.3 = load i64, ptr @rl.limit
+  %t.4 = call i64 @rl.is_even(i64 %t.3)
+  %t.5 = trunc i64 %t.4 to i1
+  %t.6 = select i1 %t.5, ptr @rt.str.5, ptr @rt.str.6
+  %t.7 = call i64 @rl.is_odd(i64 7)
+  %t.8 = trunc i64 %t.7 to i1
+  %t.9 = select i1 %t.8, ptr @rt.str.5, ptr @rt.str.6
+  call i32 (ptr, ...) @printf(ptr @rt.str.7, ptr %t.6, ptr %t.9)
+  ret i64 0
+}
+
+define internal void @rt.init() {
+entry:
+  store i64 10, ptr @rl.limit
+  ret void
+}
+
+define i32 @main() {
+entry:
+  call void @rt.init()
+  call i64 @rl.main()
+  ret i32 0
+}
+
+declare { i64, i1 } @llvm.smul.with.overflow.i64(i64, i64)
+declare { i64, i1 } @llvm.ssub.with.overflow.i64(i64, i64)
+declare void @exit(i32)
+declare i32 @printf(ptr, ...)
+declare i64 @write(i32, ptr, i64)
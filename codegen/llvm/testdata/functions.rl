@@ -0,0 +1,8 @@
+let fact = n => if (n < 2) { 1; } else { n * fact(n - 1); };
+let is_even = n => if (n == 0) { true; } else { is_odd(n - 1); };
+let is_odd = n => if (n == 0) { false; } else { is_even(n - 1); };
+let limit = 10;
+let main = () => {
+  print(fact(limit));
+  print(is_even(limit), is_odd(7));
+};
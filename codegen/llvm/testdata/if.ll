@@ -0,0 +1,102 @@
+; ModuleID = 'main.rl'
+source_filename = "main.rl"
+
+@rt.str.0 = private unnamed_addr constant [32 x i8] c"main.rl:1:29: integer overflow\0A\00"
+@rt.str.1 = private unnamed_addr constant [10 x i8] c"%lld%lld\0A\00"
+@rt.str.2 = private unnamed_addr constant [14 x i8] c"%lld%lld%lld\0A\00"
+@rt.str.3 = private unnamed_addr constant [5 x i8] c"true\00"
+@rt.str.4 = private unnamed_addr constant [6 x i8] c"false\00"
+@rt.str.5 = private unnamed_addr constant [4 x i8] c"%s\0A\00"
+
+define i64 @rl.abs(i64 %n) {
+entry:
+  %n.addr = alloca i64
+  store i64 %n, ptr %n.addr
+  %t.1 = load i64, ptr %n.addr
+  %t.2 = icmp slt i64 %t.1, 0
+  br i1 %t.2, label %then.1, label %else.3
+then.1:
+  %t.3 = load i64, ptr %n.addr
+  %t.5 = call { i64, i1 } @llvm.ssub.with.overflow.i64(i64 0, i64 %t.3)
+  %t.6 = extractvalue { i64, i1 } %t.5, 0
+  %t.7 = extractvalue { i64, i1 } %t.5, 1
+  br i1 %t.7, label %overflow.4, label %checked.5
+overflow.4:
+  call i64 @write(i32 2, ptr @rt.str.0, i64 31)
+  call void @exit(i32 1)
+  unreachable
+checked.5:
+  br label %merge.2
+else.3:
+  %t.8 = load i64, ptr %n.addr
+  br label %merge.2
+merge.2:
+  %t.9 = phi i64 [ %t.6, %checked.5 ], [ %t.8, %else.3 ]
+  ret i64 %t.9
+}
+
+define i64 @rl.sign(i64 %n) {
+entry:
+  %n.addr = alloca i64
+  store i64 %n, ptr %n.addr
+  %s.addr = alloca i64
+  %t.1 = load i64, ptr %n.addr
+  %t.2 = icmp eq i64 %t.1, 0
+  br i1 %t.2, label %then.1, label %merge.2
+then.1:
+  ret i64 0
+merge.2:
+  %t.3 = load i64, ptr %n.addr
+  %t.4 = icmp sgt i64 %t.3, 0
+  br i1 %t.4, label %then.3, label %else.5
+then.3:
+  br label %merge.4
+else.5:
+  br label %merge.4
+merge.4:
+  %t.6 = phi i64 [ 1, %then.3 ], [ -1, %else.5 ]
+  store i64 %t.6, ptr %s.addr
+  %t.7 = load i64, ptr %s.addr
+  ret i64 %t.7
+}
+
+define i64 @rl.main() {
+entry:
+  %big.addr = alloca i1
+  %t.2 = call i64 @rl.abs(i64 -5)
+  %t.3 = call i64 @rl.abs(i64 3)
+  call i32 (ptr, ...) @printf(ptr @rt.str.1, i64 %t.2, i64 %t.3)
+  %t.5 = call i64 @rl.sign(i64 -9)
+  %t.6 = call i64 @rl.sign(i64 0)
+  %t.7 = call i64 @rl.sign(i64 4)
+  call i32 (ptr, ...) @printf(ptr @rt.str.2, i64 %t.5, i64 %t.6, i64 %t.7)
+  %t.9 = call i64 @rl.abs(i64 -20)
+  %t.10 = icmp sgt i64 %t.9, 10
+  store i1 %t.10, ptr %big.addr
+  %t.11 = load i1, ptr %big.addr
+  br i1 %t.11, label %then.1, label %merge.2
+then.1:
+  %t.12 = load i1, ptr %big.addr
+  %t.13 = select i1 %t.12, ptr @rt.str.3, ptr @rt.str.4
+  call i32 (ptr, ...) @printf(ptr @rt.str.5, ptr %t.13)
+  br label %merge.2
+merge.2:
+  ret i64 0
+}
+
+define internal void @rt.init() {
+entry:
+  ret void
+}
+
+define i32 @main() {
+entry:
+  call void @rt.init()
+  call i64 @rl.main()
+  ret i32 0
+}
+
+declare { i64, i1 } @llvm.ssub.with.overflow.i64(i64, i64)
+declare void @exit(i32)
+declare i32 @printf(ptr, ...)
+declare i64 @write(i32, ptr, i64)
@@ -0,0 +1,14 @@
+let abs = n => if (n < 0) { -n; } else { n; };
+let sign = n => {
+  if (n == 0) {
+    return 0;
+  }
+  let s = if (n > 0) { 1; } else { -1; };
+  return s;
+};
+let main = () => {
+  print(abs(-5), abs(3));
+  print(sign(-9), sign(0), sign(4));
+  let big = abs(-20) > 10;
+  if (big) { print(big); };
+};
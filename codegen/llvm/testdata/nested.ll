@@ -0,0 +1,94 @@
+; ModuleID = 'main.rl'
+source_filename = "main.rl"
+
+%rl.main.walk.inner.env = type { ptr }
+%rl.main.walk.env = type { ptr }
+
+@rt.str.0 = private unnamed_addr constant [32 x i8] c"main.rl:4:64: integer overflow\0A\00"
+@rt.str.1 = private unnamed_addr constant [5 x i8] c"true\00"
+@rt.str.2 = private unnamed_addr constant [6 x i8] c"false\00"
+@rt.str.3 = private unnamed_addr constant [6 x i8] c"%s%s\0A\00"
+
+define internal i64 @rl.main.walk.inner(ptr %env.ptr, i64 %m) {
+entry:
+  %m.addr = alloca i64
+  store i64 %m, ptr %m.addr
+  %t.1 = load i64, ptr %m.addr
+  %t.2 = icmp sgt i64 %t.1, 100
+  br i1 %t.2, label %then.1, label %else.3
+then.1:
+  %t.3 = load i64, ptr %m.addr
+  %t.4 = icmp sgt i64 %t.3, 1000
+  br label %merge.2
+else.3:
+  %t.5 = load i64, ptr %m.addr
+  %t.6 = getelementptr %rl.main.walk.inner.env, ptr %env.ptr, i32 0, i32 0
+  %t.7 = load ptr, ptr %t.6
+  %t.8 = load i64, ptr %t.7
+  %t.9 = call { i64, i1 } @llvm.sadd.with.overflow.i64(i64 %t.5, i64 %t.8)
+  %t.10 = extractvalue { i64, i1 } %t.9, 0
+  %t.11 = extractvalue { i64, i1 } %t.9, 1
+  br i1 %t.11, label %overflow.4, label %checked.5
+overflow.4:
+  call i64 @write(i32 2, ptr @rt.str.0, i64 31)
+  call void @exit(i32 1)
+  unreachable
+checked.5:
+  %t.12 = call i64 @rl.main.walk.inner(ptr %env.ptr, i64 %t.10)
+  %t.13 = trunc i64 %t.12 to i1
+  br label %merge.2
+merge.2:
+  %t.14 = phi i1 [ %t.4, %then.1 ], [ %t.13, %checked.5 ]
+  %t.15 = zext i1 %t.14 to i64
+  ret i64 %t.15
+}
+
+define internal i64 @rl.main.walk(ptr %env.ptr, i64 %n) {
+entry:
+  %n.addr = alloca i64
+  store i64 %n, ptr %n.addr
+  %inner.env = alloca %rl.main.walk.inner.env
+  %t.1 = getelementptr %rl.main.walk.env, ptr %env.ptr, i32 0, i32 0
+  %t.2 = load ptr, ptr %t.1
+  %t.3 = getelementptr %rl.main.walk.inner.env, ptr %inner.env, i32 0, i32 0
+  store ptr %t.2, ptr %t.3
+  %t.4 = load i64, ptr %n.addr
+  %t.5 = call i64 @rl.main.walk.inner(ptr %inner.env, i64 %t.4)
+  %t.6 = trunc i64 %t.5 to i1
+  %t.7 = zext i1 %t.6 to i64
+  ret i64 %t.7
+}
+
+define i64 @rl.main() {
+entry:
+  %step.addr = alloca i64
+  %walk.env = alloca %rl.main.walk.env
+  store i64 3, ptr %step.addr
+  %t.1 = getelementptr %rl.main.walk.env, ptr %walk.env, i32 0, i32 0
+  store ptr %step.addr, ptr %t.1
+  %t.2 = call i64 @rl.main.walk(ptr %walk.env, i64 1)
+  %t.3 = trunc i64 %t.2 to i1
+  %t.4 = select i1 %t.3, ptr @rt.str.1, ptr @rt.str.2
+  %t.5 = call i64 @rl.main.walk(ptr %walk.env, i64 2000)
+  %t.6 = trunc i64 %t.5 to i1
+  %t.7 = select i1 %t.6, ptr @rt.str.1, ptr @rt.str.2
+  call i32 (ptr, ...) @printf(ptr @rt.str.3, ptr %t.4, ptr %t.7)
+  ret i64 0
+}
+
+define internal void @rt.init() {
+entry:
+  ret void
+}
+
+define i32 @main() {
+entry:
+  call void @rt.init()
+  call i64 @rl.main()
+  ret i32 0
+}
+
+declare { i64, i1 } @llvm.sadd.with.overflow.i64(i64, i64)
+declare void @exit(i32)
+declare i32 @printf(ptr, ...)
+declare i64 @write(i32, ptr, i64)
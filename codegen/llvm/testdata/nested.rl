@@ -0,0 +1,8 @@
+let main = () => {
+  let step = 3;
+  let walk = n => {
+    let inner = m => if (m > 100) { m > 1000; } else { inner(m + step); };
+    inner(n);
+  };
+  print(walk(1), walk(2000));
+};
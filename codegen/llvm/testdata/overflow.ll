@@ -0,0 +1,74 @@
+; ModuleID = 'main.rl'
+source_filename = "main.rl"
+
+@rt.str.0 = private unnamed_addr constant [32 x i8] c"main.rl:1:58: integer overflow\0A\00"
+@rt.str.1 = private unnamed_addr constant [32 x i8] c"main.rl:1:67: integer overflow\0A\00"
+@rt.str.2 = private unnamed_addr constant [6 x i8] c"%lld\0A\00"
+
+define i64 @rl.fact(i64 %n, i64 %acc) {
+entry:
+  %n.addr = alloca i64
+  store i64 %n, ptr %n.addr
+  %acc.addr = alloca i64
+  store i64 %acc, ptr %acc.addr
+  %t.1 = load i64, ptr %n.addr
+  %t.2 = icmp slt i64 %t.1, 2
+  br i1 %t.2, label %then.1, label %else.3
+then.1:
+  %t.3 = load i64, ptr %acc.addr
+  br label %merge.2
+else.3:
+  %t.4 = load i64, ptr %n.addr
+  %t.5 = call { i64, i1 } @llvm.ssub.with.overflow.i64(i64 %t.4, i64 1)
+  %t.6 = extractvalue { i64, i1 } %t.5, 0
+  %t.7 = extractvalue { i64, i1 } %t.5, 1
+  br i1 %t.7, label %overflow.4, label %checked.5
+overflow.4:
+  call i64 @write(i32 2, ptr @rt.str.0, i64 31)
+  call void @exit(i32 1)
+  unreachable
+checked.5:
+  %t.8 = load i64, ptr %acc.addr
+  %t.9 = load i64, ptr %n.addr
+  %t.10 = call { i64, i1 } @llvm.smul.with.overflow.i64(i64 %t.8, i64 %t.9)
+  %t.11 = extractvalue { i64, i1 } %t.10, 0
+  %t.12 = extractvalue { i64, i1 } %t.10, 1
+  br i1 %t.12, label %overflow.6, label %checked.7
+overflow.6:
+  call i64 @write(i32 2, ptr @rt.str.1, i64 31)
+  call void @exit(i32 1)
+  unreachable
+checked.7:
+  %t.13 = call i64 @rl.fact(i64 %t.6, i64 %t.11)
+  br label %merge.2
+merge.2:
+  %t.14 = phi i64 [ %t.3, %then.1 ], [ %t.13, %checked.7 ]
+  ret i64 %t.14
+}
+
+define i64 @rl.main() {
+entry:
+  %t.1 = call i64 @rl.fact(i64 20, i64 1)
+  call i32 (ptr, ...) @printf(ptr @rt.str.2, i64 %t.1)
+  %t.2 = call i64 @rl.fact(i64 25, i64 1)
+  call i32 (ptr, ...) @printf(ptr @rt.str.2, i64 %t.2)
+  ret i64 0
+}
+
+define internal void @rt.init() {
+entry:
+  ret void
+}
+
+define i32 @main() {
+entry:
+  call void @rt.init()
+  call i64 @rl.main()
+  ret i32 0
+}
+
+declare { i64, i1 } @llvm.smul.with.overflow.i64(i64, i64)
+declare { i64, i1 } @llvm.ssub.with.overflow.i64(i64, i64)
+declare void @exit(i32)
+declare i32 @printf(ptr, ...)
+declare i64 @write(i32, ptr, i64)
@@ -0,0 +1,5 @@
+let fact = (n, acc) => if (n < 2) { acc; } else { fact(n - 1, acc * n); };
+let main = () => {
+  print(fact(20, 1));
+  print(fact(25, 1));
+};
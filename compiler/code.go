@@ -0,0 +1,179 @@
+package compiler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+type Instructions []byte
+
+type Opcode byte
+
+const (
+	OpConstant Opcode = iota
+	OpNil
+	OpTrue
+	OpFalse
+	OpPop
+	OpAdd
+	OpSub
+	OpMul
+	OpDiv
+	OpMod
+	OpEqual
+	OpNotEqual
+	OpLessThan
+	OpGreaterThan
+	OpCompose
+	OpMinus
+	OpNot
+	OpJump
+	OpJumpNotTruthy
+	OpGetGlobal
+	OpSetGlobal
+	OpGetLocal
+	OpSetLocal
+	OpClosure
+	OpCall
+	OpTailCall
+	OpPipeCall
+	OpReturn
+	OpList
+	OpDict
+	OpIndex
+	OpSlice
+	OpModuleGet
+	OpModuleCall
+	OpImport
+	OpTry
+	OpEndTry
+	OpMatch
+	OpMatchFail
+)
+
+type Definition struct {
+	Name          string
+	OperandWidths []int
+}
+
+var definitions = map[Opcode]*Definition{
+	OpConstant:      {"OpConstant", []int{2}},
+	OpNil:           {"OpNil", []int{}},
+	OpTrue:          {"OpTrue", []int{}},
+	OpFalse:         {"OpFalse", []int{}},
+	OpPop:           {"OpPop", []int{}},
+	OpAdd:           {"OpAdd", []int{}},
+	OpSub:           {"OpSub", []int{}},
+	OpMul:           {"OpMul", []int{}},
+	OpDiv:           {"OpDiv", []int{}},
+	OpMod:           {"OpMod", []int{}},
+	OpEqual:         {"OpEqual", []int{}},
+	OpNotEqual:      {"OpNotEqual", []int{}},
+	OpLessThan:      {"OpLessThan", []int{}},
+	OpGreaterThan:   {"OpGreaterThan", []int{}},
+	OpCompose:       {"OpCompose", []int{}},
+	OpMinus:         {"OpMinus", []int{}},
+	OpNot:           {"OpNot", []int{}},
+	OpJump:          {"OpJump", []int{2}},
+	OpJumpNotTruthy: {"OpJumpNotTruthy", []int{2}},
+	OpGetGlobal:     {"OpGetGlobal", []int{2}},
+	OpSetGlobal:     {"OpSetGlobal", []int{2}},
+	OpGetLocal:      {"OpGetLocal", []int{2}},
+	OpSetLocal:      {"OpSetLocal", []int{2}},
+	OpClosure:       {"OpClosure", []int{2}},
+	OpCall:          {"OpCall", []int{1}},
+	OpTailCall:      {"OpTailCall", []int{1}},
+	OpPipeCall:      {"OpPipeCall", []int{1}},
+	OpReturn:        {"OpReturn", []int{}},
+	OpList:          {"OpList", []int{2}},
+	OpDict:          {"OpDict", []int{2}},
+	OpIndex:         {"OpIndex", []int{}},
+	OpSlice:         {"OpSlice", []int{1}},
+	OpModuleGet:     {"OpModuleGet", []int{2, 1}},
+	OpModuleCall:    {"OpModuleCall", []int{2, 1, 1}},
+	OpImport:        {"OpImport", []int{2}},
+	OpTry:           {"OpTry", []int{2}},
+	OpEndTry:        {"OpEndTry", []int{}},
+	OpMatch:         {"OpMatch", []int{2, 2}},
+	OpMatchFail:     {"OpMatchFail", []int{2}},
+}
+
+// Operators maps the binary opcodes to the operator the evaluator implements
+var Operators = map[Opcode]string{OpAdd: "+", OpSub: "-", OpMul: "*", OpDiv: "/", OpMod: "%",
+	OpEqual: "==", OpNotEqual: "!=", OpLessThan: "<", OpGreaterThan: ">"}
+
+var infixOpcodes = map[string]Opcode{"+": OpAdd, "-": OpSub, "*": OpMul, "/": OpDiv, "%": OpMod,
+	"==": OpEqual, "!=": OpNotEqual, "<": OpLessThan, ">": OpGreaterThan, ".": OpCompose}
+
+func LookupOpcode(op byte) (*Definition, error) {
+	definition, ok := definitions[Opcode(op)]
+	if !ok {
+		return nil, fmt.Errorf("opcode %d undefined", op)
+	}
+	return definition, nil
+}
+
+func Make(op Opcode, operands ...int) []byte {
+	definition, ok := definitions[op]
+	if !ok {
+		return []byte{}
+	}
+	length := 1
+	for _, width := range definition.OperandWidths {
+		length += width
+	}
+	instruction := make([]byte, length)
+	instruction[0] = byte(op)
+	offset := 1
+	for i, operand := range operands {
+		width := definition.OperandWidths[i]
+		switch width {
+		case 2:
+			binary.BigEndian.PutUint16(instruction[offset:], uint16(operand))
+		case 1:
+			instruction[offset] = byte(operand)
+		}
+		offset += width
+	}
+	return instruction
+}
+
+func ReadOperands(definition *Definition, instructions Instructions) ([]int, int) {
+	operands := make([]int, len(definition.OperandWidths))
+	offset := 0
+	for i, width := range definition.OperandWidths {
+		switch width {
+		case 2:
+			operands[i] = int(ReadUint16(instructions[offset:]))
+		case 1:
+			operands[i] = int(instructions[offset])
+		}
+		offset += width
+	}
+	return operands, offset
+}
+
+func ReadUint16(instructions Instructions) uint16 {
+	return binary.BigEndian.Uint16(instructions)
+}
+
+func (instructions Instructions) String() string {
+	buffer := bytes.NewBufferString("")
+	for i := 0; i < len(instructions); {
+		definition, err := LookupOpcode(instructions[i])
+		if err != nil {
+			buffer.WriteString(fmt.Sprintf("ERROR: %s\n", err))
+			i++
+			continue
+		}
+		operands, read := ReadOperands(definition, instructions[i+1:])
+		buffer.WriteString(fmt.Sprintf("%04d %s", i, definition.Name))
+		for _, operand := range operands {
+			buffer.WriteString(fmt.Sprintf(" %d", operand))
+		}
+		buffer.WriteString("\n")
+		i += 1 + read
+	}
+	return buffer.String()
+}
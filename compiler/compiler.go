@@ -0,0 +1,637 @@
+package compiler
+
+import (
+	"rootlang/ast"
+	"rootlang/object"
+	"rootlang/lexer"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const COMPILED_FUNCTION_OBJ = "COMPILED_FUNCTION"
+
+// Unit holds the constants shared by the functions of one compilation
+type Unit struct {
+	Constants []object.Object
+	Names     []string
+	Nodes     []ast.Node
+	names     map[string]int
+}
+
+type SlotRef struct {
+	Depth int
+	Slot  int
+}
+
+// Lookup lists the slots that can hold a name from the innermost scope outwards, when all of them are empty the name is searched in the globals
+type Lookup struct {
+	Name  string
+	Slots []SlotRef
+}
+
+type Pattern struct {
+	Pattern  ast.Pattern
+	Bindings map[string]int
+}
+
+// SourcePosition is the position reported for errors raised by the instruction at Offset, CallPosition is the one used for the stack frame of calls
+type SourcePosition struct {
+	Offset       int
+	Position     lexer.Position
+	CallPosition lexer.Position
+}
+
+type CompiledFunction struct {
+	Instructions Instructions
+	NumSlots     int
+	SlotNames    []string
+	Params       []*ast.Identifier
+	Body         *ast.BlockStatement
+	Lookups      []Lookup
+	Patterns     []Pattern
+	Positions    []SourcePosition
+	Unit         *Unit
+}
+
+func (fn *CompiledFunction) Type() object.ObjectType { return COMPILED_FUNCTION_OBJ }
+func (fn *CompiledFunction) Inspect() string {
+	return fmt.Sprintf("compiled function %d slots\n%s", fn.NumSlots, fn.Instructions.String())
+}
+
+func (fn *CompiledFunction) PositionAt(offset int) SourcePosition {
+	index := sort.Search(len(fn.Positions), func(i int) bool { return fn.Positions[i].Offset >= offset })
+	if index < len(fn.Positions) && fn.Positions[index].Offset == offset {
+		return fn.Positions[index]
+	}
+	return SourcePosition{Offset: offset}
+}
+
+type functionScope struct {
+	function *CompiledFunction
+	blocks   []map[string]int
+	outer    *functionScope
+	top      bool
+	tryDepth int
+}
+
+type Compiler struct {
+	unit  *Unit
+	scope *functionScope
+}
+
+func New() *Compiler {
+	unit := &Unit{Constants: make([]object.Object, 0), Names: make([]string, 0), Nodes: make([]ast.Node, 0), names: make(map[string]int)}
+	return &Compiler{unit: unit}
+}
+
+// Compile lowers a program to the function run at the top level, its lets are stored in the globals environment
+func (c *Compiler) Compile(program *ast.Program) (fn *CompiledFunction, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			compileErr, ok := r.(compileError)
+			if !ok {
+				panic(r)
+			}
+			fn, err = nil, compileErr
+		}
+	}()
+	c.enterFunction(nil, nil, true)
+	c.compileStatements(program.Statements, false)
+	c.emit(OpReturn, lexer.Position{})
+	return c.leaveFunction(), nil
+}
+
+type compileError struct {
+	message string
+}
+
+func (e compileError) Error() string {
+	return e.message
+}
+
+func (c *Compiler) fail(position lexer.Position, message string) {
+	panic(compileError{fmt.Sprintf("%s: %s", position, message)})
+}
+
+func (c *Compiler) enterFunction(params []*ast.Identifier, body *ast.BlockStatement, top bool) {
+	function := &CompiledFunction{Params: params, Body: body, Unit: c.unit, SlotNames: make([]string, 0)}
+	scope := &functionScope{function: function, outer: c.scope, top: top, blocks: []map[string]int{make(map[string]int)}}
+	c.scope = scope
+	for _, param := range params {
+		scope.blocks[0][param.Value] = c.newSlot(param.Value)
+	}
+	if body != nil {
+		c.declareNames(body)
+	}
+}
+
+func (c *Compiler) leaveFunction() *CompiledFunction {
+	function := c.scope.function
+	c.scope = c.scope.outer
+	return function
+}
+
+func (c *Compiler) newSlot(name string) int {
+	function := c.scope.function
+	function.SlotNames = append(function.SlotNames, name)
+	function.NumSlots++
+	return function.NumSlots - 1
+}
+
+// declare returns the slot bound to name in the innermost scope, -1 means a global
+func (c *Compiler) declare(name string) int {
+	scope := c.scope
+	if scope.top && len(scope.blocks) == 1 {
+		return -1
+	}
+	block := scope.blocks[len(scope.blocks)-1]
+	if slot, ok := block[name]; ok {
+		return slot
+	}
+	block[name] = c.newSlot(name)
+	return block[name]
+}
+
+func (c *Compiler) resolve(name string) (Lookup, bool) {
+	lookup := Lookup{Name: name, Slots: make([]SlotRef, 0)}
+	depth := 0
+	for scope := c.scope; scope != nil; scope = scope.outer {
+		for i := len(scope.blocks) - 1; i >= 0; i-- {
+			if scope.top && i == 0 {
+				break
+			}
+			if slot, ok := scope.blocks[i][name]; ok {
+				lookup.Slots = append(lookup.Slots, SlotRef{Depth: depth, Slot: slot})
+			}
+		}
+		depth++
+	}
+	return lookup, len(lookup.Slots) > 0
+}
+
+// declareNames binds the names a function or match arm defines before compiling it, blocks share the scope of the function like in the evaluator
+func (c *Compiler) declareNames(node ast.Node) {
+	switch nodeType := node.(type) {
+	case *ast.BlockStatement:
+		for _, statement := range nodeType.Statements {
+			c.declareNames(statement)
+		}
+	case *ast.LetStatement:
+		c.declare(nodeType.Name.Value)
+		c.declareNames(nodeType.Value)
+	case *ast.ReturnStatement:
+		c.declareNames(nodeType.Value)
+	case *ast.ExpressionStatement:
+		c.declareNames(nodeType.Exp)
+	case *ast.ImportStatement:
+		if nodeType.Symbols == nil {
+			c.declare(nodeType.Name.Value)
+		}
+		for _, symbol := range nodeType.Symbols {
+			c.declare(symbol.Alias.Value)
+		}
+	case *ast.IfExpression:
+		c.declareNames(nodeType.Condition)
+		c.declareNames(nodeType.ConditionalBlock)
+		if nodeType.AlternativeBlock != nil {
+			c.declareNames(nodeType.AlternativeBlock)
+		}
+	case *ast.TryExpression:
+		c.declareNames(nodeType.Block)
+	case *ast.CallFunctionExpression:
+		c.declareNames(nodeType.Function)
+		for _, argument := range nodeType.Arguments {
+			c.declareNames(argument)
+		}
+	case *ast.InfixExpression:
+		c.declareNames(nodeType.LeftExpression)
+		if nodeType.Operator != "::" {
+			c.declareNames(nodeType.RightExpression)
+		} else if call, ok := nodeType.RightExpression.(*ast.CallFunctionExpression); ok {
+			for _, argument := range call.Arguments {
+				c.declareNames(argument)
+			}
+		}
+	case *ast.PrefixExpression:
+		c.declareNames(nodeType.RightExpression)
+	case *ast.PipeExpression:
+		c.declareNames(nodeType.Left)
+		c.declareNames(nodeType.Right)
+	case *ast.ListLiteral:
+		for _, element := range nodeType.Elements {
+			c.declareNames(element)
+		}
+	case *ast.DictLiteral:
+		for i := range nodeType.Keys {
+			c.declareNames(nodeType.Keys[i])
+			c.declareNames(nodeType.Values[i])
+		}
+	case *ast.IndexExpression:
+		c.declareNames(nodeType.Left)
+		c.declareNames(nodeType.Index)
+	case *ast.SliceExpression:
+		c.declareNames(nodeType.Left)
+		if nodeType.Start != nil {
+			c.declareNames(nodeType.Start)
+		}
+		if nodeType.End != nil {
+			c.declareNames(nodeType.End)
+		}
+	case *ast.MatchExpression:
+		c.declareNames(nodeType.Value)
+	}
+}
+
+func (c *Compiler) emit(op Opcode, position lexer.Position, operands ...int) int {
+	return c.emitCall(op, position, position, operands...)
+}
+
+func (c *Compiler) emitCall(op Opcode, position, callPosition lexer.Position, operands ...int) int {
+	function := c.scope.function
+	offset := len(function.Instructions)
+	c.checkOperands(op, position, operands)
+	if position.IsValid() || callPosition.IsValid() {
+		function.Positions = append(function.Positions, SourcePosition{Offset: offset, Position: position, CallPosition: callPosition})
+	}
+	function.Instructions = append(function.Instructions, Make(op, operands...)...)
+	return offset
+}
+
+func (c *Compiler) changeOperand(offset int, operand int) {
+	function := c.scope.function
+	op := Opcode(function.Instructions[offset])
+	position := lexer.Position{}
+	for _, sourcePosition := range function.Positions {
+		if sourcePosition.Offset == offset {
+			position = sourcePosition.Position
+		}
+	}
+	c.checkOperands(op, position, []int{operand})
+	copy(function.Instructions[offset:], Make(op, operand))
+}
+
+// checkOperands fails when an operand does not fit in its width, Make would truncate it to a wrong index or jump
+func (c *Compiler) checkOperands(op Opcode, position lexer.Position, operands []int) {
+	definition := definitions[op]
+	for i, operand := range operands {
+		if limit := 1<<(8*uint(definition.OperandWidths[i])) - 1; operand > limit {
+			c.fail(position, fmt.Sprintf("%s operand %d is greater than %d, the program is too large for the vm", definition.Name, operand, limit))
+		}
+	}
+}
+
+func (c *Compiler) currentOffset() int {
+	return len(c.scope.function.Instructions)
+}
+
+func (c *Compiler) addConstant(value object.Object) int {
+	c.unit.Constants = append(c.unit.Constants, value)
+	return len(c.unit.Constants) - 1
+}
+
+func (c *Compiler) addName(name string) int {
+	if index, ok := c.unit.names[name]; ok {
+		return index
+	}
+	c.unit.Names = append(c.unit.Names, name)
+	c.unit.names[name] = len(c.unit.Names) - 1
+	return len(c.unit.Names) - 1
+}
+
+func (c *Compiler) addNode(node ast.Node) int {
+	c.unit.Nodes = append(c.unit.Nodes, node)
+	return len(c.unit.Nodes) - 1
+}
+
+func (c *Compiler) compileStatements(statements []ast.Statement, tail bool) {
+	if len(statements) == 0 {
+		c.emit(OpNil, lexer.Position{})
+		return
+	}
+	for i, statement := range statements {
+		last := i == len(statements)-1
+		if expressionStatement, ok := statement.(*ast.ExpressionStatement); ok && last && tail {
+			c.compileTail(expressionStatement.Exp)
+		} else {
+			c.compileStatement(statement)
+		}
+		if !last {
+			c.emit(OpPop, lexer.Position{})
+		}
+	}
+}
+
+func (c *Compiler) compileStatement(statement ast.Statement) {
+	switch nodeType := statement.(type) {
+	case *ast.LetStatement:
+		c.compileExpression(nodeType.Value)
+		c.compileSet(nodeType.Name.Value, nodeType.Position())
+		c.emit(OpNil, lexer.Position{})
+	case *ast.ReturnStatement:
+		if c.scope.top || c.scope.tryDepth > 0 {
+			c.compileExpression(nodeType.Value)
+		} else {
+			c.compileTail(nodeType.Value)
+		}
+		c.emit(OpReturn, nodeType.Position())
+	case *ast.ExpressionStatement:
+		c.compileExpression(nodeType.Exp)
+	case *ast.BlockStatement:
+		c.compileStatements(nodeType.Statements, false)
+	case *ast.ImportStatement:
+		c.emit(OpImport, nodeType.Position(), c.addNode(nodeType))
+		if nodeType.Symbols == nil {
+			c.compileSet(nodeType.Name.Value, nodeType.Position())
+		}
+		for i := len(nodeType.Symbols) - 1; i >= 0; i-- {
+			c.compileSet(nodeType.Symbols[i].Alias.Value, nodeType.Symbols[i].Alias.Position())
+		}
+		c.emit(OpNil, lexer.Position{})
+	default:
+		c.emit(OpNil, lexer.Position{})
+	}
+}
+
+func (c *Compiler) compileSet(name string, position lexer.Position) {
+	slot := c.declare(name)
+	if slot < 0 {
+		c.emit(OpSetGlobal, position, c.addName(name))
+		return
+	}
+	c.emit(OpSetLocal, position, slot)
+}
+
+func (c *Compiler) compileGet(identifier *ast.Identifier) {
+	lookup, ok := c.resolve(identifier.Value)
+	if !ok {
+		c.emit(OpGetGlobal, identifier.Position(), c.addName(identifier.Value))
+		return
+	}
+	function := c.scope.function
+	function.Lookups = append(function.Lookups, lookup)
+	c.emit(OpGetLocal, identifier.Position(), len(function.Lookups)-1)
+}
+
+// compileTail compiles an expression in return position, calls there reuse the frame of the caller
+func (c *Compiler) compileTail(expression ast.Expression) {
+	switch nodeType := expression.(type) {
+	case *ast.CallFunctionExpression:
+		c.compileCall(nodeType, OpTailCall)
+	case *ast.IfExpression:
+		c.compileIf(nodeType, true)
+	case *ast.MatchExpression:
+		c.compileMatch(nodeType, true)
+	default:
+		c.compileExpression(expression)
+	}
+}
+
+func (c *Compiler) compileExpression(expression ast.Expression) {
+	switch nodeType := expression.(type) {
+	case *ast.IntegerLiteral:
+		if nodeType.Big != nil {
+			c.emit(OpConstant, nodeType.Position(), c.addConstant(object.NewBigInteger(nodeType.Big)))
+			return
+		}
+		c.emit(OpConstant, nodeType.Position(), c.addConstant(&object.Integer{Value: nodeType.Value}))
+	case *ast.FloatLiteral:
+		c.emit(OpConstant, nodeType.Position(), c.addConstant(&object.Float{Value: nodeType.Value}))
+	case *ast.StringExpression:
+		c.emit(OpConstant, nodeType.Position(), c.addConstant(&object.String{Value: nodeType.Value}))
+	case *ast.BoolExpression:
+		if nodeType.Value == "true" {
+			c.emit(OpTrue, nodeType.Position())
+		} else {
+			c.emit(OpFalse, nodeType.Position())
+		}
+	case *ast.Identifier:
+		c.compileGet(nodeType)
+	case *ast.PrefixExpression:
+		c.compileExpression(nodeType.RightExpression)
+		if nodeType.Operator == "-" {
+			c.emit(OpMinus, nodeType.Position())
+		} else {
+			c.emit(OpNot, nodeType.Position())
+		}
+	case *ast.InfixExpression:
+		if nodeType.Operator == "::" {
+			c.compileExpression(nodeType.LeftExpression)
+			c.compileModuleAccess(nodeType.RightExpression, nodeType.Position(), nodeType.LeftExpression.Position(), false)
+			return
+		}
+		op, ok := infixOpcodes[nodeType.Operator]
+		if !ok {
+			c.fail(nodeType.Position(), fmt.Sprintf("unknow operator %s", nodeType.Operator))
+		}
+		c.compileExpression(nodeType.LeftExpression)
+		c.compileExpression(nodeType.RightExpression)
+		c.emit(op, nodeType.Position())
+	case *ast.IfExpression:
+		c.compileIf(nodeType, false)
+	case *ast.FunctionExpression:
+		c.enterFunction(nodeType.Params, nodeType.Block, false)
+		c.compileStatements(nodeType.Block.Statements, false)
+		c.emit(OpReturn, lexer.Position{})
+		function := c.leaveFunction()
+		c.emit(OpClosure, nodeType.Position(), c.addConstant(function))
+	case *ast.CallFunctionExpression:
+		c.compileCall(nodeType, OpCall)
+	case *ast.PipeExpression:
+		c.compilePipe(nodeType)
+	case *ast.ListLiteral:
+		for _, element := range nodeType.Elements {
+			c.compileExpression(element)
+		}
+		c.emit(OpList, nodeType.Position(), len(nodeType.Elements))
+	case *ast.DictLiteral:
+		for i := range nodeType.Keys {
+			c.compileExpression(nodeType.Keys[i])
+			c.compileExpression(nodeType.Values[i])
+		}
+		c.emit(OpDict, nodeType.Position(), len(nodeType.Keys))
+	case *ast.IndexExpression:
+		c.compileExpression(nodeType.Left)
+		c.compileExpression(nodeType.Index)
+		c.emit(OpIndex, nodeType.Position())
+	case *ast.SliceExpression:
+		c.compileExpression(nodeType.Left)
+		bounds := 0
+		if nodeType.Start != nil {
+			c.compileExpression(nodeType.Start)
+			bounds |= 1
+		}
+		if nodeType.End != nil {
+			c.compileExpression(nodeType.End)
+			bounds |= 2
+		}
+		c.emit(OpSlice, nodeType.Position(), bounds)
+	case *ast.TryExpression:
+		c.compileTry(nodeType)
+	case *ast.MatchExpression:
+		c.compileMatch(nodeType, false)
+	case nil:
+		c.fail(lexer.Position{}, "expression expected")
+	default:
+		c.fail(expression.Position(), fmt.Sprintf("unsupported expression %T", expression))
+	}
+}
+
+func (c *Compiler) compileCall(call *ast.CallFunctionExpression, op Opcode) {
+	c.compileArguments(call.Arguments, call.Position())
+	c.compileExpression(call.Function)
+	c.emit(op, call.Position(), len(call.Arguments))
+}
+
+func (c *Compiler) compileArguments(arguments []ast.Expression, position lexer.Position) {
+	if len(arguments) > 255 {
+		c.fail(position, "a call can not take more than 255 arguments")
+	}
+	for _, argument := range arguments {
+		c.compileExpression(argument)
+	}
+}
+
+func (c *Compiler) compilePipe(pipe *ast.PipeExpression) {
+	c.compileExpression(pipe.Left)
+	switch rightType := pipe.Right.(type) {
+	case *ast.CallFunctionExpression:
+		c.compileArguments(rightType.Arguments, rightType.Position())
+		c.compileExpression(rightType.Function)
+		c.emitCall(OpPipeCall, pipe.Position(), rightType.Position(), len(rightType.Arguments))
+		return
+	case *ast.InfixExpression:
+		if rightType.Operator == "::" {
+			c.compileExpression(rightType.LeftExpression)
+			c.compileModuleAccess(rightType.RightExpression, pipe.Position(), rightType.LeftExpression.Position(), true)
+			return
+		}
+	}
+	c.compileExpression(pipe.Right)
+	c.emitCall(OpPipeCall, pipe.Position(), pipe.Right.Position(), 0)
+}
+
+// compileModuleAccess compiles the right side of ::, the module is already on the stack
+func (c *Compiler) compileModuleAccess(expression ast.Expression, position, callPosition lexer.Position, piped bool) {
+	pipedOperand := 0
+	if piped {
+		pipedOperand = 1
+	}
+	if call, ok := expression.(*ast.CallFunctionExpression); ok {
+		c.compileArguments(call.Arguments, call.Position())
+		c.emitCall(OpModuleCall, position, callPosition, c.addNode(call), len(call.Arguments), pipedOperand)
+		return
+	}
+	c.emitCall(OpModuleGet, position, callPosition, c.addNode(expression), pipedOperand)
+}
+
+func (c *Compiler) compileIf(ifExpression *ast.IfExpression, tail bool) {
+	c.compileExpression(ifExpression.Condition)
+	jumpNotTruthy := c.emit(OpJumpNotTruthy, ifExpression.Condition.Position(), 9999)
+	c.compileStatements(ifExpression.ConditionalBlock.Statements, tail)
+	jump := c.emit(OpJump, lexer.Position{}, 9999)
+	c.changeOperand(jumpNotTruthy, c.currentOffset())
+	if ifExpression.AlternativeBlock != nil {
+		c.compileStatements(ifExpression.AlternativeBlock.Statements, tail)
+	} else {
+		c.emit(OpNil, lexer.Position{})
+	}
+	c.changeOperand(jump, c.currentOffset())
+}
+
+func (c *Compiler) compileTry(try *ast.TryExpression) {
+	tryOffset := c.emit(OpTry, try.Position(), 9999)
+	c.scope.tryDepth++
+	c.compileStatements(try.Block.Statements, false)
+	c.scope.tryDepth--
+	c.emit(OpEndTry, lexer.Position{})
+	jump := c.emit(OpJump, lexer.Position{}, 9999)
+	c.changeOperand(tryOffset, c.currentOffset())
+	c.scope.blocks = append(c.scope.blocks, make(map[string]int))
+	if try.ErrorName != nil {
+		c.declare(try.ErrorName.Value)
+	}
+	c.declareNames(try.CatchBlock)
+	if try.ErrorName != nil {
+		c.compileSet(try.ErrorName.Value, try.ErrorName.Position())
+	} else {
+		c.emit(OpPop, lexer.Position{})
+	}
+	c.compileStatements(try.CatchBlock.Statements, false)
+	c.scope.blocks = c.scope.blocks[:len(c.scope.blocks)-1]
+	c.changeOperand(jump, c.currentOffset())
+}
+
+func (c *Compiler) compileMatch(match *ast.MatchExpression, tail bool) {
+	c.compileExpression(match.Value)
+	subject := c.newSlot("")
+	c.emit(OpSetLocal, match.Position(), subject)
+	ends := make([]int, 0)
+	for _, arm := range match.Arms {
+		c.scope.blocks = append(c.scope.blocks, make(map[string]int))
+		bindings := make(map[string]int)
+		for _, name := range patternNames(arm.Pattern) {
+			bindings[name] = c.declare(name)
+		}
+		if arm.Guard != nil {
+			c.declareNames(arm.Guard)
+		}
+		c.declareNames(arm.Body)
+		function := c.scope.function
+		function.Patterns = append(function.Patterns, Pattern{Pattern: arm.Pattern, Bindings: bindings})
+		c.emit(OpMatch, arm.Pattern.Position(), len(function.Patterns)-1, subject)
+		jumps := []int{c.emit(OpJumpNotTruthy, lexer.Position{}, 9999)}
+		if arm.Guard != nil {
+			c.compileExpression(arm.Guard)
+			jumps = append(jumps, c.emit(OpJumpNotTruthy, arm.Guard.Position(), 9999))
+		}
+		switch body := arm.Body.(type) {
+		case *ast.BlockStatement:
+			c.compileStatements(body.Statements, tail)
+		case ast.Expression:
+			if tail {
+				c.compileTail(body)
+			} else {
+				c.compileExpression(body)
+			}
+		}
+		ends = append(ends, c.emit(OpJump, lexer.Position{}, 9999))
+		for _, jump := range jumps {
+			c.changeOperand(jump, c.currentOffset())
+		}
+		c.scope.blocks = c.scope.blocks[:len(c.scope.blocks)-1]
+	}
+	c.emit(OpMatchFail, match.Position(), subject)
+	for _, end := range ends {
+		c.changeOperand(end, c.currentOffset())
+	}
+}
+
+func patternNames(pattern ast.Pattern) []string {
+	switch patternType := pattern.(type) {
+	case *ast.IdentifierPattern:
+		return []string{patternType.Name.Value}
+	case *ast.ListPattern:
+		names := make([]string, 0)
+		for _, element := range patternType.Elements {
+			names = append(names, patternNames(element)...)
+		}
+		if patternType.Rest != nil {
+			names = append(names, patternNames(patternType.Rest)...)
+		}
+		return names
+	}
+	return []string{}
+}
+
+// Disassemble prints a function and the functions in its constants, it is used to inspect the compiler output
+func Disassemble(fn *CompiledFunction) string {
+	buffer := strings.Builder{}
+	buffer.WriteString(fn.Instructions.String())
+	for i, constant := range fn.Unit.Constants {
+		if function, ok := constant.(*CompiledFunction); ok {
+			buffer.WriteString(fmt.Sprintf("constant %d:\n%s", i, function.Instructions.String()))
+		}
+	}
+	return buffer.String()
+}
@@ -0,0 +1,62 @@
+package compiler
+
+import (
+	"testing"
+	"rootlang/lexer"
+	"rootlang/parser"
+	"strings"
+)
+
+func TestCompileProgram(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 + 2", "0000 OpConstant 0\n0003 OpConstant 1\n0006 OpAdd\n0007 OpReturn\n"},
+		{"let f = x => f(x - 1); f(1)", "0000 OpClosure 1\n0003 OpSetGlobal 0\n0006 OpNil\n0007 OpPop\n" +
+			"0008 OpConstant 2\n0011 OpGetGlobal 0\n0014 OpCall 1\n0016 OpReturn\n" +
+			"constant 1:\n0000 OpGetLocal 0\n0003 OpConstant 0\n0006 OpSub\n0007 OpGetGlobal 0\n" +
+			"0010 OpTailCall 1\n0012 OpReturn\n0013 OpReturn\n"},
+	}
+	for _, test := range tests {
+		program := parser.New(lexer.New(test.input)).ParseProgram()
+		fn, err := New().Compile(program)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", test.input, err)
+			continue
+		}
+		if Disassemble(fn) != test.expected {
+			t.Errorf("%s: expected\n%s and got\n%s", test.input, test.expected, Disassemble(fn))
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[" + strings.Repeat("1, ", 70000) + "1]", "OpConstant operand 65536 is greater than 65535"},
+		{"if (true) { " + strings.Repeat("true; ", 40000) + "}", "1:5: OpJumpNotTruthy operand 80006 is greater than 65535"},
+	}
+	for _, test := range tests {
+		program := parser.New(lexer.New(test.input)).ParseProgram()
+		_, err := New().Compile(program)
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%.40s: expected error %s and got %v", test.input, test.expected, err)
+		}
+	}
+}
+
+func TestMake(t *testing.T) {
+	instruction := Make(OpModuleCall, 258, 2, 1)
+	expected := []byte{byte(OpModuleCall), 1, 2, 2, 1}
+	if string(instruction) != string(expected) {
+		t.Errorf("expected %v and got %v", expected, instruction)
+	}
+	definition, _ := LookupOpcode(instruction[0])
+	operands, read := ReadOperands(definition, instruction[1:])
+	if read != 4 || operands[0] != 258 || operands[1] != 2 || operands[2] != 1 {
+		t.Errorf("wrong operands %v read %d", operands, read)
+	}
+}
@@ -0,0 +1,341 @@
+package rootlang
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"rootlang/builtin"
+	"rootlang/evaluator"
+	"rootlang/lexer"
+	"rootlang/object"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// ToObject converts a Go value to rootlang. Integers, floats, strings, bools, nil, *big.Int, slices, arrays, maps and
+// functions are converted, an object.Object is returned as it is. A Go function can return an error as its last
+// result, a non nil error is returned to rootlang as an error value.
+func (i *Interpreter) ToObject(value interface{}) (object.Object, error) {
+	switch valueType := value.(type) {
+	case nil:
+		return object.NULL, nil
+	case object.Object:
+		return valueType, nil
+	case *big.Int:
+		return object.NewBigInteger(valueType), nil
+	}
+	return i.toObject(reflect.ValueOf(value))
+}
+
+func (i *Interpreter) toObject(value reflect.Value) (object.Object, error) {
+	switch value.Kind() {
+	case reflect.Bool:
+		if value.Bool() {
+			return object.TRUE, nil
+		}
+		return object.FALSE, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &object.Integer{Value: value.Int()}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if value.Uint() > math.MaxInt64 {
+			return object.NewBigInteger(new(big.Int).SetUint64(value.Uint())), nil
+		}
+		return &object.Integer{Value: int64(value.Uint())}, nil
+	case reflect.Float32, reflect.Float64:
+		return &object.Float{Value: value.Float()}, nil
+	case reflect.String:
+		return &object.String{Value: value.String()}, nil
+	case reflect.Slice, reflect.Array:
+		elements := make([]object.Object, 0, value.Len())
+		for index := 0; index < value.Len(); index++ {
+			element, err := i.ToObject(value.Index(index).Interface())
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, element)
+		}
+		return object.NewList(elements), nil
+	case reflect.Map:
+		return i.toDict(value)
+	case reflect.Func:
+		return i.toBuiltinFunction(value), nil
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return object.NULL, nil
+		}
+		return i.ToObject(value.Elem().Interface())
+	}
+	return nil, fmt.Errorf("%s can not be converted to a rootlang value", value.Type())
+}
+
+// toDict adds the pairs sorted by key, Go maps have no order and the dict keeps the order its keys were added
+func (i *Interpreter) toDict(value reflect.Value) (object.Object, error) {
+	keys := make([]object.Hashable, 0, value.Len())
+	values := make(map[object.HashKey]object.Object, value.Len())
+	for _, key := range value.MapKeys() {
+		keyObject, err := i.ToObject(key.Interface())
+		if err != nil {
+			return nil, err
+		}
+		hashable, ok := keyObject.(object.Hashable)
+		if !ok {
+			return nil, fmt.Errorf("unusable as dict key %s", keyObject.Type())
+		}
+		element, err := i.ToObject(value.MapIndex(key).Interface())
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, hashable)
+		values[hashable.HashKey()] = element
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		return keys[a].Inspect() < keys[b].Inspect()
+	})
+	dict := object.NewDict()
+	for _, key := range keys {
+		dict.Set(key, values[key.HashKey()])
+	}
+	return dict, nil
+}
+
+// toBuiltinFunction wraps a Go function, the params are converted to the types of its params when it is called. A panic
+// of the function, like an error of a rootlang callback it got as a func without an error result, is returned as an error
+func (i *Interpreter) toBuiltinFunction(function reflect.Value) *builtin.BuiltinFunction {
+	functionType := function.Type()
+	return builtin.NewFunction("", func(_ *builtin.Caller, params ...object.Object) (result object.Object) {
+		in, err := i.arguments(functionType, params)
+		if err != nil {
+			return &object.ErrorObject{Error: err.Error()}
+		}
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				result = &object.ErrorObject{Error: fmt.Sprint(recovered)}
+			}
+		}()
+		return i.results(function.Call(in))
+	})
+}
+
+func (i *Interpreter) arguments(functionType reflect.Type, params []object.Object) ([]reflect.Value, error) {
+	fixed := functionType.NumIn()
+	if functionType.IsVariadic() {
+		fixed--
+	}
+	if len(params) < fixed || (!functionType.IsVariadic() && len(params) != fixed) {
+		return nil, fmt.Errorf("expected %d params and got %d", fixed, len(params))
+	}
+	in := make([]reflect.Value, 0, len(params))
+	for index, param := range params {
+		var paramType reflect.Type
+		if index < fixed {
+			paramType = functionType.In(index)
+		} else {
+			paramType = functionType.In(fixed).Elem()
+		}
+		value, err := i.toGoValue(param, paramType)
+		if err != nil {
+			return nil, fmt.Errorf("param %d: %s", index+1, err.Error())
+		}
+		in = append(in, value)
+	}
+	return in, nil
+}
+
+// results converts what a Go function returned, a function with more than one result returns a list
+func (i *Interpreter) results(out []reflect.Value) object.Object {
+	if len(out) != 0 && out[len(out)-1].Type() == errorType {
+		if err := out[len(out)-1].Interface(); err != nil {
+			return &object.ErrorObject{Error: err.(error).Error()}
+		}
+		out = out[:len(out)-1]
+	}
+	values := make([]object.Object, 0, len(out))
+	for _, result := range out {
+		value, err := i.ToObject(result.Interface())
+		if err != nil {
+			return &object.ErrorObject{Error: err.Error()}
+		}
+		values = append(values, value)
+	}
+	switch len(values) {
+	case 0:
+		return object.NULL
+	case 1:
+		return values[0]
+	}
+	return object.NewList(values)
+}
+
+// ToGo converts a rootlang value to Go: int64 or *big.Int, float64, string, bool, nil, []interface{} for lists and
+// map[interface{}]interface{} for dicts. Functions become func(args ...interface{}) (interface{}, error), error values
+// become errors and the other values are returned as they are.
+func (i *Interpreter) ToGo(value object.Object) interface{} {
+	switch valueType := value.(type) {
+	case *object.Integer:
+		if valueType.Big != nil {
+			return valueType.BigValue()
+		}
+		return valueType.Value
+	case *object.Float:
+		return valueType.Value
+	case *object.String:
+		return valueType.Value
+	case *object.Boolean:
+		return valueType.Value
+	case *object.Null:
+		return nil
+	case *object.List:
+		elements := make([]interface{}, 0, valueType.Len())
+		for _, element := range valueType.Elements() {
+			elements = append(elements, i.ToGo(element))
+		}
+		return elements
+	case *object.Dict:
+		dict := make(map[interface{}]interface{}, len(valueType.Keys))
+		for _, key := range valueType.Keys {
+			pair := valueType.Pairs[key]
+			dict[i.ToGo(pair.Key)] = i.ToGo(pair.Value)
+		}
+		return dict
+	case *object.ErrorObject:
+		return errors.New(valueType.Inspect())
+	case *object.Function, *builtin.BuiltinFunction, object.Callable:
+		return func(args ...interface{}) (interface{}, error) {
+			return i.call(value, args)
+		}
+	}
+	return value
+}
+
+// toGoValue converts a rootlang value to the type of a param of a Go function
+func (i *Interpreter) toGoValue(value object.Object, target reflect.Type) (reflect.Value, error) {
+	if value == nil {
+		switch target.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Slice, reflect.Map, reflect.Func:
+			return reflect.Zero(target), nil
+		}
+		return reflect.Value{}, fmt.Errorf("expected %s and got null", target)
+	}
+	if (target.Kind() != reflect.Interface || target.NumMethod() != 0) && reflect.TypeOf(value).AssignableTo(target) {
+		return reflect.ValueOf(value), nil
+	}
+	switch target.Kind() {
+	case reflect.Func:
+		return i.toGoFunction(value, target)
+	case reflect.Slice:
+		if list, ok := value.(*object.List); ok {
+			slice := reflect.MakeSlice(target, 0, list.Len())
+			for _, element := range list.Elements() {
+				converted, err := i.toGoValue(element, target.Elem())
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				slice = reflect.Append(slice, converted)
+			}
+			return slice, nil
+		}
+	case reflect.Map:
+		if dict, ok := value.(*object.Dict); ok {
+			converted := reflect.MakeMapWithSize(target, len(dict.Keys))
+			for _, key := range dict.Keys {
+				pair := dict.Pairs[key]
+				mapKey, err := i.toGoValue(pair.Key, target.Key())
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				mapValue, err := i.toGoValue(pair.Value, target.Elem())
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				converted.SetMapIndex(mapKey, mapValue)
+			}
+			return converted, nil
+		}
+	}
+	generic := i.ToGo(value)
+	if generic == nil {
+		switch target.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Slice, reflect.Map, reflect.Func:
+			return reflect.Zero(target), nil
+		}
+		return reflect.Value{}, fmt.Errorf("expected %s and got %s", target, value.Type())
+	}
+	converted := reflect.ValueOf(generic)
+	if converted.Type().AssignableTo(target) {
+		return converted, nil
+	}
+	if isNumber(converted.Kind()) && isNumber(target.Kind()) {
+		result := converted.Convert(target)
+		negative := converted.Kind() == reflect.Int64 && converted.Int() < 0
+		if result.Convert(converted.Type()).Interface() == generic && !(negative && isUnsigned(target.Kind())) {
+			return result, nil
+		}
+		return reflect.Value{}, fmt.Errorf("%s does not fit in %s", value.Inspect(), target)
+	}
+	return reflect.Value{}, fmt.Errorf("expected %s and got %s", target, value.Type())
+}
+
+// toGoFunction builds a Go function of type target that calls the rootlang function, when target has no error result
+// an error of the call panics and the Go function that called it returns the error to rootlang
+func (i *Interpreter) toGoFunction(function object.Object, target reflect.Type) (reflect.Value, error) {
+	if function.Type() != object.FUNCTION_OBJ && function.Type() != object.BUILTIN_FUNCTION_OBJ {
+		if _, ok := function.(object.Callable); !ok {
+			return reflect.Value{}, fmt.Errorf("expected %s and got %s", target, function.Type())
+		}
+	}
+	returnsError := target.NumOut() != 0 && target.Out(target.NumOut()-1) == errorType
+	return reflect.MakeFunc(target, func(args []reflect.Value) []reflect.Value {
+		out := make([]reflect.Value, 0, target.NumOut())
+		for index := 0; index < target.NumOut(); index++ {
+			out = append(out, reflect.Zero(target.Out(index)))
+		}
+		fail := func(err error) []reflect.Value {
+			if !returnsError {
+				panic(err)
+			}
+			out[len(out)-1] = reflect.ValueOf(&err).Elem()
+			return out
+		}
+		params := make([]object.Object, 0, len(args))
+		for _, arg := range args {
+			param, err := i.ToObject(arg.Interface())
+			if err != nil {
+				return fail(err)
+			}
+			params = append(params, param)
+		}
+		result := evaluator.CallFunction(function, params, "", lexer.Position{}, i.environment, i.builtins)
+		if errorObject, ok := result.(*object.ErrorObject); ok {
+			return fail(errors.New(errorObject.Inspect()))
+		}
+		if len(out) != 0 && target.Out(0) != errorType {
+			converted, err := i.toGoValue(result, target.Out(0))
+			if err != nil {
+				return fail(err)
+			}
+			out[0] = converted
+		}
+		return out
+	}), nil
+}
+
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func isUnsigned(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
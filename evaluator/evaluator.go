@@ -4,14 +4,32 @@ import (
 	"rootlang/ast"
 	"rootlang/object"
 	"rootlang/builtin"
+	"rootlang/lexer"
 	"fmt"
+	"math"
+	"math/big"
 	"strings"
 )
 
 func CallMainFunction(function *object.Function, builtinSymbols *builtin.Builtin) object.Object {
-	return applyArgumentsToFunctionAndCall(function, []object.Object{}, builtinSymbols)
+	returnValue := applyArgumentsToFunctionAndCall(function, []object.Object{}, builtinSymbols)
+	if errorObject, ok := returnValue.(*object.ErrorObject); ok {
+		errorObject.AddFrame(function.Name, "", lexer.Position{})
+	}
+	return returnValue
 }
 func Eval(node ast.Node, environment *object.Environment, builtinSymbols *builtin.Builtin) object.Object {
+	return withPosition(node, evalNode(node, environment, builtinSymbols))
+}
+
+func withPosition(node ast.Node, result object.Object) object.Object {
+	if errorObject, ok := result.(*object.ErrorObject); ok && !errorObject.Position.IsValid() {
+		errorObject.Position = node.Position()
+	}
+	return result
+}
+
+func evalNode(node ast.Node, environment *object.Environment, builtinSymbols *builtin.Builtin) object.Object {
 
 	switch nodeType := node.(type) {
 	case *ast.Program:
@@ -21,33 +39,40 @@ func Eval(node ast.Node, environment *object.Environment, builtinSymbols *builti
 	case *ast.ExpressionStatement:
 		return Eval(nodeType.Exp, environment, builtinSymbols)
 	case *ast.IntegerLiteral:
+		if nodeType.Big != nil {
+			return object.NewBigInteger(nodeType.Big)
+		}
 		return &object.Integer{Value: nodeType.Value}
+	case *ast.FloatLiteral:
+		return &object.Float{Value: nodeType.Value}
 	case *ast.BoolExpression:
 		return nativeToBooleanObject(nodeType.Value == "true")
 	case *ast.StringExpression:
 		return nativeStringToObject(nodeType.Value)
 	case *ast.ImportStatement:
-		builtinModule, ok := builtinSymbols.GetObject(nodeType.Name.Value)
-		if ok {
-			environment.SetVar(nodeType.Name.Value, builtinModule)
-			return nil
+		if nodeType.Symbols != nil {
+			return evalSelectiveImport(nodeType, environment, builtinSymbols)
 		}
-		resultImport := importModule(nodeType, builtinSymbols)
-		if isError(resultImport) {
-			return resultImport
+		module := Import(nodeType, builtinSymbols)
+		if isError(module) {
+			return module
 		}
-		module := resultImport.(*object.Module)
-		environment.SetVar(module.Name, module)
+		environment.SetVar(nodeType.Name.Value, module)
+		return nil
+	case *ast.ExportStatement:
 		return nil
 	case *ast.LetStatement:
 		valueExpression := Eval(nodeType.Value, environment, builtinSymbols)
 		if isError(valueExpression) {
 			return valueExpression
 		}
-		environment.SetVar(nodeType.Name.Value, valueExpression)
+		if function, ok := valueExpression.(*object.Function); ok && function.Name == "" {
+			function.Name = nodeType.Name.Value
+		}
+		setIdentifier(nodeType.Name, valueExpression, environment)
 		return nil
 	case *ast.Identifier:
-		value, ok := environment.GetVar(nodeType.Value)
+		value, ok := lookupIdentifier(nodeType, environment)
 		if !ok {
 			value, ok = builtinSymbols.GetObject(nodeType.Value)
 			if !ok {
@@ -56,29 +81,52 @@ func Eval(node ast.Node, environment *object.Environment, builtinSymbols *builti
 		}
 		return value
 	case *ast.FunctionExpression:
+		if nodeType.Locals != nil {
+			return &object.Function{Params: nodeType.Params, Body: nodeType.Block, Env: environment, Locals: nodeType.Locals}
+		}
 		return &object.Function{Params: nodeType.Params, Body: nodeType.Block, Env: environment.ExtendNewEnvironment()}
 	case *ast.CallFunctionExpression:
-		params := evalExpressions(nodeType.Arguments, environment, builtinSymbols)
-		if len(params) == 1 && isError(params[0]) {
-			return params[0]
-		}
-		value := Eval(nodeType.Function, environment, builtinSymbols)
+		return evalCallFunctionExpression(nodeType, environment, builtinSymbols)
+	case *ast.PipeExpression:
+		value := Eval(nodeType.Left, environment, builtinSymbols)
 		if isError(value) {
 			return value
 		}
-		switch valueFunction := value.(type) {
-		case *object.Function:
-			return applyArgumentsToFunctionAndCall(valueFunction, params, builtinSymbols)
-		case *builtin.BuiltinFunction:
-			return valueFunction.Function(environment, builtinSymbols, Eval, params...)
-		default:
-			return newError(fmt.Sprintf("expected function %s", value.Inspect()))
+		return evalPipeExpression(value, nodeType.Right, environment, builtinSymbols)
+	case *ast.ListLiteral:
+		elements := evalExpressions(nodeType.Elements, environment, builtinSymbols)
+		if len(elements) == 1 && isError(elements[0]) {
+			return elements[0]
 		}
-		function, ok := value.(*object.Function)
-		if !ok {
-			return newError(fmt.Sprintf("expected function %s", value.Inspect()))
+		return object.NewList(elements)
+	case *ast.DictLiteral:
+		return evalDictLiteral(nodeType, environment, builtinSymbols)
+	case *ast.IndexExpression:
+		left := Eval(nodeType.Left, environment, builtinSymbols)
+		if isError(left) {
+			return left
+		}
+		index := Eval(nodeType.Index, environment, builtinSymbols)
+		if isError(index) {
+			return index
+		}
+		return evalIndexExpression(left, index)
+	case *ast.SliceExpression:
+		left := Eval(nodeType.Left, environment, builtinSymbols)
+		if isError(left) {
+			return left
 		}
-		return applyArgumentsToFunctionAndCall(function, params, builtinSymbols)
+		bounds := make([]object.Object, 2)
+		for i, bound := range []ast.Expression{nodeType.Start, nodeType.End} {
+			if bound == nil {
+				continue
+			}
+			bounds[i] = Eval(bound, environment, builtinSymbols)
+			if isError(bounds[i]) {
+				return bounds[i]
+			}
+		}
+		return evalSliceExpression(left, bounds[0], bounds[1])
 	case *ast.PrefixExpression:
 		rightExpression := Eval(nodeType.RightExpression, environment, builtinSymbols)
 		if isError(rightExpression) {
@@ -86,8 +134,8 @@ func Eval(node ast.Node, environment *object.Environment, builtinSymbols *builti
 		}
 		return evalPrefixExpression(nodeType.Operator, rightExpression)
 	case *ast.ReturnStatement:
-		value := Eval(nodeType.Value, environment, builtinSymbols)
-		if isError(value) {
+		value := evalTail(nodeType.Value, environment, builtinSymbols)
+		if isError(value) || (value != nil && value.Type() == object.RETURN_OBJ) {
 			return value
 		}
 		return &object.ReturnObject{Value: value}
@@ -103,6 +151,29 @@ func Eval(node ast.Node, environment *object.Environment, builtinSymbols *builti
 		} else {
 			return nil
 		}
+	case *ast.TryExpression:
+		returnValue := forceTailCall(Eval(nodeType.Block, environment, builtinSymbols), builtinSymbols)
+		errorObject, ok := returnValue.(*object.ErrorObject)
+		if !ok {
+			return returnValue
+		}
+		var catchEnvironment *object.Environment
+		if nodeType.Locals != nil {
+			catchEnvironment = object.NewFrame(environment, nodeType.Locals)
+		} else {
+			catchEnvironment = environment.ExtendNewEnvironment()
+		}
+		if nodeType.ErrorName != nil {
+			errorValue := &object.ErrorValue{Message: errorObject.Error, Position: errorObject.Position, Stack: errorObject.Stack}
+			catchEnvironment.SetVar(nodeType.ErrorName.Value, errorValue)
+		}
+		return Eval(nodeType.CatchBlock, catchEnvironment, builtinSymbols)
+	case *ast.MatchExpression:
+		value := Eval(nodeType.Value, environment, builtinSymbols)
+		if isError(value) {
+			return value
+		}
+		return evalMatchExpression(nodeType, value, environment, builtinSymbols)
 	case *ast.InfixExpression:
 		leftExpression := Eval(nodeType.LeftExpression, environment, builtinSymbols)
 		if isError(leftExpression) {
@@ -113,46 +184,192 @@ func Eval(node ast.Node, environment *object.Environment, builtinSymbols *builti
 		}
 
 		if nodeType.Operator == "::" && leftExpression.Type() == object.MODULE_OBJ {
-			return moduleEvaluation(leftExpression.(*object.Module), nodeType.RightExpression, environment, builtinSymbols)
+			return moduleEvaluation(leftExpression.(*object.Module), nodeType.RightExpression, nodeType.LeftExpression.Position(), environment, builtinSymbols)
 		}
 		rightExpression := Eval(nodeType.RightExpression, environment, builtinSymbols)
 
 		if isError(rightExpression) {
 			return rightExpression
 		}
+		if nodeType.Operator == "." {
+			return evalComposeExpression(leftExpression, rightExpression, nodeType.Token)
+		}
 		return evalInfixExpression(nodeType.Operator, rightExpression, leftExpression)
 	}
 
 	return nil
 }
 
-func moduleEvaluation(module *object.Module, expression ast.Expression, environment *object.Environment, builtinSymbols *builtin.Builtin) object.Object {
+// lookupIdentifier reads the slot found by the resolver, identifiers of programs not resolved are looked up by name
+func setIdentifier(identifier *ast.Identifier, value object.Object, environment *object.Environment) {
+	if identifier.Scope == ast.LocalScope {
+		environment.SetSlot(identifier.Slot, value)
+	} else {
+		environment.SetVar(identifier.Value, value)
+	}
+}
+
+func evalSelectiveImport(importStatement *ast.ImportStatement, environment *object.Environment, builtinSymbols *builtin.Builtin) object.Object {
+	values, err := ImportSymbols(importStatement, builtinSymbols)
+	if err != nil {
+		return err
+	}
+	for i, symbol := range importStatement.Symbols {
+		setIdentifier(symbol.Alias, values[i], environment)
+	}
+	return nil
+}
+
+func lookupIdentifier(identifier *ast.Identifier, environment *object.Environment) (object.Object, bool) {
+	switch identifier.Scope {
+	case ast.LocalScope:
+		return environment.GetSlot(identifier.Depth, identifier.Slot, identifier.Value)
+	case ast.GlobalScope:
+		return environment.GetGlobal(identifier.Value)
+	}
+	return environment.GetVar(identifier.Value)
+}
+
+func evalMatchExpression(match *ast.MatchExpression, value object.Object, environment *object.Environment, builtinSymbols *builtin.Builtin) object.Object {
+	arm, armEnvironment, err := selectMatchArm(match, value, environment, builtinSymbols)
+	if err != nil {
+		return err
+	}
+	return Eval(arm.Body, armEnvironment, builtinSymbols)
+}
+
+func selectMatchArm(match *ast.MatchExpression, value object.Object, environment *object.Environment, builtinSymbols *builtin.Builtin) (*ast.MatchArm, *object.Environment, object.Object) {
+	for _, arm := range match.Arms {
+		var armEnvironment *object.Environment
+		if arm.Locals != nil {
+			armEnvironment = object.NewFrame(environment, arm.Locals)
+		} else {
+			armEnvironment = environment.ExtendNewEnvironment()
+		}
+		if !matchPattern(arm.Pattern, value, armEnvironment.SetVar) {
+			continue
+		}
+		if arm.Guard != nil {
+			guard := Eval(arm.Guard, armEnvironment, builtinSymbols)
+			if isError(guard) {
+				return nil, nil, guard
+			}
+			if !evalTruthValue(guard) {
+				continue
+			}
+		}
+		return arm, armEnvironment, nil
+	}
+	return nil, nil, newError(fmt.Sprintf("no pattern matched %s", value.Inspect()))
+}
+
+func matchPattern(pattern ast.Pattern, value object.Object, bind func(name string, value object.Object)) bool {
+	switch patternType := pattern.(type) {
+	case *ast.WildcardPattern:
+		return true
+	case *ast.IdentifierPattern:
+		bind(patternType.Name.Value, value)
+		return true
+	case *ast.LiteralPattern:
+		return literalEquals(Eval(patternType.Value, nil, nil), value)
+	case *ast.ListPattern:
+		list, ok := value.(*object.List)
+		if !ok {
+			return false
+		}
+		if list.Len() < len(patternType.Elements) || (patternType.Rest == nil && list.Len() != len(patternType.Elements)) {
+			return false
+		}
+		for i, element := range patternType.Elements {
+			if !matchPattern(element, list.Get(i), bind) {
+				return false
+			}
+		}
+		if patternType.Rest == nil {
+			return true
+		}
+		return matchPattern(patternType.Rest, list.Slice(len(patternType.Elements), list.Len()), bind)
+	}
+	return false
+}
+
+func literalEquals(literal, value object.Object) bool {
+	if literalInteger, ok := literal.(*object.Integer); ok {
+		if valueInteger, ok := value.(*object.Integer); ok {
+			return literalInteger.Cmp(valueInteger) == 0
+		}
+	}
+	if isNumber(literal) && isNumber(value) {
+		return toFloat(literal) == toFloat(value)
+	}
+	if literalString, ok := literal.(*object.String); ok {
+		valueString, ok := value.(*object.String)
+		return ok && literalString.Value == valueString.Value
+	}
+	return literal == value
+}
+
+func evalCallFunctionExpression(call *ast.CallFunctionExpression, environment *object.Environment, builtinSymbols *builtin.Builtin, pipedParams ...object.Object) object.Object {
+	params := evalExpressions(call.Arguments, environment, builtinSymbols)
+	if len(params) == 1 && isError(params[0]) {
+		return params[0]
+	}
+	value := Eval(call.Function, environment, builtinSymbols)
+	if isError(value) {
+		return value
+	}
+	return callFunction(value, append(params, pipedParams...), "", call.Position(), environment, builtinSymbols)
+}
+
+// evalPipeExpression passes value as the last argument of the call on the right side of |>
+func evalPipeExpression(value object.Object, right ast.Expression, environment *object.Environment, builtinSymbols *builtin.Builtin) object.Object {
+	switch rightType := right.(type) {
+	case *ast.CallFunctionExpression:
+		return evalCallFunctionExpression(rightType, environment, builtinSymbols, value)
+	case *ast.InfixExpression:
+		if rightType.Operator != "::" {
+			break
+		}
+		module := Eval(rightType.LeftExpression, environment, builtinSymbols)
+		if isError(module) {
+			return module
+		}
+		if module.Type() != object.MODULE_OBJ {
+			return newError("module was expected")
+		}
+		return moduleEvaluation(module.(*object.Module), rightType.RightExpression, rightType.LeftExpression.Position(), environment, builtinSymbols, value)
+	}
+	function := Eval(right, environment, builtinSymbols)
+	if isError(function) {
+		return function
+	}
+	return callFunction(function, []object.Object{value}, "", right.Position(), environment, builtinSymbols)
+}
+
+func moduleEvaluation(module *object.Module, expression ast.Expression, position lexer.Position, environment *object.Environment, builtinSymbols *builtin.Builtin, pipedParams ...object.Object) object.Object {
 	switch nodeType := expression.(type) {
 	case *ast.CallFunctionExpression:
 		params := evalExpressions(nodeType.Arguments, environment, builtinSymbols)
 		if len(params) == 1 && isError(params[0]) {
 			return params[0]
 		}
-		value := Eval(nodeType.Function, module.Env, builtinSymbols)
+		params = append(params, pipedParams...)
+		value := ModuleFunction(module, nodeType.Function, builtinSymbols)
 		if isError(value) {
 			return value
 		}
 		if value.Type() != object.FUNCTION_OBJ && value.Type() != object.BUILTIN_FUNCTION_OBJ {
 			return newError(fmt.Sprintf("expected function and got %s", value.Type()))
 		}
-		switch functionType := value.(type) {
-		case *object.Function:
-			return applyArgumentsToFunctionAndCall(functionType, params, builtinSymbols)
-		case *builtin.BuiltinFunction:
-			return functionType.Function(environment, builtinSymbols, Eval, params...)
-		default:
-			return newError("expression not expected on module")
-		}
+		return callFunction(value, params, module.Name, position, environment, builtinSymbols)
 
 	case *ast.Identifier:
-		value, ok := module.Env.GetVar(nodeType.Value)
-		if !ok {
-			return newError(fmt.Sprintf("symbol %s not found in module %s", nodeType.Value, module.Name))
+		value := ModuleSymbol(module, nodeType.Value)
+		if isError(value) {
+			return value
+		}
+		if len(pipedParams) != 0 {
+			return callFunction(value, pipedParams, module.Name, position, environment, builtinSymbols)
 		}
 		return value
 	default:
@@ -161,28 +378,304 @@ func moduleEvaluation(module *object.Module, expression ast.Expression, environm
 
 }
 
-func applyArgumentsToFunctionAndCall(function *object.Function, params []object.Object, builtinSymbols *builtin.Builtin) object.Object {
+// evalComposeExpression builds the function x => left(right(x)), the params are taken from right when it is a rootlang function
+func evalComposeExpression(left, right object.Object, token lexer.Token) object.Object {
+	if !isFunction(left) || !isFunction(right) {
+		return newError(fmt.Sprintf("compose operator expected functions and got %s . %s", typeOf(left), typeOf(right)))
+	}
+	params := []*ast.Identifier{{Token: lexer.Token{Type: lexer.IDENT, Literal: "x", Position: token.Position}, Value: "x"}}
+	if function, ok := right.(*object.Function); ok {
+		params = function.Params
+	} else if callable, ok := right.(object.Callable); ok {
+		params = callable.Params()
+	}
+	arguments := make([]ast.Expression, 0)
+	for _, param := range params {
+		arguments = append(arguments, &ast.Identifier{Token: param.Token, Value: param.Value})
+	}
+	leftIdentifier := &ast.Identifier{Token: lexer.Token{Type: lexer.IDENT, Literal: "<f>", Position: token.Position}, Value: "<f>"}
+	rightIdentifier := &ast.Identifier{Token: lexer.Token{Type: lexer.IDENT, Literal: "<g>", Position: token.Position}, Value: "<g>"}
+	rightCall := &ast.CallFunctionExpression{Token: token, Function: rightIdentifier, Arguments: arguments}
+	leftCall := &ast.CallFunctionExpression{Token: token, Function: leftIdentifier, Arguments: []ast.Expression{rightCall}}
+	returnStatement := &ast.ReturnStatement{Token: lexer.Token{Type: lexer.RETURN, Literal: "return", Position: token.Position}, Value: leftCall}
+	body := &ast.BlockStatement{Token: token, Statements: []ast.Statement{returnStatement}}
+	environment := object.NewEnvironment()
+	environment.SetVar("<f>", left)
+	environment.SetVar("<g>", right)
+	return &object.Function{Params: params, Body: body, Env: environment}
+}
+
+func isFunction(value object.Object) bool {
+	return value != nil && (value.Type() == object.FUNCTION_OBJ || value.Type() == object.BUILTIN_FUNCTION_OBJ)
+}
+
+// typeOf is the type of a value for error messages, a missing value is null
+func typeOf(value object.Object) object.ObjectType {
+	if value == nil {
+		return object.NULL_OBJ
+	}
+	return value.Type()
+}
+
+func evalDictLiteral(dictLiteral *ast.DictLiteral, environment *object.Environment, builtinSymbols *builtin.Builtin) object.Object {
+	dict := object.NewDict()
+	for i, keyExpression := range dictLiteral.Keys {
+		key := Eval(keyExpression, environment, builtinSymbols)
+		if isError(key) {
+			return key
+		}
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return newError(fmt.Sprintf("unusable as dict key %s", key.Type()))
+		}
+		value := Eval(dictLiteral.Values[i], environment, builtinSymbols)
+		if isError(value) {
+			return value
+		}
+		dict.Set(hashKey, value)
+	}
+	return dict
+}
+
+func evalIndexExpression(left, index object.Object) object.Object {
+	switch leftType := left.(type) {
+	case *object.Dict:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError(fmt.Sprintf("unusable as dict key %s", index.Type()))
+		}
+		value, ok := leftType.Get(key)
+		if !ok {
+			return newError(fmt.Sprintf("key %s not found in dict", index.Inspect()))
+		}
+		return value
+	case *object.List:
+		position, err := indexPosition(index, leftType.Len())
+		if err != nil {
+			return err
+		}
+		return leftType.Get(position)
+	case *object.String:
+		position, err := indexPosition(index, len(leftType.Value))
+		if err != nil {
+			return err
+		}
+		return nativeStringToObject(leftType.Value[position:position+1])
+	default:
+		return newError(fmt.Sprintf("index operator not supported on %s", left.Type()))
+	}
+}
+
+func indexPosition(index object.Object, length int) (int, *object.ErrorObject) {
+	integerIndex, ok := index.(*object.Integer)
+	if !ok {
+		return 0, newError(fmt.Sprintf("index should be integer and got %s", index.Type()))
+	}
+	if integerIndex.Big != nil {
+		return 0, newError(fmt.Sprintf("index %s does not fit in 64 bits", integerIndex.Inspect()))
+	}
+	position := integerIndex.Value
+	if position < 0 {
+		position += int64(length)
+	}
+	if position < 0 || position >= int64(length) {
+		return 0, newError(fmt.Sprintf("index %d out of range with length %d", integerIndex.Value, length))
+	}
+	return int(position), nil
+}
+
+func evalSliceExpression(left, start, end object.Object) object.Object {
+	switch leftType := left.(type) {
+	case *object.List:
+		from, to, err := sliceBounds(start, end, leftType.Len())
+		if err != nil {
+			return err
+		}
+		return leftType.Slice(from, to)
+	case *object.String:
+		from, to, err := sliceBounds(start, end, len(leftType.Value))
+		if err != nil {
+			return err
+		}
+		return nativeStringToObject(leftType.Value[from:to])
+	default:
+		return newError(fmt.Sprintf("slice operator not supported on %s", left.Type()))
+	}
+}
+
+func sliceBounds(start, end object.Object, length int) (int, int, *object.ErrorObject) {
+	bounds := []int64{0, int64(length)}
+	for i, bound := range []object.Object{start, end} {
+		if bound == nil {
+			continue
+		}
+		integerBound, ok := bound.(*object.Integer)
+		if !ok {
+			return 0, 0, newError(fmt.Sprintf("slice bounds should be integer and got %s", bound.Type()))
+		}
+		if integerBound.Big != nil {
+			return 0, 0, newError(fmt.Sprintf("slice bound %s does not fit in 64 bits", integerBound.Inspect()))
+		}
+		bounds[i] = integerBound.Value
+		if bounds[i] < 0 {
+			bounds[i] += int64(length)
+		}
+	}
+	if bounds[0] < 0 || bounds[1] > int64(length) || bounds[0] > bounds[1] {
+		return 0, 0, newError(fmt.Sprintf("slice bounds [%s:%s] out of range with length %d", inspectBound(start), inspectBound(end), length))
+	}
+	return int(bounds[0]), int(bounds[1]), nil
+}
+
+func inspectBound(bound object.Object) string {
+	if bound == nil {
+		return ""
+	}
+	return bound.Inspect()
+}
+
+func callFunction(function object.Object, params []object.Object, module string, position lexer.Position, environment *object.Environment, builtinSymbols *builtin.Builtin) object.Object {
+	var returnValue object.Object
+	var name string
+	switch functionType := function.(type) {
+	case *object.Function:
+		if err := checkArguments(functionType, params); err != nil {
+			return err
+		}
+		returnValue = applyArgumentsToFunctionAndCall(functionType, params, builtinSymbols)
+		name = functionType.Name
+	case *builtin.BuiltinFunction:
+		returnValue = functionType.Function(environment, builtinSymbols, Eval, params...)
+		name = functionType.Name
+	case object.Callable:
+		returnValue = functionType.Call(params...)
+		name = functionType.FunctionName()
+	default:
+		return newError(fmt.Sprintf("expected function %s", function.Inspect()))
+	}
+	if errorObject, ok := returnValue.(*object.ErrorObject); ok {
+		errorObject.AddFrame(name, module, position)
+	}
+	return returnValue
+}
 
+func checkArguments(function *object.Function, params []object.Object) *object.ErrorObject {
 	if len(params) > len(function.Params) {
 		return newError(fmt.Sprintf("this function takes at least %d arguments (%d given)", len(function.Params), len(params)))
 	}
-	newEnvironment := applyArguments(function, params)
-	if len(function.Params) == len(params) {
+	return nil
+}
+
+func applyArgumentsToFunctionAndCall(function *object.Function, params []object.Object, builtinSymbols *builtin.Builtin) object.Object {
+
+	if err := checkArguments(function, params); err != nil {
+		return err
+	}
+	var tailCall *object.TailCall
+	for {
+		if len(function.Params) != len(params) {
+			return function.Partial(params)
+		}
+		newEnvironment := applyArguments(function, params)
 		returnValue := Eval(function.Body, newEnvironment, builtinSymbols)
-		if returnValue.Type() == object.RETURN_OBJ {
-			return returnValue.(*object.ReturnObject).Value
+		if returnValue != nil && returnValue.Type() == object.RETURN_OBJ {
+			returnValue = returnValue.(*object.ReturnObject).Value
+		}
+		if errorObject, ok := returnValue.(*object.ErrorObject); ok && tailCall != nil {
+			errorObject.AddFrame(tailCall.Function.Name, "", tailCall.Position)
+		}
+		nextCall, ok := returnValue.(*object.TailCall)
+		if !ok {
+			return returnValue
 		}
-		return returnValue
+		tailCall = nextCall
+		function, params = tailCall.Function, tailCall.Params
 	}
-	return function.Clone(function.Params[len(params):], newEnvironment)
 }
 
-func applyArguments(function *object.Function, params []object.Object) *object.Environment {
-	newEnvironment := function.Env.ExtendNewEnvironment()
-	for i := 0; i < len(params); i++ {
-		newEnvironment.SetVar(function.Params[i].Value, params[i])
+// evalTail evaluates an expression in return position, a call to a user function there becomes a TailCall
+func evalTail(node ast.Expression, environment *object.Environment, builtinSymbols *builtin.Builtin) object.Object {
+	return withPosition(node, evalTailNode(node, environment, builtinSymbols))
+}
+
+func evalTailNode(node ast.Expression, environment *object.Environment, builtinSymbols *builtin.Builtin) object.Object {
+	switch nodeType := node.(type) {
+	case *ast.CallFunctionExpression:
+		params := evalExpressions(nodeType.Arguments, environment, builtinSymbols)
+		if len(params) == 1 && isError(params[0]) {
+			return params[0]
+		}
+		value := Eval(nodeType.Function, environment, builtinSymbols)
+		if isError(value) {
+			return value
+		}
+		if function, ok := value.(*object.Function); ok && len(function.Params) == len(params) {
+			return &object.TailCall{Function: function, Params: params, Position: nodeType.Position()}
+		}
+		return callFunction(value, params, "", nodeType.Position(), environment, builtinSymbols)
+	case *ast.IfExpression:
+		condition := Eval(nodeType.Condition, environment, builtinSymbols)
+		if isError(condition) {
+			return condition
+		}
+		if evalTruthValue(condition) {
+			return evalTailBlock(nodeType.ConditionalBlock, environment, builtinSymbols)
+		} else if nodeType.AlternativeBlock != nil {
+			return evalTailBlock(nodeType.AlternativeBlock, environment, builtinSymbols)
+		}
+		return nil
+	case *ast.MatchExpression:
+		value := Eval(nodeType.Value, environment, builtinSymbols)
+		if isError(value) {
+			return value
+		}
+		arm, armEnvironment, err := selectMatchArm(nodeType, value, environment, builtinSymbols)
+		if err != nil {
+			return err
+		}
+		if block, ok := arm.Body.(*ast.BlockStatement); ok {
+			return evalTailBlock(block, armEnvironment, builtinSymbols)
+		}
+		return evalTail(arm.Body.(ast.Expression), armEnvironment, builtinSymbols)
 	}
-	return newEnvironment
+	return Eval(node, environment, builtinSymbols)
+}
+
+func evalTailBlock(block *ast.BlockStatement, environment *object.Environment, builtinSymbols *builtin.Builtin) object.Object {
+	if len(block.Statements) == 0 {
+		return nil
+	}
+	last := len(block.Statements) - 1
+	result := evalStatement(block.Statements[:last], environment, builtinSymbols)
+	if result != nil && (result.Type() == object.RETURN_OBJ || result.Type() == object.ERROR_OBJ) {
+		return result
+	}
+	if statement, ok := block.Statements[last].(*ast.ExpressionStatement); ok {
+		return evalTail(statement.Exp, environment, builtinSymbols)
+	}
+	return Eval(block.Statements[last], environment, builtinSymbols)
+}
+
+// forceTailCall runs a tail call that would otherwise escape the construct that evaluated it
+func forceTailCall(value object.Object, builtinSymbols *builtin.Builtin) object.Object {
+	returnValue, ok := value.(*object.ReturnObject)
+	if !ok {
+		return value
+	}
+	tailCall, ok := returnValue.Value.(*object.TailCall)
+	if !ok {
+		return value
+	}
+	result := applyArgumentsToFunctionAndCall(tailCall.Function, tailCall.Params, builtinSymbols)
+	if errorObject, ok := result.(*object.ErrorObject); ok {
+		errorObject.AddFrame(tailCall.Function.Name, "", tailCall.Position)
+		return result
+	}
+	return &object.ReturnObject{Value: result}
+}
+
+func applyArguments(function *object.Function, params []object.Object) *object.Environment {
+	return function.CallEnvironment(params)
 }
 
 func evalExpressions(expressions []ast.Expression, environment *object.Environment, builtinSymbols *builtin.Builtin) []object.Object {
@@ -200,7 +693,7 @@ func evalExpressions(expressions []ast.Expression, environment *object.Environme
 func evalProgram(program *ast.Program, environment *object.Environment, builtinSymbols *builtin.Builtin) object.Object {
 	var result object.Object
 	for _, statement := range program.Statements {
-		result = Eval(statement, environment, builtinSymbols)
+		result = forceTailCall(Eval(statement, environment, builtinSymbols), builtinSymbols)
 		if result == nil {
 			continue
 		}
@@ -220,6 +713,8 @@ func evalTruthValue(value object.Object) bool {
 		return valueType.Value
 	case *object.Integer:
 		return valueType.Value != 0
+	case *object.Float:
+		return valueType.Value != 0
 	default:
 		return false
 	}
@@ -245,6 +740,9 @@ func evalInfixExpression(operator string, rightValue, leftValue object.Object) o
 	if rightValue.Type() == object.INTEGER_OBJ && leftValue.Type() == object.INTEGER_OBJ {
 		return evalIntegerInfixExpression(operator, rightValue, leftValue);
 	}
+	if isNumber(rightValue) && isNumber(leftValue) {
+		return evalFloatInfixExpression(operator, toFloat(rightValue), toFloat(leftValue))
+	}
 	if (rightValue.Type() == object.STRING_OBJ || leftValue.Type() == object.STRING_OBJ) && operator == "+" {
 		return nativeStringToObject(fmt.Sprintf("%s%s", leftValue.Inspect(), rightValue.Inspect()));
 	}
@@ -270,39 +768,142 @@ func nativeStringToObject(text string) object.Object {
 func evalIntegerInfixExpression(operator string, rightValue, leftValue object.Object) object.Object {
 	rightIntegerValue := rightValue.(*object.Integer)
 	leftIntegerValue := leftValue.(*object.Integer)
+	if leftIntegerValue.Big == nil && rightIntegerValue.Big == nil {
+		if value, ok := evalSmallIntegerInfixExpression(operator, rightIntegerValue.Value, leftIntegerValue.Value); ok {
+			return value
+		}
+	}
+	return evalBigIntegerInfixExpression(operator, rightIntegerValue.BigValue(), leftIntegerValue.BigValue())
+}
+
+// evalSmallIntegerInfixExpression returns false when the result overflows an int64 and has to be computed with math/big
+func evalSmallIntegerInfixExpression(operator string, right, left int64) (object.Object, bool) {
+	switch operator {
+	case "+":
+		result := left + right
+		return &object.Integer{Value: result}, (left >= 0) != (right >= 0) || (result >= 0) == (left >= 0)
+	case "-":
+		result := left - right
+		return &object.Integer{Value: result}, (left >= 0) == (right >= 0) || (result >= 0) == (left >= 0)
+	case "/":
+		if right == 0 {
+			return newError("division by zero"), true
+		}
+		return &object.Integer{Value: left / right}, left != math.MinInt64 || right != -1
+	case "*":
+		result := left * right
+		overflow := left != 0 && (result/left != right || (left == -1 && right == math.MinInt64) || (right == -1 && left == math.MinInt64))
+		return &object.Integer{Value: result}, !overflow
+	case "%":
+		if right == 0 {
+			return newError("division by zero"), true
+		}
+		return &object.Integer{Value: left % right}, true
+	case "==":
+		return nativeToBooleanObject(left == right), true
+	case "!=":
+		return nativeToBooleanObject(left != right), true
+	case ">":
+		return nativeToBooleanObject(left > right), true
+	case "<":
+		return nativeToBooleanObject(left < right), true
+	default:
+		return object.NULL, true
+	}
+}
+
+// evalBigIntegerInfixExpression truncates the division like the int64 operators, the result goes back to an int64 when it fits
+func evalBigIntegerInfixExpression(operator string, right, left *big.Int) object.Object {
 	switch operator {
 	case "+":
-		return &object.Integer{Value: leftIntegerValue.Value + rightIntegerValue.Value }
+		return object.NewBigInteger(new(big.Int).Add(left, right))
 	case "-":
-		return &object.Integer{Value: leftIntegerValue.Value - rightIntegerValue.Value }
+		return object.NewBigInteger(new(big.Int).Sub(left, right))
 	case "/":
-		return &object.Integer{Value: leftIntegerValue.Value / rightIntegerValue.Value }
+		if right.Sign() == 0 {
+			return newError("division by zero")
+		}
+		return object.NewBigInteger(new(big.Int).Quo(left, right))
 	case "*":
-		return &object.Integer{Value: leftIntegerValue.Value * rightIntegerValue.Value }
+		return object.NewBigInteger(new(big.Int).Mul(left, right))
 	case "%":
-		return &object.Integer{Value: leftIntegerValue.Value % rightIntegerValue.Value }
+		if right.Sign() == 0 {
+			return newError("division by zero")
+		}
+		return object.NewBigInteger(new(big.Int).Rem(left, right))
 	case "==":
-		return nativeToBooleanObject(leftIntegerValue.Value == rightIntegerValue.Value)
+		return nativeToBooleanObject(left.Cmp(right) == 0)
 	case "!=":
-		return nativeToBooleanObject(leftIntegerValue.Value != rightIntegerValue.Value)
+		return nativeToBooleanObject(left.Cmp(right) != 0)
 	case ">":
-		return nativeToBooleanObject(leftIntegerValue.Value > rightIntegerValue.Value)
+		return nativeToBooleanObject(left.Cmp(right) > 0)
 	case "<":
-		return nativeToBooleanObject(leftIntegerValue.Value < rightIntegerValue.Value)
+		return nativeToBooleanObject(left.Cmp(right) < 0)
 	default:
 		return object.NULL
 	}
 }
+
+func evalFloatInfixExpression(operator string, rightValue, leftValue float64) object.Object {
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftValue + rightValue}
+	case "-":
+		return &object.Float{Value: leftValue - rightValue}
+	case "/":
+		return &object.Float{Value: leftValue / rightValue}
+	case "*":
+		return &object.Float{Value: leftValue * rightValue}
+	case "%":
+		return &object.Float{Value: math.Mod(leftValue, rightValue)}
+	case "==":
+		return nativeToBooleanObject(leftValue == rightValue)
+	case "!=":
+		return nativeToBooleanObject(leftValue != rightValue)
+	case ">":
+		return nativeToBooleanObject(leftValue > rightValue)
+	case "<":
+		return nativeToBooleanObject(leftValue < rightValue)
+	default:
+		return object.NULL
+	}
+}
+
+func isNumber(value object.Object) bool {
+	return value.Type() == object.INTEGER_OBJ || value.Type() == object.FLOAT_OBJ
+}
+
+func toFloat(value object.Object) float64 {
+	switch valueType := value.(type) {
+	case *object.Integer:
+		if valueType.Big != nil {
+			value, _ := new(big.Float).SetInt(valueType.Big).Float64()
+			return value
+		}
+		return float64(valueType.Value)
+	case *object.Float:
+		return valueType.Value
+	default:
+		return 0
+	}
+}
+
 func isError(error object.Object) bool {
 	return error != nil && error.Type() == object.ERROR_OBJ
 }
 
 func evalMinusOperator(rightValue object.Object) object.Object {
-	if rightValue.Type() != object.INTEGER_OBJ {
+	switch value := rightValue.(type) {
+	case *object.Integer:
+		if value.Big != nil || value.Value == math.MinInt64 {
+			return object.NewBigInteger(new(big.Int).Neg(value.BigValue()))
+		}
+		return &object.Integer{Value: -value.Value}
+	case *object.Float:
+		return &object.Float{Value: -value.Value}
+	default:
 		return &object.ErrorObject{Error: fmt.Sprintf("unknow operator for -%s", rightValue.Inspect())}
 	}
-	value := rightValue.(*object.Integer)
-	return &object.Integer{Value: -value.Value}
 }
 
 func evalBangOperator(rightValue object.Object) object.Object {
@@ -310,6 +911,10 @@ func evalBangOperator(rightValue object.Object) object.Object {
 	if ok && integerObject.Value == 0 {
 		return object.TRUE
 	}
+	floatObject, ok := rightValue.(*object.Float)
+	if ok && floatObject.Value == 0 {
+		return object.TRUE
+	}
 	switch rightValue {
 	case object.TRUE:
 		return object.FALSE
@@ -9,63 +9,71 @@ import (
 	"io/ioutil"
 	"bytes"
 	"os"
+	"runtime/debug"
+	"rootlang/resolver"
+	"rootlang/optimizer"
+	"strings"
+	"rootlang/evaluator/evaluatortest"
 )
 
 func TestIntegerEvaluator(t *testing.T) {
-	input := `5`
-	l := lexer.New(input)
-	programParser := parser.New(l)
-	program := programParser.ParseProgram()
-	returnValue := Eval(program, object.NewEnvironment(), builtin.New())
-	objectInteger, ok := returnValue.(*object.Integer)
-	if !ok {
-		t.Error("should return object integer")
-		return
-	}
-	if objectInteger.Value != 5 {
-		t.Error("should be has 5 integer value")
-		return
+	evalCases(t, evaluatortest.Integers, builtin.New(), object.INTEGER_OBJ)
+}
+
+func TestBooleanEvaluator(t *testing.T) {
+	evalCases(t, evaluatortest.Booleans, builtin.New(), object.BOOLEAN_OBJ)
+}
+
+func TestArithmeticExpression(t *testing.T) {
+	evalCases(t, evaluatortest.Arithmetic, builtin.New(), "")
+	shrunk := Eval(parser.New(lexer.New(`(9223372036854775807 + 1) - 1`)).ParseProgram(), object.NewEnvironment(), builtin.New())
+	if integer, ok := shrunk.(*object.Integer); !ok || integer.Big != nil || integer.Value != 9223372036854775807 {
+		t.Errorf("the result should go back to an int64 and got %#v", shrunk)
 	}
 }
 
-func TestFalseBooleanEvaluator(t *testing.T) {
-	input := `false`
-	l := lexer.New(input)
-	programParser := parser.New(l)
-	program := programParser.ParseProgram()
-	returnValue := Eval(program, object.NewEnvironment(), builtin.New())
-	objectBoolean, ok := returnValue.(*object.Boolean)
-	if !ok {
-		t.Error("should return object boolean")
-		return
+func TestFloatExpressionEvaluator(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"3.14", 3.14},
+		{"-2.5", -2.5},
+		{"1.5 + 1", 2.5},
+		{"1 + 1.5", 2.5},
+		{"10 / 4.0", 2.5},
+		{"2.5 * 2", 5},
+		{"7.5 % 2", 1.5},
+		{"1e-3 * 1000", 1},
 	}
-	if objectBoolean.Value {
-		t.Error("should be has false boolean value")
-		return
+	for _, test := range tests {
+		l := lexer.New(test.input)
+		programParser := parser.New(l)
+		program := programParser.ParseProgram()
+		returnValue := Eval(program, object.NewEnvironment(), builtin.New())
+		objectFloat, ok := returnValue.(*object.Float)
+		if !ok {
+			t.Errorf("should return float object %s", test.input)
+			return
+		}
+		if test.expected != objectFloat.Value {
+			t.Errorf("should has %f and got %f %s", test.expected, objectFloat.Value, test.input)
+			return
+		}
 	}
 }
 
-func TestBooleanEvaluator(t *testing.T) {
+func TestNumberComparisonEvaluator(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected bool
 	}{
-		{"!true", false},
-		{"!false", true},
-		{"!5", false},
-		{"!!true", true},
-		{"!!false", false},
-		{"!!5", true},
-		{"!0", true},
-		{"5 == 5", true},
-		{"5 > 5", false},
-		{"6 != 5", true},
-		{"5 != 5", false},
-		{"2 < 3", true},
-		{"2 > 3", false},
-		{"(2 > 3) == true", false},
-		{"(2 < 3) == true", true},
-
+		{"1 == 1.0", true},
+		{"1.5 > 1", true},
+		{"2 < 1.5", false},
+		{"0.1 + 0.2 != 0.3", true},
+		{"!0.0", true},
+		{"0xFF == 255", true},
 	}
 	for _, test := range tests {
 		l := lexer.New(test.input)
@@ -82,21 +90,20 @@ func TestBooleanEvaluator(t *testing.T) {
 			return
 		}
 	}
+}
 
+func TestIfExpressionEvaluator(t *testing.T) {
+	evalCases(t, evaluatortest.Ifs, builtin.New(), object.INTEGER_OBJ)
 }
 
-func TestIntegerExpressionEvaluator(t *testing.T) {
+func TestReturnExpressionEvaluator(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected int64
 	}{
-		{"5", 5},
-		{"-10", -10},
-		{"-10 + 5", -5},
-		{"10 + 5", 15},
-		{"-10 - 5", -15},
-		{"10 * 5", 50},
-		{"10 * -6", -60},
+		{"9;return 20;10", 20},
+		{"return 9;return 20;10", 9},
+		{"if (20>1){if(20>1){return 11;} return 12;}", 11},
 	}
 	for _, test := range tests {
 		l := lexer.New(test.input)
@@ -105,7 +112,7 @@ func TestIntegerExpressionEvaluator(t *testing.T) {
 		returnValue := Eval(program, object.NewEnvironment(), builtin.New())
 		objectInteger, ok := returnValue.(*object.Integer)
 		if !ok {
-			t.Error("should return integer object")
+			t.Errorf("should return integer object %s", test.input)
 			return
 		}
 		if test.expected != objectInteger.Value {
@@ -116,76 +123,229 @@ func TestIntegerExpressionEvaluator(t *testing.T) {
 
 }
 
-func TestIfExpressionEvaluator(t *testing.T) {
+func TestErrorExpression(t *testing.T) {
+	evalCases(t, evaluatortest.Errors, builtin.New(), "")
+}
+
+func TestComposeExpression(t *testing.T) {
 	tests := []struct {
-		input    string
-		expected interface{}
+		input string
+		value string
 	}{
-		{"if(true){20}", 20},
-		{"if(false){20}", nil},
-		{"if(false){20}else{10}", 10},
-		{"if(true){}else{10}", nil},
-		{"if(2<3){40+20}else{10}", 60},
-		{"if(2>3){40+20}else{50}", 50},
+		{`let inc = x => x + 1; let double = x => x * 2; (inc . double)(5)`, "11"},
+		{`let inc = x => x + 1; let double = x => x * 2; (double . inc)(5)`, "12"},
+		{`let inc = x => x + 1; let h = inc . inc . inc; h(0)`, "3"},
+		{`let add = (x, y) => x + y; let double = x => x * 2; let h = double . add; h(1, 2)`, "6"},
+		{`let add = (x, y) => x + y; let double = x => x * 2; let h = double . add(10); h(1)`, "22"},
+		{`let add = (x, y) => x + y; let h = add(1) . len; h("abc")`, "4"},
+		{`let double = x => x * 2; map(double . len, ["a", "abc"])`, "[2,6]"},
+		{`let is_even = x => x % 2 == 0; filter(is_even . len, ["a", "ab", "abcd"])`, "[ab,abcd]"},
+		{`let f = x => x; f . if (false) { 1; }`, "1:19: compose operator expected functions and got FUNCTION . NULL"},
+		{`let f = x => x; if (false) { 1; } . f`, "1:35: compose operator expected functions and got NULL . FUNCTION"},
 	}
 	for _, test := range tests {
 		l := lexer.New(test.input)
 		programParser := parser.New(l)
 		program := programParser.ParseProgram()
 		returnValue := Eval(program, object.NewEnvironment(), builtin.New())
-		if test.expected == nil && returnValue == nil {
-			continue
+		if returnValue == nil {
+			t.Errorf("should return a value %s", test.input)
+			return
 		}
-		objectInteger, ok := returnValue.(*object.Integer)
+		if test.value != returnValue.Inspect() {
+			t.Errorf("should have %s and got %s %s", test.value, returnValue.Inspect(), test.input)
+			return
+		}
+	}
+}
 
-		if !ok {
-			t.Errorf("should return integer object %s", test.input)
+func TestComposeMissingOperand(t *testing.T) {
+	for _, input := range []string{"let f = x => x; f .", "1."} {
+		p := parser.New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.GetErrors()) == 0 {
+			t.Errorf("%s: a parser error is expected", input)
+		}
+	}
+	function := Eval(parser.New(lexer.New(`x => x`)).ParseProgram(), object.NewEnvironment(), builtin.New())
+	for _, operands := range [][2]object.Object{{function, nil}, {nil, function}, {&object.Integer{Value: 1}, nil}} {
+		returnValue := Compose(operands[0], operands[1], lexer.Position{})
+		if _, ok := returnValue.(*object.ErrorObject); !ok {
+			t.Errorf("compose of %v should return an error and got %v", operands, returnValue)
+		}
+	}
+}
+
+func TestPipeExpression(t *testing.T) {
+	tests := []struct {
+		input string
+		value string
+	}{
+		{`[1, 2, 3] |> map(x => x * 2)`, "[2,4,6]"},
+		{`[1, 2, 3, 4] |> map(x => x * 2) |> filter(x => x > 4) |> reduce((x, y) => x + y)`, "14"},
+		{`let add = (x, y) => x + y; 5 |> add(1)`, "6"},
+		{`let inc = x => x + 1; 5 |> inc |> inc`, "7"},
+		{`"abc" |> len`, "3"},
+		{`let double = x => x * 2; "abc" |> double . len`, "6"},
+		{`"hola" |> bytes::create_writer`, "4"},
+		{`"hola" |> bytes::create_writer("ab")`, "6"},
+	}
+	for _, test := range tests {
+		l := lexer.New(test.input)
+		programParser := parser.New(l)
+		program := programParser.ParseProgram()
+		returnValue := Eval(program, object.NewEnvironment(), builtin.New())
+		if returnValue == nil {
+			t.Errorf("should return a value %s", test.input)
 			return
 		}
-		if int64(test.expected.(int)) != objectInteger.Value {
-			t.Errorf("should has %d and got %d %s", test.expected, objectInteger.Value, test.input)
+		if test.value != returnValue.Inspect() {
+			t.Errorf("should have %s and got %s %s", test.value, returnValue.Inspect(), test.input)
 			return
 		}
 	}
+}
+
+func TestSequences(t *testing.T) {
+	tests := []struct {
+		input string
+		value string
+	}{
+		{`collect(range(0, 5))`, "[0,1,2,3,4]"},
+		{`collect(range(10, 0, -3))`, "[10,7,4,1]"},
+		{`range(1) |> map(x => x * x) |> filter(x => x % 2 == 1) |> take(4) |> collect`, "[1,9,25,49]"},
+		{`iterate(x => x * 2, 1) |> drop(3) |> take(3) |> collect`, "[8,16,32]"},
+		{`iterate(x => x + 1, 0) |> take_while(x => x < 4) |> collect`, "[0,1,2,3]"},
+		{`collect(zip(range(1), repeat("a"), [true, false]))`, "[[1,a,true],[2,a,false]]"},
+		{`collect(repeat(0, 3))`, "[0,0,0]"},
+		{`range(0, 100000) |> filter(x => x % 3 == 0) |> reduce((x, y) => x + y)`, "1666683333"},
+		{`collect(map(x => x + 1, [1, 2], range(10, 12)))`, "[2,3,11,12]"},
+		{`let s = range(0, 3); [collect(s), collect(s)]`, "[[0,1,2],[0,1,2]]"},
+		{`let s = map(x => 10 / x, range(-1, 2)); collect(take(2, s))`, "1:21: division by zero"},
+		{`collect(map(x => 10 / x, range(-1, 2)))`, "1:21: division by zero"},
+		{`reduce((x, y) => x + y, range(0, 0))`, "1:1: you provide empty list and not initial value, please dont be a fucking ass hole"},
+		{`range(0, 10, 0)`, "1:1: range step can not be 0"},
+		{`take(9223372036854775807 + 1, range(0))`, "1:1: take count 9223372036854775808 does not fit in 64 bits"},
+		{`drop(-9223372036854775807 - 2, [1])`, "1:1: drop count -9223372036854775809 does not fit in 64 bits"},
+		{`repeat(1, 9223372036854775807 * 2)`, "1:1: repeat count 18446744073709551614 does not fit in 64 bits"},
+		{`collect(range(9223372036854775806, 9223372036854775807, 2))`, "[9223372036854775806]"},
+		{`collect(range(-9223372036854775807, -9223372036854775807 - 3, -1))`, "[-9223372036854775807,-9223372036854775808,-9223372036854775809]"},
+		{`range(9223372036854775806) |> take(3) |> collect`, "[9223372036854775806,9223372036854775807,9223372036854775808]"},
+		{`collect(range(9223372036854775807 * 2, 9223372036854775807 * 2 + 2))`, "[18446744073709551614,18446744073709551615]"},
+		{`map(x => 1 / 0, range(0))`, "sequence"},
+	}
+	for _, test := range tests {
+		l := lexer.New(test.input)
+		programParser := parser.New(l)
+		program := programParser.ParseProgram()
+		returnValue := Eval(program, object.NewEnvironment(), builtin.New())
+		if returnValue == nil {
+			t.Errorf("should return a value %s", test.input)
+			continue
+		}
+		if test.value != returnValue.Inspect() {
+			t.Errorf("should have %s and got %s %s", test.value, returnValue.Inspect(), test.input)
+		}
+	}
+}
 
+func TestPipeModuleCall(t *testing.T) {
+	moduleContent := "let add = (x, y) => x + y; let inc = x => x + 1;"
+	modulePath := "/tmp/testPipe.rl"
+	createModule(moduleContent, modulePath)
+	input := `import "testPipe" as test;
+	let x = 10 |> test::add(5) |> test::inc; x`
+	l := lexer.New(input)
+	programParser := parser.New(l)
+	program := programParser.ParseProgram()
+	builtinSymbols := builtin.New()
+	builtinSymbols.RegisterPath("/tmp/")
+	returnValue := Eval(program, object.NewEnvironment(), builtinSymbols)
+	if returnValue == nil || returnValue.Inspect() != "16" {
+		t.Errorf("expected 16 from module pipe and got %v", returnValue)
+		return
+	}
+	os.Remove(modulePath)
 }
 
-func TestReturnExpressionEvaluator(t *testing.T) {
+func TestTryExpression(t *testing.T) {
+	evalCases(t, evaluatortest.Tries, builtin.New(), "")
+}
+
+func TestMatchExpression(t *testing.T) {
+	evalCases(t, evaluatortest.Matches, builtin.New(), "")
+}
+
+func TestTailCall(t *testing.T) {
 	tests := []struct {
-		input    string
-		expected int64
+		input string
+		value string
 	}{
-		{"9;return 20;10", 20},
-		{"return 9;return 20;10", 9},
-		{"if (20>1){if(20>1){return 11;} return 12;}", 11},
+		{`let count = (n, acc) => { if (n == 0) { return acc; } return count(n - 1, acc + 1); }; count(200000, 0)`, "200000"},
+		{`let even = n => if (n == 0) { true; } else { odd(n - 1); }; let odd = n => if (n == 0) { false; } else { even(n - 1); }; even(200001)`, "false"},
+		{`let sum = (xs, acc) => match xs { [] => acc, [x, ...rest] => sum(rest, acc + x) }; sum([1, 2, 3, 4], 0)`, "10"},
+		{`let count = (n, acc) => if (n == 0) { acc; } else { count(n - 1, acc + 1); }; reduce((acc, x) => count(x, acc), [100000, 100000], 0)`, "200000"},
+		{`let count = (n, acc) => if (n == 0) { acc; } else { count(n - 1, acc + 1); }; let add = count(200000); add(1)`, "200001"},
+		{`let f = n => if (n == 0) { 10 / n; } else { f(n - 1); }; try { f(200000); } catch (e) { error_message(e); }`, "division by zero"},
+		{`let f = x => { return x + 1; }; return f(1);`, "2"},
 	}
+	defer debug.SetMaxStack(debug.SetMaxStack(8 << 20))
 	for _, test := range tests {
 		l := lexer.New(test.input)
 		programParser := parser.New(l)
 		program := programParser.ParseProgram()
 		returnValue := Eval(program, object.NewEnvironment(), builtin.New())
-		objectInteger, ok := returnValue.(*object.Integer)
-		if !ok {
-			t.Errorf("should return integer object %s", test.input)
+		if returnValue == nil {
+			t.Errorf("should return a value %s", test.input)
 			return
 		}
-		if test.expected != objectInteger.Value {
-			t.Errorf("should has %d and got %d %s", test.expected, objectInteger.Value, test.input)
+		if test.value != returnValue.Inspect() {
+			t.Errorf("should have %s and got %s %s", test.value, returnValue.Inspect(), test.input)
 			return
 		}
 	}
+}
 
+func TestTailCallStackTrace(t *testing.T) {
+	input := `let fail = n => {
+  return 10 / n;
+};
+let loop = n => if (n == 0) { fail(n); } else { loop(n - 1); };
+let main = () => {
+  return loop(3) + 1;
+};`
+	expectedTrace := "  at fail (main.rl:2:13)\n" +
+		"  at loop (main.rl:4:31)\n" +
+		"  at main (main.rl:6:10)\n"
+	l := lexer.NewWithFile(input, "main.rl")
+	programParser := parser.New(l)
+	program := programParser.ParseProgram()
+	environment := object.NewEnvironment()
+	builtinSymbols := builtin.New()
+	Eval(program, environment, builtinSymbols)
+	mainFunction, _ := environment.GetVar("main")
+	returnValue := CallMainFunction(mainFunction.(*object.Function), builtinSymbols)
+	errorObject, ok := returnValue.(*object.ErrorObject)
+	if !ok {
+		t.Error("should return error object")
+		return
+	}
+	if errorObject.StackTrace() != expectedTrace {
+		t.Errorf("stack trace expected\n%s and got\n%s", expectedTrace, errorObject.StackTrace())
+	}
 }
 
-func TestErrorExpression(t *testing.T) {
+func TestErrorPosition(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected string
 	}{
-		{"false + false;return 1 + 1;10", "unknow operator for false + false"},
+		{"let x = 5;\nlet y = x + z;", "main.rl:2:13: z was not declare"},
+		{"let f = (a) => {\n  return a / 0;\n};\nf(1);", "main.rl:2:12: division by zero"},
+		{"let x = 5;\n  len(x);", "main.rl:2:3: expected string, list or dict type and got INTEGER"},
 	}
 	for _, test := range tests {
-		l := lexer.New(test.input)
+		l := lexer.NewWithFile(test.input, "main.rl")
 		programParser := parser.New(l)
 		program := programParser.ParseProgram()
 		returnValue := Eval(program, object.NewEnvironment(), builtin.New())
@@ -194,8 +354,8 @@ func TestErrorExpression(t *testing.T) {
 			t.Errorf("should return error object %s", test.input)
 			return
 		}
-		if test.expected != errorObject.Error {
-			t.Errorf("should has %d and got %d %s", test.expected, errorObject.Error, test.input)
+		if test.expected != errorObject.Inspect() {
+			t.Errorf("should has %s and got %s", test.expected, errorObject.Inspect())
 			return
 		}
 	}
@@ -287,83 +447,34 @@ func TestFunctionExpression(t *testing.T) {
 }
 
 func TestFunctionCallExpression(t *testing.T) {
-	tests := []struct {
-		input string
-		value int64
-	}{
-		{"((x,y)=>{return x+y;})(10, 5);", 15},
-		{"let add = (x,y)=>{return x+y;}; add(5,10);", 15},
-		{"let x = 10;let add = (x,y)=>{return x+y;}; add(5,10);", 15},
-		{"let z = 10;let add = (x,y)=>{return x+y+z;}; add(5,10);", 25},
-		{"let z = (x,y)=>{let w = ()=>{return x+y;};return w;}; let b= z(10, 15); b();", 25},
-		{"let z = (x,y)=>{ return x + y;}; let b= ()=>{return 2;}; z(23, b());", 25},
-	}
-	for _, test := range tests {
-		l := lexer.New(test.input)
-		programParser := parser.New(l)
-		program := programParser.ParseProgram()
-		returnValue := Eval(program, object.NewEnvironment(), builtin.New())
-		integerObject, ok := returnValue.(*object.Integer)
-		if !ok {
-			t.Errorf("should Integer object %s", test.input)
-			return
-		}
-		if test.value != integerObject.Value {
-			t.Errorf("should have %d and got %d %s", test.value, integerObject.Value, test.input)
-			return
-		}
-	}
-
+	evalCases(t, evaluatortest.FunctionCalls, builtin.New(), object.INTEGER_OBJ)
 }
 
 func TestStringObject(t *testing.T) {
-	tests := []struct {
-		input string
-		value string
-	}{
-		{`"carlos viera"`, "carlos viera"},
-		{`"carlos viera" + " hola mundo"`, "carlos viera hola mundo"},
-		{`"carlos viera " + (3+5)`, "carlos viera 8"},
-	}
-	for _, test := range tests {
-		l := lexer.New(test.input)
-		programParser := parser.New(l)
-		program := programParser.ParseProgram()
-		returnValue := Eval(program, object.NewEnvironment(), builtin.New())
-		stringObject, ok := returnValue.(*object.String)
-		if !ok {
-			t.Errorf("should String object %s", test.input)
-			return
-		}
-		if test.value != stringObject.Value {
-			t.Errorf("should have %s and got %s %s", test.value, stringObject.Value, test.input)
-			return
-		}
-	}
+	evalCases(t, evaluatortest.Strings, builtin.New(), object.STRING_OBJ)
+}
+
+func TestDictObject(t *testing.T) {
+	evalCases(t, evaluatortest.Dicts, builtin.New(), "")
+}
+
+func TestListIndexAndSlice(t *testing.T) {
+	evalCases(t, evaluatortest.Lists, builtin.New(), "")
 }
 
 func TestClosure(t *testing.T) {
-	tests := []struct {
-		input string
-		value int64
-	}{
-		{`let test = (x,y)=>{return x+y;};let test1 = test(1);test1(2);`, 3},
-	}
-	for _, test := range tests {
-		l := lexer.New(test.input)
-		programParser := parser.New(l)
-		program := programParser.ParseProgram()
-		returnValue := Eval(program, object.NewEnvironment(), builtin.New())
-		integerObject, ok := returnValue.(*object.Integer)
-		if !ok {
-			t.Errorf("should Integer object %s", test.input)
-			return
-		}
-		if test.value != integerObject.Value {
-			t.Errorf("should have %d and got %d %s", test.value, integerObject.Value, test.input)
-			return
-		}
+	evalCases(t, evaluatortest.Closures, builtin.New(), "")
+}
+
+func TestModuleCases(t *testing.T) {
+	remove, err := evaluatortest.WriteModules("/tmp/")
+	if err != nil {
+		t.Fatal(err)
 	}
+	defer remove()
+	builtinSymbols := builtin.New()
+	builtinSymbols.RegisterPath("/tmp/")
+	evalCases(t, evaluatortest.Modules, builtinSymbols, "")
 }
 
 func TestImportModule(t *testing.T) {
@@ -433,6 +544,252 @@ func TestImportModule(t *testing.T) {
 	os.Remove(modulePath)
 }
 
+func TestModuleCache(t *testing.T) {
+	modules := map[string]string{
+		"/tmp/testShared.rl":   `let values = [1, 2];`,
+		"/tmp/testUser.rl":     `import "testShared" as shared; let values = shared::values;`,
+		"/tmp/testCycleA.rl":   `import "testCycleB" as b; let a = 1;`,
+		"/tmp/testCycleB.rl":   `import "testCycleC" as c; let b = 1;`,
+		"/tmp/testCycleC.rl":   `let c = () => { import "testCycleA" as a; a::a; }; let value = c();`,
+	}
+	for path, content := range modules {
+		createModule(content, path)
+		defer os.Remove(path)
+	}
+	input := `import "testShared" as first; import "testUser" as user; import "testShared" as second; [first, user, second]`
+	builtinSymbols := builtin.New()
+	builtinSymbols.RegisterPath("/tmp/")
+	returnValue := Eval(parser.New(lexer.New(input)).ParseProgram(), object.NewEnvironment(), builtinSymbols)
+	list, ok := returnValue.(*object.List)
+	if !ok {
+		t.Fatalf("expected a list of modules and got %s", returnValue.Inspect())
+	}
+	first, user, second := list.Get(0).(*object.Module), list.Get(1).(*object.Module), list.Get(2).(*object.Module)
+	if first.Env != second.Env || first.Name != "first" || second.Name != "second" {
+		t.Errorf("importing a module twice should share its environment")
+	}
+	firstValues, _ := first.Env.GetVar("values")
+	userValues, _ := user.Env.GetVar("values")
+	if firstValues != userValues {
+		t.Errorf("a module imported by another module should be the same module")
+	}
+	returnValue = Eval(parser.New(lexer.New(`import "testCycleA" as a;`)).ParseProgram(), object.NewEnvironment(), builtinSymbols)
+	expected := "import cycle /tmp/testCycleA.rl -> /tmp/testCycleB.rl -> /tmp/testCycleC.rl -> /tmp/testCycleA.rl"
+	if returnValue == nil || returnValue.Type() != object.ERROR_OBJ || !strings.HasSuffix(returnValue.Inspect(), expected) {
+		t.Errorf("expected error %s and got %v", expected, returnValue)
+	}
+	returnValue = Eval(parser.New(lexer.New(`import "testShared" as again; again::values`)).ParseProgram(), object.NewEnvironment(), builtinSymbols)
+	if returnValue != firstValues {
+		t.Errorf("the failed cycle should not stop later imports and got %v", returnValue)
+	}
+}
+
+func TestModuleSearchPaths(t *testing.T) {
+	os.MkdirAll("/tmp/testSearch/lib", 0755)
+	os.MkdirAll("/tmp/testSearch/other", 0755)
+	defer os.RemoveAll("/tmp/testSearch")
+	createModule(`import "./lib/helper" as helper; let value = helper::value;`, "/tmp/testSearch/app.rl")
+	createModule(`import "../other/deep" as deep; let value = deep::value + 1;`, "/tmp/testSearch/lib/helper.rl")
+	createModule(`let value = 41;`, "/tmp/testSearch/other/deep.rl")
+	createModule(`let value = "first";`, "/tmp/testSearch/lib/found.rl")
+	createModule(`let value = "second";`, "/tmp/testSearch/other/found.rl")
+	builtinSymbols := builtin.New()
+	builtinSymbols.RegisterPath("/tmp/testSearch/lib")
+	builtinSymbols.RegisterPath("/tmp/testSearch/other")
+	builtinSymbols.RegisterPath("/tmp/testSearch")
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "app" as app; app::value`, "42"},
+		{`import "found" as found; found::value`, "first"},
+		{`import "deep" as deep; deep::value`, "41"},
+		{`import "missing" as missing;`, "not module missing found, tried /tmp/testSearch/lib/missing.rl, /tmp/testSearch/other/missing.rl, /tmp/testSearch/missing.rl"},
+		{`import "./missing" as missing;`, "not module ./missing found, tried /tmp/testSearch/missing.rl"},
+	}
+	for _, test := range tests {
+		program := parser.New(lexer.NewWithFile(test.input, "/tmp/testSearch/main.rl")).ParseProgram()
+		returnValue := Eval(program, object.NewEnvironment(), builtinSymbols)
+		if returnValue == nil || !strings.HasSuffix(returnValue.Inspect(), test.expected) {
+			t.Errorf("expected %s and got %v", test.expected, returnValue)
+		}
+	}
+}
+
+func TestModuleExports(t *testing.T) {
+	modules := map[string]string{
+		"/tmp/testExportLet.rl":  `let helper = x => x * 2; export let double = x => helper(x); let hidden = 1;`,
+		"/tmp/testExportList.rl": `let a = 1; let b = 2; let c = 3; export (a, b);`,
+		"/tmp/testExportNone.rl": `let a = 1; let b = 2;`,
+	}
+	for path, content := range modules {
+		createModule(content, path)
+		defer os.Remove(path)
+	}
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "testExportLet" as m; m::double(4)`, "8"},
+		{`import "testExportLet" as m; 4 |> m::double`, "8"},
+		{`import "testExportLet" as m; m::helper(4)`, "symbol helper is not exported by module m"},
+		{`import "testExportLet" as m; m::hidden`, "symbol hidden is not exported by module m"},
+		{`import "testExportLet" as m; m`, "[double]"},
+		{`import "testExportList" as m; m::a + m::b`, "3"},
+		{`import "testExportList" as m; m::c`, "symbol c is not exported by module m"},
+		{`import "testExportNone" as m; m::a + m::b`, "3"},
+	}
+	builtinSymbols := builtin.New()
+	builtinSymbols.RegisterPath("/tmp/")
+	for _, test := range tests {
+		returnValue := Eval(parser.New(lexer.New(test.input)).ParseProgram(), object.NewEnvironment(), builtinSymbols)
+		if returnValue == nil || !strings.HasSuffix(returnValue.Inspect(), test.expected) {
+			t.Errorf("%s: expected %s and got %v", test.input, test.expected, returnValue)
+		}
+	}
+}
+
+func TestSelectiveImport(t *testing.T) {
+	createModule(`let helper = x => x * 2; export let double = x => helper(x); export let base = 10;`, "/tmp/testSelective.rl")
+	defer os.Remove("/tmp/testSelective.rl")
+	createModule(`let value = "file";`, "/tmp/fs.rl")
+	defer os.Remove("/tmp/fs.rl")
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import { double, base as b } from "testSelective"; double(b)`, "20"},
+		{`import { get_clients, write_to_client as send } from "/net"; [get_clients, send]`, "[get_clients,write_to_client]"},
+		{`let f = () => { import { base } from "testSelective"; base + 1; }; f()`, "11"},
+		{`import { missing } from "/net";`, "symbol missing not found in module net"},
+		{`import { helper } from "testSelective";`, "symbol helper is not exported by module testSelective"},
+		{`import { len } from "len";`, "not module len found, tried /tmp/len.rl"},
+		{`import "/net" as network; network::get_clients`, "get_clients"},
+		{`import "testSelective" as net; net::base`, "10"},
+		{`import "fs" as fs; fs::value`, "file"},
+		{`import { value } from "fs"; value`, "file"},
+		{`import "/fs" as file; file::exists("/tmp/fs.rl")`, "true"},
+	}
+	builtinSymbols := builtin.New()
+	builtinSymbols.RegisterPath("/tmp/")
+	for _, test := range tests {
+		program := parser.New(lexer.New(test.input)).ParseProgram()
+		environment := object.NewEnvironment()
+		if errors := resolver.Resolve(program, environment, builtinSymbols); len(errors) != 0 {
+			t.Fatalf("%s: %v", test.input, errors)
+		}
+		returnValue := Eval(program, environment, builtinSymbols)
+		if returnValue == nil || !strings.HasSuffix(returnValue.Inspect(), test.expected) {
+			t.Errorf("%s: expected %s and got %v", test.input, test.expected, returnValue)
+		}
+	}
+}
+
+func TestNativeModule(t *testing.T) {
+	builtinSymbols := builtin.New()
+	builtinSymbols.RegisterModule("company", map[string]builtin.NativeFunction{
+		"apply_twice": func(caller *builtin.Caller, params ...object.Object) object.Object {
+			if err := builtin.CheckArity("apply_twice", params, 2, 2); err != nil {
+				return err
+			}
+			return caller.Call(params[0], caller.Call(params[0], params[1]))
+		},
+		"greet": func(caller *builtin.Caller, params ...object.Object) object.Object {
+			if err := builtin.CheckTypes("greet", params, object.STRING_OBJ); err != nil {
+				return err
+			}
+			return &object.String{Value: "hello " + params[0].(*object.String).Value}
+		},
+	})
+	builtinSymbols.RegisterFunction("twice", func(caller *builtin.Caller, params ...object.Object) object.Object {
+		return caller.Call(params[0], params[1], params[1])
+	})
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "/company" as company; company::apply_twice(x => x * 3, 2)`, "18"},
+		{`import { greet } from "/company"; greet("rootlang")`, "hello rootlang"},
+		{`import { apply_twice } from "/company"; apply_twice(collect, [1])`, "[1]"},
+		{`twice((x, y) => x + y, 4)`, "8"},
+		{`twice(twice, 4)`, "expected function 4"},
+		{`import "/company" as company; company::greet(1)`, "greet expected STRING as param 1 and got INTEGER"},
+		{`import "/company" as company; company::apply_twice(1)`, "apply_twice expected 2 params and got 1"},
+		{`import "/company" as company; company::apply_twice(x => x / 0, 1)`, "division by zero"},
+	}
+	for _, test := range tests {
+		returnValue := Eval(parser.New(lexer.New(test.input)).ParseProgram(), object.NewEnvironment(), builtinSymbols)
+		if returnValue == nil || !strings.Contains(returnValue.Inspect(), test.expected) {
+			t.Errorf("%s: expected %s and got %v", test.input, test.expected, returnValue)
+		}
+	}
+}
+
+func TestStandardLibrary(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "std/list" as list; [list::reverse([1, 2, 3]), list::sum([1, 2, 3]), [3, 1, 2] |> list::sort]`, "[[3,2,1],6,[1,2,3]]"},
+		{`import "std/list" as list; list::sort_by(x => len(x), ["ccc", "a", "bb", "b"])`, "[a,b,bb,ccc]"},
+		{`import "std/list" as list; [list::find(x => x > 1, 0, [1, 2, 3]), list::find(x => x > 5, 0, [1, 2]), list::all(x => x > 0, [1, 2])]`, "[2,0,true]"},
+		{`import "std/list" as list; [list::unique([1, 2, 1, 3]), list::chunk(2, [1, 2, 3]), list::flatten([[1], [], [2, 3]])]`, "[[1,2,3],[[1,2],[3]],[1,2,3]]"},
+		{`import "std/list" as list; [list::enumerate(["a", "b"]), list::contains("b", ["a", "b"]), list::count(x => x > 1, [1, 2, 3])]`, "[[[0,a],[1,b]],true,2]"},
+		{`import "std/list" as list; list::chunk(0, [1])`, "chunk size should be greater than 0"},
+		{`import "std/strings" as strings; [strings::split(",", "a,b,,c"), strings::join("-", [1, 2]), strings::replace("a", "o", "banana")]`, "[[a,b,,c],1-2,bonono]"},
+		{`import "std/strings" as strings; [strings::starts_with("ab", "abc"), strings::ends_with("abc", "c"), strings::index_of("c", "abc")]`, "[true,false,2]"},
+		{`import "std/strings" as strings; ["[" + strings::trim(" \thi\n ") + "]", strings::pad_left(4, "0", "7"), strings::reverse("abc")]`, "[[hi],0007,cba]"},
+		{`import { flip, compose_all, times, until } from "std/functional"; [flip((a, b) => a - b)(1, 10), compose_all([x => x + 1, x => x * 2])(5), times(3, x => x * 2, 1), until(x => x > 10, x => x * 3, 1)]`, "[9,11,8,27]"},
+		{`import "std/list" as list; list::equal`, "symbol equal is not exported by module list"},
+	}
+	for _, test := range tests {
+		returnValue := Eval(parser.New(lexer.New(test.input)).ParseProgram(), object.NewEnvironment(), builtin.New())
+		if returnValue == nil || !strings.Contains(returnValue.Inspect(), test.expected) {
+			t.Errorf("%s: expected %s and got %v", test.input, test.expected, returnValue)
+		}
+	}
+}
+
+func TestFsModule(t *testing.T) {
+	notes := map[string]string{"notes/a.txt": "one\ntwo\n3"}
+	tests := []struct {
+		files    map[string]string
+		input    string
+		expected string
+	}{
+		{nil, `import "/fs" as fs; fs::mkdir("{dir}/notes"); fs::write_file("{dir}/notes/a.txt", "one\n")`, "4"},
+		{map[string]string{"notes/a.txt": "one\n"}, `import "/fs" as fs; [fs::append_file("{dir}/notes/a.txt", bytes::create_writer("two\n", 3)), fs::read_file("{dir}/notes/a.txt")]`, "[5,one\ntwo\n3]"},
+		{notes, `import "/fs" as fs; fs::read_file("{dir}/notes/a.txt")`, "one\ntwo\n3"},
+		{notes, `import "/fs" as fs; let f = fs::open("{dir}/notes/a.txt"); let line = fs::read_line(f); let rest = bytes::read_string(fs::read(f)); fs::close(f); [line, rest, f]`, "[one\n,two\n3,file({dir}/notes/a.txt)]"},
+		{notes, `import "/fs" as fs; let f = fs::open("{dir}/notes/b.txt", "w"); fs::write(f, "b"); fs::close(f); [fs::exists("{dir}/notes/b.txt"), fs::list_dir("{dir}/notes")]`, "[true,[a.txt,b.txt]]"},
+		{notes, `import "/fs" as fs; let info = fs::stat("{dir}/notes/a.txt"); let notes = fs::stat("{dir}/notes"); [info["name"], info["size"], info["is_dir"], notes["is_dir"]]`, "[a.txt,9,false,true]"},
+		{notes, `import "/fs" as fs; let f = fs::open("{dir}/notes/b.txt", "w"); fs::write(f, "one\ntwo\nthree\n"); fs::close(f); let f = fs::open("{dir}/notes/b.txt", "r+"); fs::read_line(f); fs::write(f, "TWO"); let rest = fs::read_line(f); fs::close(f); [rest, fs::read_file("{dir}/notes/b.txt")]`, "[\n,one\nTWO\nthree\n]"},
+		{map[string]string{"notes/b.txt": "b"}, `import "/fs" as fs; fs::remove("{dir}/notes/b.txt"); fs::exists("{dir}/notes/b.txt")`, "false"},
+		{notes, `import "/fs" as fs; let f = fs::open("{dir}/notes/a.txt"); fs::close(f); fs::read_line(f)`, "read_line the file {dir}/notes/a.txt is closed"},
+		{notes, `import "/fs" as fs; fs::open("{dir}/notes/a.txt", "x")`, "open unknown mode x"},
+		{nil, `import "/fs" as fs; fs::read_file("{dir}/missing.txt")`, "no such file or directory"},
+		{nil, `import "/fs" as fs; fs::write_file("{dir}/c.txt", 1)`, "write_file expected string or writer_buffer and got INTEGER"},
+	}
+	for _, test := range tests {
+		directory, err := ioutil.TempDir("", "rootlangFsTest")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(directory)
+		for name, content := range test.files {
+			path := directory + "/" + name
+			os.MkdirAll(path[:strings.LastIndex(path, "/")], 0755)
+			ioutil.WriteFile(path, []byte(content), 0644)
+		}
+		input := strings.ReplaceAll(test.input, "{dir}", directory)
+		expected := strings.ReplaceAll(test.expected, "{dir}", directory)
+		returnValue := Eval(parser.New(lexer.New(input)).ParseProgram(), object.NewEnvironment(), builtin.New())
+		if returnValue == nil || !strings.Contains(returnValue.Inspect(), expected) {
+			t.Errorf("%s: expected %s and got %v", input, expected, returnValue)
+		}
+	}
+}
+
 func TestModuleCall(t *testing.T) {
 	moduleContent := "let y = 5; let addToX = x=>{return x+y;};"
 	modulePath := "/tmp/testImport.rl"
@@ -514,6 +871,48 @@ func TestModuleCall3(t *testing.T) {
 	os.Remove(modulePath)
 }
 
+func TestStackTrace(t *testing.T) {
+	moduleContent := "let fail = x => {\n  return x / 0;\n};\nlet run = xs => map(fail, xs);"
+	modulePath := "/tmp/testTrace.rl"
+	createModule(moduleContent, modulePath)
+	input := `import "testTrace" as helper;
+let process = (a) => {
+  return helper::run([a]);
+};
+let main = () => {
+  let r = process(1);
+  return r;
+};`
+	expectedTrace := "  at fail (/tmp/testTrace.rl:2:12)\n" +
+		"  at map (native)\n" +
+		"  at helper::run (/tmp/testTrace.rl:4:17)\n" +
+		"  at process (main.rl:3:10)\n" +
+		"  at main (main.rl:6:11)\n"
+	l := lexer.NewWithFile(input, "main.rl")
+	programParser := parser.New(l)
+	program := programParser.ParseProgram()
+	builtinSymbols := builtin.New()
+	builtinSymbols.RegisterPath("/tmp/")
+	environment := object.NewEnvironment()
+	Eval(program, environment, builtinSymbols)
+	mainFunction, _ := environment.GetVar("main")
+	returnValue := CallMainFunction(mainFunction.(*object.Function), builtinSymbols)
+	errorObject, ok := returnValue.(*object.ErrorObject)
+	if !ok {
+		t.Error("should return error object")
+		return
+	}
+	if errorObject.Inspect() != "/tmp/testTrace.rl:2:12: division by zero" {
+		t.Errorf("error expected with position and got %s", errorObject.Inspect())
+		return
+	}
+	if errorObject.StackTrace() != expectedTrace {
+		t.Errorf("stack trace expected\n%s and got\n%s", expectedTrace, errorObject.StackTrace())
+		return
+	}
+	os.Remove(modulePath)
+}
+
 func TestMainModuleModule(t *testing.T) {
 
 	input := `import "/net";
@@ -581,6 +980,143 @@ let main = () => {
 
 }
 
+func TestResolvedProgram(t *testing.T) {
+	moduleContent := "let add = (x, y) => x + y; let scale = 3; let times = x => x * scale;"
+	modulePath := "/tmp/testResolved.rl"
+	createModule(moduleContent, modulePath)
+	defer os.Remove(modulePath)
+	inputs := []string{
+		`let x = 1; let f = () => { let y = x; let x = 2; [y, x]; }; [f(), x]`,
+		`let f = c => { if (c) { let x = 2; } x; }; let x = 1; [f(true), f(false)]`,
+		`let f = () => { let g = () => x; let x = 5; g(); }; f()`,
+		`let counter = () => { let n = 0; let inc = () => { let n = n + 1; n; }; [inc, () => n]; }; let c = counter(); c[0](); c[0](); c[1]()`,
+		`let add = (x, y, z) => x + y + z; let a = add(1); let b = a(2); [b(3), b(4), a(5, 6)]`,
+		`let f = x => { let y = if (false) { 1; }; y; }; f(1)`,
+		`let n = 10; match [1, 2] { [n, m] => { let k = n + m; k; } }`,
+		`let n = 10; match 3 { m if m > n => 1, m => { let n = m * 2; n; } }; n`,
+		`let f = x => try { 10 / x; } catch (e) { let message = error_message(e); message; }; [f(2), f(0)]`,
+		`let e = 5; let f = () => { let e = 6; let v = try { 1 / 0; } catch (e) { let k = e; 0; }; [e, v]; }; let g = try { 1 / 0; } catch (e) { () => error_message(e); }; [e, f(), g()]`,
+		`let inc = x => x + 1; let add = (x, y) => x + y; let h = inc . add(10); [h(1), (inc . len)("ab")]`,
+		`let count = (n, acc) => if (n == 0) { acc; } else { count(n - 1, acc + 1); }; count(10000, 0)`,
+		`let f = (x, x) => x; f(1, 2)`,
+		`let make = x => y => z => [x, y, z]; make(1)(2)(3)`,
+		`import "testResolved" as m; let scale = 100; [m::add(1, 2), m::times(2), 5 |> m::add(1), 4 |> m::times]`,
+		`let f = () => { import "testResolved" as lib; lib::scale; }; f()`,
+		`let xs = [1, 2, 3]; map(x => x * len(xs), xs) |> filter(x => x > 3)`,
+		`let f = x => { return g(x); }; let g = x => x * 2; f(4)`,
+	}
+	for _, input := range inputs {
+		results := make([]string, 2)
+		for i := range results {
+			l := lexer.NewWithFile(input, "main.rl")
+			program := parser.New(l).ParseProgram()
+			environment := object.NewEnvironment()
+			builtinSymbols := builtin.New()
+			builtinSymbols.RegisterPath("/tmp/")
+			if i == 1 {
+				if errors := resolver.Resolve(program, environment, builtinSymbols); len(errors) != 0 {
+					t.Errorf("%s: unexpected errors %v", input, errors)
+				}
+			}
+			if value := Eval(program, environment, builtinSymbols); value != nil {
+				results[i] = value.Inspect()
+			}
+		}
+		if results[0] != results[1] {
+			t.Errorf("%s: expected %s and got %s once resolved", input, results[0], results[1])
+		}
+	}
+}
+
+func TestReplLines(t *testing.T) {
+	lines := []struct {
+		input    string
+		expected string
+	}{
+		{`let even = n => if (n == 0) { true; } else { odd(n - 1); };`, "<nil>"},
+		{`let odd = n => if (n == 0) { false; } else { even(n - 1); };`, "<nil>"},
+		{`[even(10), odd(7), even(3)]`, "[true,true,false]"},
+		{`let later = () => missing;`, "<nil>"},
+		{`later()`, "1:19: missing was not declare"},
+	}
+	environment := object.NewEnvironment()
+	builtinSymbols := builtin.New()
+	for _, line := range lines {
+		program := parser.New(lexer.New(line.input)).ParseProgram()
+		if errors := resolver.ResolveLine(program, environment, builtinSymbols); len(errors) != 0 {
+			t.Fatalf("%s: %v", line.input, errors)
+		}
+		returnValue := Eval(program, environment, builtinSymbols)
+		got := "<nil>"
+		if returnValue != nil {
+			got = returnValue.Inspect()
+		}
+		if got != line.expected {
+			t.Errorf("%s: expected %s and got %s", line.input, line.expected, got)
+		}
+	}
+	if errors := resolver.Resolve(parser.New(lexer.New(`let f = () => unknown;`)).ParseProgram(), environment, builtinSymbols); len(errors) == 0 {
+		t.Errorf("a module should still fail to resolve an undeclared name")
+	}
+}
+
+func TestOptimizedProgram(t *testing.T) {
+	inputs := []string{
+		`let x = 2 * 3 + 1; [x, -x, !x, 7 / 2, 7 % 2, 1 < 2, "a" + "b", "a" != "a", true == !false]`,
+		`1 / 0`,
+		`let f = x => (x + 1) % (2 - 2); f(1)`,
+		`let f = () => { if (true) { let a = 1; } a; }; f()`,
+		`let f = () => { if (false) { 1; } }; f()`,
+		`let f = () => { 5; if (false) { 1; } }; f()`,
+		`let f = c => { if (1) { return 1; } 2; }; f(true)`,
+		`let y = if ("text") { 1; } else { 2; }; y`,
+		`if (0) { 1; }`,
+		`"a" == "a"`,
+		`(x => x)(1 + 2) |> (x => x)`,
+		`(x => x)(1, 2)`,
+		`try { (x => x)(1 / 0); } catch (e) { error_message(e); }`,
+		`let count = (n, acc) => if (n == 0 + 0) { acc; } else { count(n - 1, acc + 1); }; count(10000, 0)`,
+		`match 2 * 2 { n if n > 1 + 1 => n * 10, _ => 0 }`,
+	}
+	for _, input := range inputs {
+		results := make([]string, 2)
+		for i := range results {
+			l := lexer.NewWithFile(input, "main.rl")
+			program := parser.New(l).ParseProgram()
+			if i == 1 {
+				optimizer.Optimize(program)
+			}
+			if value := Eval(program, object.NewEnvironment(), builtin.New()); value != nil {
+				results[i] = value.Inspect()
+			}
+		}
+		if results[0] != results[1] {
+			t.Errorf("%s: expected %s and got %s once optimized", input, results[0], results[1])
+		}
+	}
+}
+
+// evalCases checks the value every program returns, when objectType is set the value has to be of that type
+func evalCases(t *testing.T, cases []evaluatortest.Case, builtinSymbols *builtin.Builtin, objectType object.ObjectType) {
+	for _, test := range cases {
+		program := parser.New(lexer.NewWithFile(test.Input, "main.rl")).ParseProgram()
+		returnValue := Eval(program, object.NewEnvironment(), builtinSymbols)
+		if returnValue == nil {
+			if test.Expected != evaluatortest.Nil {
+				t.Errorf("should return a value %s", test.Input)
+			}
+			continue
+		}
+		if objectType != "" && returnValue.Type() != objectType {
+			t.Errorf("should return %s object and got %s %s", objectType, returnValue.Type(), test.Input)
+			continue
+		}
+		if test.Expected != returnValue.Inspect() {
+			t.Errorf("should have %s and got %s %s", test.Expected, returnValue.Inspect(), test.Input)
+		}
+	}
+}
+
 func createModule(module, path string) {
 	buffer := bytes.NewBufferString(module)
 	ioutil.WriteFile(path, buffer.Bytes(), 0644)
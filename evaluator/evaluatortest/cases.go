@@ -0,0 +1,230 @@
+// Package evaluatortest has the programs the evaluator is tested with so the vm can be checked to return the same
+package evaluatortest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+type Case struct {
+	Input    string
+	Expected string
+}
+
+// Nil is the Expected of a program that returns no value
+const Nil = "<nil>"
+
+var Arithmetic = []Case{
+	{`5`, "5"},
+	{`-10 + 5`, "-5"},
+	{`-10 - 5`, "-15"},
+	{`10 * -6`, "-60"},
+	{`10 / 4`, "2"},
+	{`-7 % 3`, "-1"},
+	{`1.5 + 1`, "2.5"},
+	{`10 / 4.0`, "2.5"},
+	{`7.5 % 2`, "1.5"},
+	{`0xFF + 1`, "256"},
+	{`9223372036854775807 + 1`, "9223372036854775808"},
+	{`-9223372036854775807 - 2`, "-9223372036854775809"},
+	{`let fact = n => if (n < 2) { 1; } else { n * fact(n - 1); }; fact(25)`, "15511210043330985984000000"},
+	{`let x = 4611686018427387904 * 4; [x / 4, x % 1000, -x % 1000, x / -3, x > 1, x < 1, x == 18446744073709551616]`, "[4611686018427387904,616,-616,-6148914691236517205,true,false,true]"},
+	{`let x = 9223372036854775807 * 3; x == x + 0`, "true"},
+	{`let x = 9223372036854775807 * 2; x != 18446744073709551614`, "false"},
+	{`(-9223372036854775807 - 1) / -1`, "9223372036854775808"},
+	{`-(-9223372036854775807 - 1)`, "9223372036854775808"},
+	{`9223372036854775807 * 2 + 0.5`, "1.8446744073709552e+19"},
+	{`(9223372036854775807 * 2) / 0`, "main.rl:1:27: division by zero"},
+	{`let d = {(9223372036854775807 * 2): "big"}; d[18446744073709551614]`, "big"},
+	{`match 9223372036854775807 * 2 { 9223372036854775807 => "small", _ => "big" }`, "big"},
+}
+
+var Integers = []Case{
+	{`5`, "5"},
+	{`-10`, "-10"},
+	{`-10 + 5`, "-5"},
+	{`10 + 5`, "15"},
+	{`-10 - 5`, "-15"},
+	{`10 * 5`, "50"},
+	{`10 * -6`, "-60"},
+}
+
+var Booleans = []Case{
+	{`false`, "false"},
+	{`!true`, "false"},
+	{`!false`, "true"},
+	{`!5`, "false"},
+	{`!!true`, "true"},
+	{`!!false`, "false"},
+	{`!!5`, "true"},
+	{`!0`, "true"},
+	{`5 == 5`, "true"},
+	{`5 > 5`, "false"},
+	{`6 != 5`, "true"},
+	{`5 != 5`, "false"},
+	{`2 < 3`, "true"},
+	{`2 > 3`, "false"},
+	{`(2 > 3) == true`, "false"},
+	{`(2 < 3) == true`, "true"},
+}
+
+var Ifs = []Case{
+	{`if(true){20}`, "20"},
+	{`if(false){20}`, Nil},
+	{`if(false){20}else{10}`, "10"},
+	{`if(true){}else{10}`, Nil},
+	{`if(2<3){40+20}else{10}`, "60"},
+	{`if(2>3){40+20}else{50}`, "50"},
+}
+
+var FunctionCalls = []Case{
+	{`((x,y)=>{return x+y;})(10, 5);`, "15"},
+	{`let add = (x,y)=>{return x+y;}; add(5,10);`, "15"},
+	{`let x = 10;let add = (x,y)=>{return x+y;}; add(5,10);`, "15"},
+	{`let z = 10;let add = (x,y)=>{return x+y+z;}; add(5,10);`, "25"},
+	{`let z = (x,y)=>{let w = ()=>{return x+y;};return w;}; let b= z(10, 15); b();`, "25"},
+	{`let z = (x,y)=>{ return x + y;}; let b= ()=>{return 2;}; z(23, b());`, "25"},
+}
+
+var Strings = []Case{
+	{`"carlos viera"`, "carlos viera"},
+	{`"carlos viera" + " hola mundo"`, "carlos viera hola mundo"},
+	{`"carlos viera " + (3+5)`, "carlos viera 8"},
+}
+
+var Dicts = []Case{
+	{`let d = {"a": 1, "b": 2}; d["b"]`, "2"},
+	{`let d = {1: "one", true: "yes"}; d[1] + d[true]`, "oneyes"},
+	{`let d = {"a": 1}; let e = put(d, "b", 2); len(d) + len(e)`, "3"},
+	{`let d = {"a": 1, "b": 2}; keys(remove(d, "a"))`, "[b]"},
+	{`values({"a": 1, "b": 2})`, "[1,2]"},
+	{`let d = {"a": 1}; has(d, "a") == true`, "true"},
+	{`let d = {"a": 1}; get(d, "z", 0)`, "0"},
+	{`get({}, "z")`, "null"},
+	{`let d = {"a": 1, "b": {"c": 3}}; d`, "{a:1,b:{c:3}}"},
+}
+
+var Lists = []Case{
+	{`[1, 2, 3]`, "[1,2,3]"},
+	{`let xs = [1, 2, 3]; xs[0]`, "1"},
+	{`let xs = [1, 2, 3]; xs[-1]`, "3"},
+	{`let xs = [1, 2, 3, 4]; xs[1:3]`, "[2,3]"},
+	{`let xs = [1, 2, 3, 4]; xs[:-1]`, "[1,2,3]"},
+	{`let xs = [1, 2, 3, 4]; xs[2:]`, "[3,4]"},
+	{`let xs = [1, 2, 3, 4]; xs[:]`, "[1,2,3,4]"},
+	{`let xs = [1, 2, 3, 4]; xs[2:2]`, "[]"},
+	{`"rootlang"[0]`, "r"},
+	{`"rootlang"[-4:]`, "lang"},
+	{`map(x => x * 2, [1, 2])[1]`, "4"},
+}
+
+var Matches = []Case{
+	{`match 1 { 0 => "zero", 1 => "one", _ => "many" }`, "one"},
+	{`match 7 { 0 => "zero", 1 => "one", _ => "many" }`, "many"},
+	{`match -1 { -1 => "minus", _ => "other" }`, "minus"},
+	{`match "pong" { "ping" => 1, "pong" => 2 }`, "2"},
+	{`match 2.0 { 2 => "two", _ => "other" }`, "two"},
+	{`match false { true => 1, false => 0 }`, "0"},
+	{`match 20 { n if n > 10 => n * 2, n => n }`, "40"},
+	{`match 5 { n if n > 10 => n * 2, n => n }`, "5"},
+	{`match [1, 2, 3] { [] => 0, [x, ...rest] => rest }`, "[2,3]"},
+	{`match [1] { [x, ...rest] => len(rest) }`, "0"},
+	{`match [1, 2] { [x] => 1, [x, y] => x + y, _ => 0 }`, "3"},
+	{`match [1, [2, 3]] { [a, [b, c]] => a + b + c }`, "6"},
+	{`match "xs" { [x, ..._] => 1, _ => 0 }`, "0"},
+	{`let sum = xs => match xs { [] => 0, [x, ...rest] => x + sum(rest) }; sum([1, 2, 3, 4])`, "10"},
+	{`let f = x => { match x { 0 => { return "early"; }, _ => 1 }; return "late"; }; f(0)`, "early"},
+	{`let n = 1; match 5 { n => n }; n`, "1"},
+	{`match 3 { 1 => 1 }`, "main.rl:1:1: no pattern matched 3"},
+	{`match 3 { n if n / 0 => 1 }`, "main.rl:1:18: division by zero"},
+}
+
+var Tries = []Case{
+	{`try { 10 / 2; } catch (e) { 0; }`, "5"},
+	{`try { 10 / 0; } catch (e) { 0; }`, "0"},
+	{`try { 10 / 0; } catch (e) { error_message(e); }`, "division by zero"},
+	{`try { 10 / 0; } catch (e) { is_error(e); }`, "true"},
+	{`try { x; } catch { "missing"; }`, "missing"},
+	{`let f = () => { try { return 1; } catch (e) { return 2; }; return 3; }; f()`, "1"},
+	{`let f = x => { return 10 / x; }; map(x => try { f(x); } catch (e) { -1; }, [1, 0, 5])`, "[10,-1,2]"},
+	{`is_error(error("boom"))`, "true"},
+	{`is_error(10)`, "false"},
+	{`error_message(error("boom"))`, "boom"},
+	{`let e = error("boom"); [1, e][1]`, "error(boom)"},
+	{`let e = 5; let v = try { 1 / 0; } catch (e) { 0; }; [e, v]`, "[5,0]"},
+	{`let f = () => { let e = 5; try { 1 / 0; } catch (e) { let m = 1; }; e; }; f()`, "5"},
+	{`try { raise("boom"); } catch (e) { error_message(e); }`, "boom"},
+	{`let check = x => if (x > 0) { x; } else { raise("not positive " + x); }; map(x => try { check(x); } catch (e) { error_message(e); }, [1, -2])`, "[1,not positive -2]"},
+	{`try { try { 1 / 0; } catch (e) { raise(e); }; } catch (e) { "again " + error_message(e); }`, "again division by zero"},
+	{`raise("boom")`, "main.rl:1:1: boom"},
+	{`import "std/list" as list; try { list::chunk(0, [1]); } catch (e) { error_message(e); }`, "chunk size should be greater than 0"},
+}
+
+var Closures = []Case{
+	{`let test = (x,y)=>{return x+y;};let test1 = test(1);test1(2);`, "3"},
+	{`((x,y)=>{return x+y;})(10, 5);`, "15"},
+	{`let z = 10;let add = (x,y)=>{return x+y+z;}; add(5,10);`, "25"},
+	{`let z = (x,y)=>{let w = ()=>{return x+y;};return w;}; let b= z(10, 15); b();`, "25"},
+	{`let newAdder = x => y => x + y; newAdder(2)(3)`, "5"},
+	{`let add = (x, y, z) => x + y + z; let add1 = add(1); let add3 = add1(2); [add1(2, 3), add3(4)]`, "[6,7]"},
+	{`let counter = () => { let n = 0; let inc = () => { let n = n + 1; n; }; [inc, () => n]; }; let c = counter(); [c[0](), c[0](), c[1]()]`, "[1,1,0]"},
+	{`let adders = map(x => y => x + y, [1, 2, 3]); map(f => f(10), adders)`, "[11,12,13]"},
+	{`let add = (x, y) => x + y; let double = x => x * 2; let h = double . add(10); h(1)`, "22"},
+}
+
+var Errors = []Case{
+	{`false + false;return 1 + 1;10`, "main.rl:1:7: unknow operator for false + false"},
+	{`10 / 0`, "main.rl:1:4: division by zero"},
+	{`let d = {"a": 1}; d["b"]`, "main.rl:1:20: key b not found in dict"},
+	{`let d = {(x => x): 1};`, "main.rl:1:9: unusable as dict key FUNCTION"},
+	{`[1, 2, 3][3]`, "main.rl:1:10: index 3 out of range with length 3"},
+	{`[1, 2, 3][-4]`, "main.rl:1:10: index -4 out of range with length 3"},
+	{`[1, 2, 3][2:1]`, "main.rl:1:10: slice bounds [2:1] out of range with length 3"},
+	{`"abc"[:5]`, "main.rl:1:6: slice bounds [:5] out of range with length 3"},
+	{`[1, 2, 3][9223372036854775807 + 1]`, "main.rl:1:10: index 9223372036854775808 does not fit in 64 bits"},
+	{`"abc"[-9223372036854775807 - 2]`, "main.rl:1:6: index -9223372036854775809 does not fit in 64 bits"},
+	{`[1, 2, 3][1:9223372036854775807 * 2]`, "main.rl:1:10: slice bound 18446744073709551614 does not fit in 64 bits"},
+	{`[1, 2, 3]["a"]`, "main.rl:1:10: index should be integer and got STRING"},
+	{`let f = x => x; f . 1`, "main.rl:1:19: compose operator expected functions and got FUNCTION . INTEGER"},
+	{`let f = (a) => { return a / 0; }; f(1);`, "main.rl:1:27: division by zero"},
+	{`let add = (x,y)=>{return x+y;}; add(5, 1, 2)`, "main.rl:1:33: this function takes at least 2 arguments (3 given)"},
+	{`10(1)`, "main.rl:1:1: expected function 10"},
+}
+
+var ModuleFiles = map[string]string{
+	"testCasesMath":   `let helper = x => x * 2; export let double = x => helper(x); export let base = 10;`,
+	"testCasesList":   `let a = 1; let b = 2; let c = 3; export (a, b);`,
+	"testCasesNested": `import "testCasesMath" as math; let value = math::double(math::base);`,
+}
+
+var Modules = []Case{
+	{`import "testCasesMath" as m; m::double(4)`, "8"},
+	{`import "testCasesMath" as m; 4 |> m::double`, "8"},
+	{`import "testCasesMath" as m; m::helper(4)`, "main.rl:1:31: symbol helper is not exported by module m"},
+	{`import "testCasesList" as m; m::a + m::b`, "3"},
+	{`import "testCasesList" as m; m::c`, "main.rl:1:31: symbol c is not exported by module m"},
+	{`import "testCasesNested" as nested; nested::value`, "20"},
+	{`import { double, base as b } from "testCasesMath"; double(b)`, "20"},
+	{`let f = () => { import { base } from "testCasesMath"; base + 1; }; f()`, "11"},
+	{`import "testCasesMissing" as m;`, "main.rl:1:1: not module testCasesMissing found, tried /tmp/testCasesMissing.rl"},
+}
+
+// WriteModules writes the modules imported by the Modules cases into the directory and returns a function removing them
+func WriteModules(dir string) (func(), error) {
+	var paths []string
+	remove := func() {
+		for _, path := range paths {
+			os.Remove(path)
+		}
+	}
+	for name, content := range ModuleFiles {
+		path := filepath.Join(dir, name+".rl")
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			remove()
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return remove, nil
+}
@@ -5,58 +5,132 @@ import (
 	"rootlang/ast"
 	"rootlang/builtin"
 	"os"
-	"path"
+	"path/filepath"
 	"fmt"
 	"io/ioutil"
 	"rootlang/lexer"
 	"rootlang/parser"
+	"rootlang/resolver"
+	"rootlang/optimizer"
+	"rootlang/std"
 	"strings"
 	"errors"
 )
 
-func ReadPrincipalModule(pathModule string) (*object.Environment, error) {
-	builtinSymbols := builtin.New()
+// ReadPrincipalModule evaluates the module the program starts from, builtinSymbols keeps the modules it imports so the
+// functions of the program share them when they run
+func ReadPrincipalModule(pathModule string, builtinSymbols *builtin.Builtin) (*object.Environment, error) {
 	newEnvironment := object.NewEnvironment()
 	moduleContent, err := readModuleFile(pathModule)
 	if err != nil {
 		return nil, err
 	}
-	l := lexer.New(moduleContent)
+	canonical := canonicalPath(pathModule)
+	builtinSymbols.StartLoading(canonical)
+	defer builtinSymbols.EndLoading()
+	l := lexer.NewWithFile(moduleContent, pathModule)
 	p := parser.New(l)
 	program := p.ParseProgram()
 	if len(p.GetErrors()) != 0 {
-		return nil, err
+		return nil, errors.New(strings.Join(p.GetErrors(), "\n"))
+	}
+	optimizer.Optimize(program)
+	if resolveErrors := resolver.Resolve(program, newEnvironment, builtinSymbols); len(resolveErrors) != 0 {
+		return nil, errors.New(strings.Join(resolveErrors, "\n"))
 	}
 	evalResult := Eval(program, newEnvironment, builtinSymbols)
 	if evalResult != nil && evalResult.Type() == object.ERROR_OBJ {
 		return nil, errors.New(evalResult.Inspect())
 	}
+	name := strings.TrimSuffix(filepath.Base(pathModule), ".rl")
+	builtinSymbols.SetModule(canonical, &object.Module{Path: pathModule, Name: name, Env: newEnvironment, Exports: exportedNames(program)})
 	return newEnvironment, nil
 }
 
+// importModule evaluates the module of a standard library path or the first file found in the search paths, the std
+// modules are cached by their import path that can not be mistaken for the absolute path of a file
 func importModule(importStatement *ast.ImportStatement, builtinSymbols *builtin.Builtin) object.Object {
-	modulePath := getModulePath(importStatement.Path, builtinSymbols.GetPaths())
-
-	if modulePath == "" {
-		return &object.ErrorObject{Error: fmt.Sprintf("not module %s found", importStatement.Path)}
+	moduleContent, fromLibrary := std.Source(importStatement.Path)
+	modulePath := importStatement.Path + ".rl"
+	canonical := modulePath
+	if !fromLibrary {
+		var tried []string
+		modulePath, tried = getModulePath(importStatement, builtinSymbols.GetPaths())
+		if modulePath == "" {
+			return &object.ErrorObject{Error: fmt.Sprintf("not module %s found, tried %s", importStatement.Path, strings.Join(tried, ", "))}
+		}
+		canonical = canonicalPath(modulePath)
 	}
-	moduleContent, err := readModuleFile(modulePath)
-	if err != nil {
-		return &object.ErrorObject{Error: fmt.Sprintf("the module %s can not be read", importStatement.Path)}
+	if module, ok := builtinSymbols.GetModule(canonical); ok {
+		return &object.Module{Path: importStatement.Path, Name: importStatement.Name.Value, Env: module.Env, Exports: module.Exports}
+	}
+	if chain, cycle := builtinSymbols.StartLoading(canonical); cycle {
+		return &object.ErrorObject{Error: fmt.Sprintf("import cycle %s", strings.Join(chain, " -> "))}
+	}
+	defer builtinSymbols.EndLoading()
+	if !fromLibrary {
+		content, err := readModuleFile(modulePath)
+		if err != nil {
+			return &object.ErrorObject{Error: fmt.Sprintf("the module %s can not be read", importStatement.Path)}
+		}
+		moduleContent = content
 	}
 	newEnvironment := object.NewEnvironment()
-	l := lexer.New(moduleContent)
+	l := lexer.NewWithFile(moduleContent, modulePath)
 	p := parser.New(l)
 	program := p.ParseProgram()
 	if len(p.GetErrors()) != 0 {
 		return &object.ErrorObject{Error: fmt.Sprintf("error parsing the module %s %s", importStatement.Path, strings.Join(p.GetErrors(), "\n"))}
 	}
+	optimizer.Optimize(program)
+	if resolveErrors := resolver.Resolve(program, newEnvironment, builtinSymbols); len(resolveErrors) != 0 {
+		return &object.ErrorObject{Error: fmt.Sprintf("error resolving the module %s %s", importStatement.Path, strings.Join(resolveErrors, "\n"))}
+	}
 	evalResult := Eval(program, newEnvironment, builtinSymbols)
-	if evalResult != nil && evalResult.Type() == object.ERROR_OBJ {
-		return evalResult
+	if errorObject, ok := evalResult.(*object.ErrorObject); ok {
+		errorObject.AddFrame("<module>", importStatement.Name.Value, importStatement.Position())
+		return errorObject
 	}
-	return &object.Module{Path: importStatement.Path, Name: importStatement.Name.Value, Env: newEnvironment}
+	module := &object.Module{Path: importStatement.Path, Name: importStatement.Name.Value, Env: newEnvironment, Exports: exportedNames(program)}
+	builtinSymbols.SetModule(canonical, module)
+	return module
+}
 
+// exportedNames returns the names the module declares with export, nil when it has no export so every name is visible
+func exportedNames(program *ast.Program) map[string]bool {
+	var exports map[string]bool
+	for _, statement := range program.Statements {
+		switch statementType := statement.(type) {
+		case *ast.LetStatement:
+			if statementType.Exported {
+				if exports == nil {
+					exports = make(map[string]bool)
+				}
+				exports[statementType.Name.Value] = true
+			}
+		case *ast.ExportStatement:
+			if exports == nil {
+				exports = make(map[string]bool)
+			}
+			for _, name := range statementType.Names {
+				exports[name.Value] = true
+			}
+		}
+	}
+	return exports
+}
+
+// canonicalPath is the absolute path of the module file with the symbolic links resolved, two imports of the same file
+// get the same path even when they reach it in different ways
+func canonicalPath(modulePath string) string {
+	absolute, err := filepath.Abs(modulePath)
+	if err != nil {
+		return modulePath
+	}
+	if resolved, err := filepath.EvalSymlinks(absolute); err == nil {
+		return resolved
+	}
+	return absolute
 }
 
 func readModuleFile(modulePath string) (string, error) {
@@ -67,24 +141,32 @@ func readModuleFile(modulePath string) (string, error) {
 	return string(content), err
 }
 
-func getModulePath(module string, paths []string) string {
-	modulePathWithExtension := fmt.Sprintf("%s.rl", module)
+// getModulePath returns the first module file that exists and every file it tried, a path starting with ./ or ../ is
+// relative to the directory of the importing file and any other path is searched in the registered paths
+func getModulePath(importStatement *ast.ImportStatement, paths []string) (string, []string) {
+	modulePathWithExtension := fmt.Sprintf("%s.rl", importStatement.Path)
+	if isRelativeImport(importStatement.Path) {
+		paths = []string{filepath.Dir(importStatement.Position().File)}
+	}
+	tried := make([]string, 0, len(paths))
 	for _, modulePath := range paths {
-		if existPath(modulePath, modulePathWithExtension) {
-			return path.Join(modulePath, modulePathWithExtension)
+		fullPath := filepath.Join(modulePath, modulePathWithExtension)
+		if existPath(fullPath) {
+			return fullPath, tried
 		}
+		tried = append(tried, fullPath)
 	}
-	return ""
+	return "", tried
+}
+
+func isRelativeImport(modulePath string) bool {
+	return strings.HasPrefix(modulePath, "./") || strings.HasPrefix(modulePath, "../")
 }
 
-func existPath(module, modulePath string) bool {
-	fullPath := path.Join(module, modulePath)
-	_, err := os.Stat(fullPath)
+func existPath(fullPath string) bool {
+	info, err := os.Stat(fullPath)
 	if err != nil {
 		return false
 	}
-	if os.IsNotExist(err) {
-		return false
-	}
-	return true
+	return !info.IsDir()
 }
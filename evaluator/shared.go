@@ -0,0 +1,110 @@
+package evaluator
+
+import (
+	"rootlang/ast"
+	"rootlang/object"
+	"rootlang/builtin"
+	"rootlang/lexer"
+	"fmt"
+	"strings"
+)
+
+// the vm package runs the operations below through the evaluator so both backends give the same results and errors
+
+func Infix(operator string, left, right object.Object) object.Object {
+	return evalInfixExpression(operator, right, left)
+}
+
+func Prefix(operator string, right object.Object) object.Object {
+	return evalPrefixExpression(operator, right)
+}
+
+func Index(left, index object.Object) object.Object {
+	return evalIndexExpression(left, index)
+}
+
+func Slice(left, start, end object.Object) object.Object {
+	return evalSliceExpression(left, start, end)
+}
+
+func TruthValue(value object.Object) bool {
+	return evalTruthValue(value)
+}
+
+func Compose(left, right object.Object, position lexer.Position) object.Object {
+	return evalComposeExpression(left, right, lexer.Token{Type: lexer.COMPOSE, Literal: ".", Position: position})
+}
+
+func MatchPattern(pattern ast.Pattern, value object.Object, bind func(name string, value object.Object)) bool {
+	return matchPattern(pattern, value, bind)
+}
+
+func CallFunction(function object.Object, params []object.Object, module string, position lexer.Position, environment *object.Environment, builtinSymbols *builtin.Builtin) object.Object {
+	return callFunction(function, params, module, position, environment, builtinSymbols)
+}
+
+func NewDict(keys, values []object.Object) object.Object {
+	dict := object.NewDict()
+	for i, key := range keys {
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return newError(fmt.Sprintf("unusable as dict key %s", key.Type()))
+		}
+		dict.Set(hashKey, values[i])
+	}
+	return dict
+}
+
+// Import resolves an import statement to a builtin, standard library or file module, builtin modules are found by their
+// path like "/net" so the alias of the import can be any name and a file named like a builtin module is still imported
+func Import(importStatement *ast.ImportStatement, builtinSymbols *builtin.Builtin) object.Object {
+	if name := strings.TrimPrefix(importStatement.Path, "/"); name != importStatement.Path {
+		if module, ok := builtinSymbols.GetObject(name); ok {
+			if _, isModule := module.(*object.Module); isModule {
+				return module
+			}
+		}
+	}
+	return importModule(importStatement, builtinSymbols)
+}
+
+// ImportSymbols resolves import { a, b as c } from "path" to the values of its symbols in the same order
+func ImportSymbols(importStatement *ast.ImportStatement, builtinSymbols *builtin.Builtin) ([]object.Object, *object.ErrorObject) {
+	imported := Import(importStatement, builtinSymbols)
+	if errorObject, ok := imported.(*object.ErrorObject); ok {
+		return nil, errorObject
+	}
+	module, ok := imported.(*object.Module)
+	if !ok {
+		return nil, newError(fmt.Sprintf("%s is not a module", importStatement.Path))
+	}
+	values := make([]object.Object, 0, len(importStatement.Symbols))
+	for _, symbol := range importStatement.Symbols {
+		value := ModuleSymbol(module, symbol.Name.Value)
+		if errorObject, ok := value.(*object.ErrorObject); ok {
+			return nil, errorObject
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// ModuleSymbol returns the value of a name the module exports
+func ModuleSymbol(module *object.Module, name string) object.Object {
+	if !module.IsExported(name) {
+		return newError(fmt.Sprintf("symbol %s is not exported by module %s", name, module.Name))
+	}
+	value, ok := module.Env.GetVar(name)
+	if !ok {
+		return newError(fmt.Sprintf("symbol %s not found in module %s", name, module.Name))
+	}
+	return value
+}
+
+// ModuleFunction evaluates the function of a call on a module, a name has to be exported by the module
+func ModuleFunction(module *object.Module, function ast.Expression, builtinSymbols *builtin.Builtin) object.Object {
+	if identifier, ok := function.(*ast.Identifier); ok {
+		return ModuleSymbol(module, identifier.Value)
+	}
+	return Eval(function, module.Env, builtinSymbols)
+}
@@ -1,6 +1,14 @@
 import "/net";
 import "/bytes";
 let main = () => {
+	let send = (client, message) => {
+		return try {
+			net::write_to_client(client, message);
+		} catch (e) {
+			print("can not write to client ", client, " --> ", error_message(e));
+			e;
+		};
+	};
 	let get_clients_to_write = (server, client)=> {
 		let is_current_client = client_to_write => {
 			let client_id = net::get_client_id(client);
@@ -15,7 +23,7 @@ let main = () => {
 		let clients = get_clients_to_write(server,client);
 		let client_id = net::get_client_id(client);
 		let message_to_send = bytes::create_writer("new-client :) ",client_id);
-		let clients_write = map(client_to_write => { return net::write_to_client(client_to_write, message_to_send);}, clients);
+		let clients_write = map(client_to_write => { return send(client_to_write, message_to_send);}, clients);
 		return clients_write;
 		
 	};
@@ -24,7 +32,7 @@ let main = () => {
 		let clients = get_clients_to_write(server, client);
 		let client_id =  net::get_client_id(client);
 		let message_to_send = bytes::create_writer(client_id, ": ", message_text);
-		let clients_write = map(client_to_write => { return net::write_to_client(client_to_write, message_to_send);}, clients);
+		let clients_write = map(client_to_write => { return send(client_to_write, message_to_send);}, clients);
 		return clients_write;	
 	};
 	print("server listen on port 3000");
@@ -0,0 +1,112 @@
+// Package rootlang runs rootlang programs from Go. An Interpreter keeps the names its programs declare between calls,
+// so a host can load a file of rules once and call its functions many times with Go values.
+package rootlang
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"rootlang/builtin"
+	"rootlang/evaluator"
+	"rootlang/lexer"
+	"rootlang/object"
+	"rootlang/optimizer"
+	"rootlang/parser"
+	"rootlang/resolver"
+)
+
+type Interpreter struct {
+	environment *object.Environment
+	builtins    *builtin.Builtin
+}
+
+func New() *Interpreter {
+	return &Interpreter{environment: object.NewEnvironment(), builtins: builtin.New()}
+}
+
+// Builtins returns the builtin symbols and module search paths of the interpreter
+func (i *Interpreter) Builtins() *builtin.Builtin {
+	return i.builtins
+}
+
+// EvalString evaluates source and returns the value of its last expression converted to Go
+func (i *Interpreter) EvalString(source string) (interface{}, error) {
+	return i.eval(lexer.New(source))
+}
+
+// EvalFile evaluates the file like EvalString, the imports of the file are searched in its directory too
+func (i *Interpreter) EvalFile(path string) (interface{}, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	directory := filepath.Dir(path)
+	registered := false
+	for _, searchPath := range i.builtins.GetPaths() {
+		registered = registered || searchPath == directory
+	}
+	if !registered {
+		i.builtins.RegisterPath(directory)
+	}
+	return i.eval(lexer.NewWithFile(string(content), path))
+}
+
+func (i *Interpreter) eval(l *lexer.Lexer) (interface{}, error) {
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.GetErrors()) != 0 {
+		return nil, errors.New(strings.Join(p.GetErrors(), "\n"))
+	}
+	optimizer.Optimize(program)
+	if resolveErrors := resolver.Resolve(program, i.environment, i.builtins); len(resolveErrors) != 0 {
+		return nil, errors.New(strings.Join(resolveErrors, "\n"))
+	}
+	return i.result(evaluator.Eval(program, i.environment, i.builtins))
+}
+
+// Call calls the function declared with name, the args are converted to rootlang values
+func (i *Interpreter) Call(name string, args ...interface{}) (interface{}, error) {
+	function, ok := i.environment.GetVar(name)
+	if !ok {
+		if function, ok = i.builtins.GetObject(name); !ok {
+			return nil, errors.New(name + " is not declared")
+		}
+	}
+	return i.call(function, args)
+}
+
+func (i *Interpreter) call(function object.Object, args []interface{}) (interface{}, error) {
+	params := make([]object.Object, 0, len(args))
+	for _, arg := range args {
+		param, err := i.ToObject(arg)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, param)
+	}
+	return i.result(evaluator.CallFunction(function, params, "", lexer.Position{}, i.environment, i.builtins))
+}
+
+// Define binds name to the value converted to rootlang in the top level of the interpreter
+func (i *Interpreter) Define(name string, value interface{}) error {
+	converted, err := i.ToObject(value)
+	if err != nil {
+		return err
+	}
+	if function, ok := converted.(*builtin.BuiltinFunction); ok && function.Name == "" {
+		function.Name = name
+	}
+	i.environment.SetVar(name, converted)
+	return nil
+}
+
+func (i *Interpreter) result(value object.Object) (interface{}, error) {
+	if errorObject, ok := value.(*object.ErrorObject); ok {
+		return nil, errors.New(errorObject.Inspect())
+	}
+	if value == nil {
+		return nil, nil
+	}
+	return i.ToGo(value), nil
+}
@@ -0,0 +1,157 @@
+package rootlang
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEvalString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`1 + 2`, int64(3)},
+		{`9223372036854775807 + 1`, new(big.Int).Lsh(big.NewInt(1), 63)},
+		{`1.5 * 2`, 3.0},
+		{`"root" + "lang"`, "rootlang"},
+		{`1 < 2`, true},
+		{`[1, "a", [true]]`, []interface{}{int64(1), "a", []interface{}{true}}},
+		{`let d = {"a": 1, 2: false}; d`, map[interface{}]interface{}{"a": int64(1), int64(2): false}},
+		{`let x = 1;`, nil},
+	}
+	for _, test := range tests {
+		result, err := New().EvalString(test.input)
+		if err != nil {
+			t.Errorf("%s: %s", test.input, err)
+			continue
+		}
+		if expected, ok := test.expected.(*big.Int); ok {
+			if value, ok := result.(*big.Int); !ok || value.Cmp(expected) != 0 {
+				t.Errorf("%s: expected %v and got %v", test.input, test.expected, result)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("%s: expected %v and got %v", test.input, test.expected, result)
+		}
+	}
+	for input, expected := range map[string]string{`let = 1;`: "ident is expected", `x + 1`: "x was not declare", `1 / 0`: "division by zero"} {
+		if _, err := New().EvalString(input); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected error %s and got %v", input, expected, err)
+		}
+	}
+}
+
+func TestDefineAndCall(t *testing.T) {
+	interpreter := New()
+	limits := map[string]int{"gold": 100, "silver": 10}
+	if err := interpreter.Define("limits", limits); err != nil {
+		t.Fatal(err)
+	}
+	interpreter.Define("scale", func(values []int, factor float64) []float64 {
+		scaled := make([]float64, 0)
+		for _, value := range values {
+			scaled = append(scaled, float64(value)*factor)
+		}
+		return scaled
+	})
+	interpreter.Define("check", func(amount int) (bool, error) {
+		if amount < 0 {
+			return false, errors.New("negative amount")
+		}
+		return amount > 50, nil
+	})
+	interpreter.Define("apply", func(f func(int) int, value int) int {
+		return f(value)
+	})
+	interpreter.Define("apply_checked", func(f func(int) (int, error), value int) (int, error) {
+		return f(value)
+	})
+	_, err := interpreter.EvalString(`let allowed = (tier, amount) => !(amount > limits[tier]); let twice = x => x * 2;`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		args     []interface{}
+		expected interface{}
+	}{
+		{"allowed", []interface{}{"gold", 50}, true},
+		{"allowed", []interface{}{"silver", uint8(50)}, false},
+		{"scale", []interface{}{[]int{1, 2}, 1.5}, []interface{}{1.5, 3.0}},
+		{"check", []interface{}{60}, true},
+		{"apply", []interface{}{func(x int) int { return x + 1 }, 1}, int64(2)},
+		{"len", []interface{}{"rootlang"}, int64(8)},
+	}
+	for _, test := range tests {
+		result, err := interpreter.Call(test.name, test.args...)
+		if err != nil || !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("%s%v: expected %v and got %v %v", test.name, test.args, test.expected, result, err)
+		}
+	}
+	result, err := interpreter.EvalString(`apply(twice, 21)`)
+	if err != nil || result != int64(42) {
+		t.Errorf("a rootlang function should be passed as a Go func and got %v %v", result, err)
+	}
+	errorTests := []struct {
+		name     string
+		args     []interface{}
+		expected string
+	}{
+		{"check", []interface{}{-1}, "negative amount"},
+		{"check", []interface{}{1.5}, "1.5 does not fit in int"},
+		{"check", []interface{}{"1"}, "expected int and got STRING"},
+		{"check", []interface{}{}, "expected 1 params and got 0"},
+		{"missing", []interface{}{}, "missing is not declared"},
+		{"allowed", []interface{}{struct{}{}, 1}, "struct {} can not be converted"},
+	}
+	for _, test := range errorTests {
+		if _, err := interpreter.Call(test.name, test.args...); err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%s%v: expected error %s and got %v", test.name, test.args, test.expected, err)
+		}
+	}
+	evalErrorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`apply_checked(x => if (x > 100) { 1; }, 3)`, "expected int and got null"},
+		{`apply(x => if (x > 100) { 1; }, 3)`, "expected int and got null"},
+		{`apply(x => 1 / 0, 3)`, "division by zero"},
+	}
+	for _, test := range evalErrorTests {
+		if _, err := interpreter.EvalString(test.input); err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%s: expected error %s and got %v", test.input, test.expected, err)
+		}
+	}
+	caught, err := interpreter.EvalString(`try { apply(x => 1 / 0, 3); } catch (e) { error_message(e); }`)
+	if err != nil || !strings.Contains(fmt.Sprint(caught), "division by zero") {
+		t.Errorf("the error of a callback should be caught by try and got %v %v", caught, err)
+	}
+	function, _ := interpreter.EvalString(`twice`)
+	if result, err := function.(func(...interface{}) (interface{}, error))(4); err != nil || result != int64(8) {
+		t.Errorf("a rootlang function should be returned as a Go func and got %v %v", result, err)
+	}
+}
+
+func TestEvalFile(t *testing.T) {
+	os.MkdirAll("/tmp/testInterpreter", 0755)
+	defer os.RemoveAll("/tmp/testInterpreter")
+	ioutil.WriteFile("/tmp/testInterpreter/rules.rl", []byte(`import "discount" as discount; let price = amount => amount - discount::of(amount);`), 0644)
+	ioutil.WriteFile("/tmp/testInterpreter/discount.rl", []byte(`let of = amount => amount / 10;`), 0644)
+	interpreter := New()
+	if _, err := interpreter.EvalFile("/tmp/testInterpreter/rules.rl"); err != nil {
+		t.Fatal(err)
+	}
+	if result, err := interpreter.Call("price", 200); err != nil || result != int64(180) {
+		t.Errorf("expected 180 and got %v %v", result, err)
+	}
+	if _, err := interpreter.EvalFile("/tmp/testInterpreter/missing.rl"); err == nil {
+		t.Errorf("a missing file should be an error")
+	}
+}
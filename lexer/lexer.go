@@ -6,25 +6,41 @@ import (
 
 type Lexer struct {
 	input        string
+	file         string
 	position     int
 	readPosition int
 	ch           byte
+	line         int
+	column       int
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input + " "}
+	return NewWithFile(input, "")
+}
+
+func NewWithFile(input string, file string) *Lexer {
+	l := &Lexer{input: input + " ", file: file, line: 1}
 	l.readChar()
 	return l
 }
 
 func (l *Lexer) NextToken() Token {
 	l.skipSpace()
+	position := Position{File: l.file, Line: l.line, Column: l.column}
+	token := l.readToken()
+	token.Position = position
+	return token
+}
+
+func (l *Lexer) readToken() Token {
 	var token Token
 	switch l.ch {
 	case ':':
 		if l.peekChar() == ':' {
 			token = newToken(MODULE, "::")
 			l.readChar()
+		} else {
+			token = newToken(COLON, string(l.ch))
 		}
 	case '+':
 		token = newToken(PLUS, string(l.ch))
@@ -41,6 +57,21 @@ func (l *Lexer) NextToken() Token {
 
 	case ',':
 		token = newToken(COMMA, string(l.ch))
+	case '.':
+		if l.peekChar() == '.' && l.readPosition+1 < len(l.input) && l.input[l.readPosition+1] == '.' {
+			token = newToken(ELLIPSIS, "...")
+			l.readChar()
+			l.readChar()
+		} else {
+			token = newToken(COMPOSE, string(l.ch))
+		}
+	case '|':
+		if l.peekChar() == '>' {
+			token = newToken(PIPE, "|>")
+			l.readChar()
+		} else {
+			token = newToken(ILLEGAL, string(l.ch))
+		}
 	case '%':
 		token = newToken(MOD, string(l.ch))
 	case ';':
@@ -53,6 +84,10 @@ func (l *Lexer) NextToken() Token {
 		token = newToken(LBRACE, string(l.ch))
 	case '}':
 		token = newToken(RBRACE, string(l.ch))
+	case '[':
+		token = newToken(LBRACKET, string(l.ch))
+	case ']':
+		token = newToken(RBRACKET, string(l.ch))
 	case '-':
 		token = newToken(MINUS, string(l.ch))
 	case '*':
@@ -81,8 +116,7 @@ func (l *Lexer) NextToken() Token {
 			token.Type = lookUpKeyWord(token.Literal)
 			return token
 		} else if isNumber(l.ch) {
-			token.Literal = l.readNumber()
-			token.Type = INT
+			token.Literal, token.Type = l.readNumber()
 			return token
 		} else {
 			token.Type = ILLEGAL
@@ -120,15 +154,58 @@ func (l *Lexer) isStringCharacter() bool {
 	return l.ch != '"' || isSpace(l.ch) || (l.ch == 92 && l.peekChar() == '"');
 }
 
-func (l*Lexer) readNumber() string {
+func (l*Lexer) readNumber() (string, TokenType) {
 	beginPosition := l.position
-	for isNumber(l.ch) {
+	if l.ch == '0' && (l.peekChar() == 'x' || l.peekChar() == 'X') {
 		l.readChar()
+		l.readChar()
+		l.readDigits(isHexNumber)
+		return l.input[beginPosition:l.position], INT
 	}
-	if beginPosition == l.position {
-		return l.input[beginPosition:]
+	if l.ch == '0' && (l.peekChar() == 'b' || l.peekChar() == 'B') {
+		l.readChar()
+		l.readChar()
+		l.readDigits(isBinaryNumber)
+		return l.input[beginPosition:l.position], INT
 	}
-	return l.input[beginPosition:l.position]
+	var tokenType TokenType = INT
+	l.readDigits(isNumber)
+	if l.ch == '.' && isNumber(l.peekChar()) {
+		tokenType = FLOAT
+		l.readChar()
+		l.readDigits(isNumber)
+	}
+	if (l.ch == 'e' || l.ch == 'E') && l.isExponentStart() {
+		tokenType = FLOAT
+		l.readChar()
+		if l.ch == '+' || l.ch == '-' {
+			l.readChar()
+		}
+		l.readDigits(isNumber)
+	}
+	return l.input[beginPosition:l.position], tokenType
+}
+
+func (l *Lexer) readDigits(isDigit func(byte) bool) {
+	for isDigit(l.ch) || (l.ch == '_' && isDigit(l.peekChar())) {
+		l.readChar()
+	}
+}
+
+func (l *Lexer) isExponentStart() bool {
+	next := l.peekChar()
+	if isNumber(next) {
+		return true
+	}
+	return (next == '+' || next == '-') && l.readPosition+1 < len(l.input) && isNumber(l.input[l.readPosition+1])
+}
+
+func isHexNumber(ch byte) bool {
+	return isNumber(ch) || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F'
+}
+
+func isBinaryNumber(ch byte) bool {
+	return ch == '0' || ch == '1'
 }
 
 func isNumber(ch byte) bool {
@@ -148,6 +225,11 @@ func (l *Lexer) readChar() {
 		l.ch = 0
 		return
 	}
+	if l.ch == '\n' {
+		l.line += 1
+		l.column = 0
+	}
+	l.column += 1
 	l.ch = l.input[l.readPosition]
 	l.position = l.readPosition
 	l.readPosition += 1
@@ -155,13 +237,7 @@ func (l *Lexer) readChar() {
 
 func (l *Lexer) skipSpace() {
 	for isSpace(l.ch) {
-		if !l.hasMoreToRead() {
-			l.ch = 0
-			return
-		}
-		l.ch = l.input[l.readPosition]
-		l.position = l.readPosition
-		l.readPosition += 1
+		l.readChar()
 	}
 }
 
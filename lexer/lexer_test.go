@@ -97,6 +97,63 @@ func TestIdentifier(t *testing.T) {
 	assertLexer(t, inputLine, []Token{Token{Type: IDENT, Literal: "carlos-1"}, })
 }
 
+func TestNumberTokens(t *testing.T) {
+	inputLine := `3.14 1e-9 2.5E+3 0xFF 0b1010 1_000_000 7`
+	tokensExpected := []Token{Token{Type: FLOAT, Literal: "3.14"}, Token{Type: FLOAT, Literal: "1e-9"},
+		Token{Type: FLOAT, Literal: "2.5E+3"}, Token{Type: INT, Literal: "0xFF"},
+		Token{Type: INT, Literal: "0b1010"}, Token{Type: INT, Literal: "1_000_000"},
+		Token{Type: INT, Literal: "7"}, Token{Type: EOF, Literal: ""}}
+	assertLexer(t, inputLine, tokensExpected)
+}
+
+func TestDictTokens(t *testing.T) {
+	inputLine := `{"a": 1}["a"]`
+	tokensExpected := []Token{Token{Type: LBRACE, Literal: "{"}, Token{Type: STRING, Literal: "a"},
+		Token{Type: COLON, Literal: ":"}, Token{Type: INT, Literal: "1"}, Token{Type: RBRACE, Literal: "}"},
+		Token{Type: LBRACKET, Literal: "["}, Token{Type: STRING, Literal: "a"}, Token{Type: RBRACKET, Literal: "]"},
+		Token{Type: EOF, Literal: ""}}
+	assertLexer(t, inputLine, tokensExpected)
+}
+
+func TestTokenPosition(t *testing.T) {
+	inputLine := "let x = 5;\n  \"a\nb\" + x;"
+	positionsExpected := []Position{{"test.rl", 1, 1}, {"test.rl", 1, 5}, {"test.rl", 1, 7}, {"test.rl", 1, 9},
+		{"test.rl", 1, 10}, {"test.rl", 2, 3}, {"test.rl", 3, 4}, {"test.rl", 3, 6}, {"test.rl", 3, 7}}
+	l := NewWithFile(inputLine, "test.rl")
+	for _, positionExpected := range positionsExpected {
+		token := l.NextToken()
+		if token.Position != positionExpected {
+			t.Fatalf("Expected Token %s at position %s and got %s", token.Literal, positionExpected, token.Position)
+		}
+	}
+}
+
+func TestComposeToken(t *testing.T) {
+	inputLine := `f . g.h 1.5`
+	tokensExpected := []Token{Token{Type: IDENT, Literal: "f"}, Token{Type: COMPOSE, Literal: "."},
+		Token{Type: IDENT, Literal: "g"}, Token{Type: COMPOSE, Literal: "."}, Token{Type: IDENT, Literal: "h"},
+		Token{Type: FLOAT, Literal: "1.5"}, Token{Type: EOF, Literal: ""}}
+	assertLexer(t, inputLine, tokensExpected)
+}
+
+func TestPipeToken(t *testing.T) {
+	inputLine := `xs |> map(f)`
+	tokensExpected := []Token{Token{Type: IDENT, Literal: "xs"}, Token{Type: PIPE, Literal: "|>"},
+		Token{Type: IDENT, Literal: "map"}, Token{Type: LPAREN, Literal: "("}, Token{Type: IDENT, Literal: "f"},
+		Token{Type: RPAREN, Literal: ")"}, Token{Type: EOF, Literal: ""}}
+	assertLexer(t, inputLine, tokensExpected)
+}
+
+func TestMatchTokens(t *testing.T) {
+	inputLine := `match xs { [x, ...rest] => x }`
+	tokensExpected := []Token{Token{Type: MATCH, Literal: "match"}, Token{Type: IDENT, Literal: "xs"},
+		Token{Type: LBRACE, Literal: "{"}, Token{Type: LBRACKET, Literal: "["}, Token{Type: IDENT, Literal: "x"},
+		Token{Type: COMMA, Literal: ","}, Token{Type: ELLIPSIS, Literal: "..."}, Token{Type: IDENT, Literal: "rest"},
+		Token{Type: RBRACKET, Literal: "]"}, Token{Type: FUNCTION, Literal: "=>"}, Token{Type: IDENT, Literal: "x"},
+		Token{Type: RBRACE, Literal: "}"}, Token{Type: EOF, Literal: ""}}
+	assertLexer(t, inputLine, tokensExpected)
+}
+
 func TestFunctionIdentifier(t *testing.T) {
 	inputLine := `=>`
 	assertLexer(t, inputLine, []Token{Token{Type: FUNCTION, Literal: "=>"}, })
@@ -1,9 +1,29 @@
 package lexer
 
+import "fmt"
+
 type TokenType string
 type Token struct {
-	Type    TokenType
-	Literal string
+	Type     TokenType
+	Literal  string
+	Position Position
+}
+
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Position) String() string {
+	if p.File == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
 }
 
 const (
@@ -11,6 +31,7 @@ const (
 	EOF       = "EOF"
 	IDENT     = "IDENT"
 	INT       = "INT"
+	FLOAT     = "FLOAT"
 	ASSIGN    = "="
 	PLUS      = "+"
 	COMMA     = ","
@@ -19,6 +40,9 @@ const (
 	RPAREN    = ")"
 	LBRACE    = "{"
 	RBRACE    = "}"
+	LBRACKET  = "["
+	RBRACKET  = "]"
+	COLON     = ":"
 	LET       = "LET"
 	TRUE      = "TRUE"
 	FALSE     = "FALSE"
@@ -29,6 +53,9 @@ const (
 	EQUAL     = "=="
 	NOTEQUAL  = "!="
 	MODULE    = "::"
+	COMPOSE   = "."
+	PIPE      = "|>"
+	ELLIPSIS  = "..."
 	MINUS     = "-"
 	DIV       = "/"
 	MOD       = "%"
@@ -38,10 +65,15 @@ const (
 	FUNCTION  = "=>"
 	STRING    = `"`
 	IMPORT    = "IMPORT"
+	EXPORT    = "EXPORT"
+	FROM      = "FROM"
 	AS        = "AS"
+	TRY       = "TRY"
+	CATCH     = "CATCH"
+	MATCH     = "MATCH"
 )
 
-var keywords = map[string]TokenType{"import": IMPORT, "as": AS, "let": LET, "if": IF, "return": RETURN, "true": TRUE, "false": FALSE, "else": ELSE}
+var keywords = map[string]TokenType{"import": IMPORT, "export": EXPORT, "from": FROM, "as": AS, "let": LET, "if": IF, "return": RETURN, "true": TRUE, "false": FALSE, "else": ELSE, "try": TRY, "catch": CATCH, "match": MATCH}
 
 func lookUpKeyWord(identifier string) TokenType {
 	if tok, ok := keywords[identifier]; ok {
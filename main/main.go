@@ -10,19 +10,57 @@ import (
 	"rootlang/object"
 	"rootlang/builtin"
 	"os"
+	"io/ioutil"
+	"rootlang/ast"
+	"flag"
+	"rootlang/compiler"
+	"rootlang/vm"
+	"rootlang/codegen/llvm"
+	"rootlang/resolver"
+	"rootlang/optimizer"
+	"path/filepath"
+	"strings"
 )
 
 var PROMPT string = "rootlang>"
 
+var useVM = flag.Bool("vm", false, "run programs on the bytecode virtual machine")
+var dumpOptimized = flag.Bool("dump-optimized", false, "print the module after the optimizer rewrites it instead of running it")
+var includePaths = make(pathList, 0)
+
+// pathList is a flag that can be repeated, every use adds a path
+type pathList []string
+
+func (l *pathList) String() string {
+	return strings.Join(*l, string(os.PathListSeparator))
+}
+
+func (l *pathList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func init() {
+	flag.Var(&includePaths, "I", "add a directory to the module search path, can be repeated")
+}
+
 func main() {
-	if len(os.Args) == 1 {
+	flag.Parse()
+	if flag.NArg() == 0 {
 		start(os.Stdin, os.Stdout)
+	} else if flag.Arg(0) == "emit-llvm" {
+		emitLLVM(flag.Args()[1:])
+	} else if *dumpOptimized {
+		dumpOptimizedModule(flag.Arg(0))
+	} else if *useVM {
+		runVM(flag.Arg(0))
 	} else {
-		modulePath := os.Args[1]
+		modulePath := flag.Arg(0)
 		builtinSymbols := builtin.New()
-		env, err := evaluator.ReadPrincipalModule(modulePath)
+		registerModulePaths(builtinSymbols, filepath.Dir(modulePath))
+		env, err := evaluator.ReadPrincipalModule(modulePath, builtinSymbols)
 		if err != nil {
-			os.Stderr.WriteString(fmt.Sprintf("Error On Module %s  --> %s\n", err.Error(), modulePath))
+			os.Stderr.WriteString(fmt.Sprintf("Error On Module %s  --> %s\n", modulePath, err.Error()))
 			return
 		}
 		mainFunction, hasMain := env.GetVar("main")
@@ -35,18 +73,137 @@ func main() {
 			return
 		}
 		returnValue := evaluator.CallMainFunction(mainFunction.(*object.Function), builtinSymbols)
-		if returnValue.Type() == object.ERROR_OBJ {
-			os.Stderr.WriteString(fmt.Sprintf("%s\n", returnValue.Inspect()))
+		if errorObject, ok := returnValue.(*object.ErrorObject); ok {
+			os.Stderr.WriteString(fmt.Sprintf("%s\n", errorObject.Inspect()))
+			os.Stderr.WriteString(errorObject.StackTrace())
 			os.Exit(-1)
 		}
 	}
 
 }
 
+// registerModulePaths sets where imports are searched: the directory of the entry module, the -I flags and then the
+// directories of ROOTLANG_PATH
+func registerModulePaths(builtinSymbols *builtin.Builtin, entryDirectory string) {
+	builtinSymbols.RegisterPath(entryDirectory)
+	for _, includePath := range includePaths {
+		builtinSymbols.RegisterPath(includePath)
+	}
+	for _, environmentPath := range filepath.SplitList(os.Getenv("ROOTLANG_PATH")) {
+		if environmentPath != "" {
+			builtinSymbols.RegisterPath(environmentPath)
+		}
+	}
+}
+
+func runVM(modulePath string) {
+	moduleContent, err := ioutil.ReadFile(modulePath)
+	if err != nil {
+		os.Stderr.WriteString(fmt.Sprintf("Error On Module %s  --> %s\n", modulePath, err.Error()))
+		return
+	}
+	p := parser.New(lexer.NewWithFile(string(moduleContent), modulePath))
+	program := p.ParseProgram()
+	if len(p.GetErrors()) != 0 {
+		printParserErrors(os.Stderr, p.GetErrors())
+		return
+	}
+	optimizer.Optimize(program)
+	globals := object.NewEnvironment()
+	builtinSymbols := builtin.New()
+	registerModulePaths(builtinSymbols, filepath.Dir(modulePath))
+	if resolveErrors := resolver.Resolve(program, globals, builtinSymbols); len(resolveErrors) != 0 {
+		printParserErrors(os.Stderr, resolveErrors)
+		return
+	}
+	fn, err := compiler.New().Compile(program)
+	if err != nil {
+		os.Stderr.WriteString(fmt.Sprintf("Error On Module %s  --> %s\n", modulePath, err.Error()))
+		return
+	}
+	returnValue := vm.New(globals, builtinSymbols).Run(fn)
+	if returnValue != nil && returnValue.Type() == object.ERROR_OBJ {
+		os.Stderr.WriteString(fmt.Sprintf("Error On Module %s  --> %s\n", modulePath, returnValue.Inspect()))
+		return
+	}
+	mainFunction, hasMain := globals.GetVar("main")
+	if !hasMain {
+		os.Stderr.WriteString("Module Has No Main Function\n")
+		return
+	}
+	mainClosure, ok := mainFunction.(*vm.Closure)
+	if !ok {
+		os.Stderr.WriteString("Main is not a function\n")
+		return
+	}
+	returnValue = vm.CallMainFunction(mainClosure)
+	if errorObject, ok := returnValue.(*object.ErrorObject); ok {
+		os.Stderr.WriteString(fmt.Sprintf("%s\n", errorObject.Inspect()))
+		os.Stderr.WriteString(errorObject.StackTrace())
+		os.Exit(-1)
+	}
+}
+
+// dumpOptimizedModule prints the module rewritten by the optimizer, one statement per line
+func dumpOptimizedModule(modulePath string) {
+	moduleContent, err := ioutil.ReadFile(modulePath)
+	if err != nil {
+		os.Stderr.WriteString(fmt.Sprintf("Error On Module %s  --> %s\n", modulePath, err.Error()))
+		os.Exit(1)
+	}
+	p := parser.New(lexer.NewWithFile(string(moduleContent), modulePath))
+	program := p.ParseProgram()
+	if len(p.GetErrors()) != 0 {
+		printParserErrors(os.Stderr, p.GetErrors())
+		os.Exit(1)
+	}
+	for _, statement := range optimizer.Optimize(program).Statements {
+		os.Stdout.WriteString(fmt.Sprintf("%s\n", statement.String()))
+	}
+}
+
+// emitLLVM prints the LLVM IR of a module or writes it to the file given with -o
+func emitLLVM(args []string) {
+	flags := flag.NewFlagSet("emit-llvm", flag.ExitOnError)
+	output := flags.String("o", "", "write the IR to this file instead of stdout")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		os.Stderr.WriteString("usage: rootlang emit-llvm [-o file.ll] file.rl\n")
+		os.Exit(2)
+	}
+	modulePath := flags.Arg(0)
+	moduleContent, err := ioutil.ReadFile(modulePath)
+	if err != nil {
+		os.Stderr.WriteString(fmt.Sprintf("Error On Module %s  --> %s\n", modulePath, err.Error()))
+		os.Exit(1)
+	}
+	p := parser.New(lexer.NewWithFile(string(moduleContent), modulePath))
+	program := p.ParseProgram()
+	if len(p.GetErrors()) != 0 {
+		printParserErrors(os.Stderr, p.GetErrors())
+		os.Exit(1)
+	}
+	ir, err := llvm.New(modulePath).Emit(program)
+	if err != nil {
+		os.Stderr.WriteString(fmt.Sprintf("%s\n", err.Error()))
+		os.Exit(1)
+	}
+	if *output == "" {
+		os.Stdout.WriteString(ir)
+		return
+	}
+	if err := ioutil.WriteFile(*output, []byte(ir), 0644); err != nil {
+		os.Stderr.WriteString(fmt.Sprintf("%s\n", err.Error()))
+		os.Exit(1)
+	}
+}
+
 func start(in io.Reader, out io.Writer) {
 	scanner := bufio.NewScanner(in)
 	environment := object.NewEnvironment()
 	builtinSymbols := builtin.New()
+	registerModulePaths(builtinSymbols, ".")
+	machine := vm.New(environment, builtinSymbols)
 	for {
 		fmt.Print(PROMPT)
 		scanned := scanner.Scan()
@@ -61,7 +218,17 @@ func start(in io.Reader, out io.Writer) {
 			printParserErrors(out, p.GetErrors())
 			continue
 		}
-		evaluated := evaluator.Eval(program, environment, builtinSymbols)
+		optimizer.Optimize(program)
+		if resolveErrors := resolver.ResolveLine(program, environment, builtinSymbols); len(resolveErrors) != 0 {
+			printParserErrors(out, resolveErrors)
+			continue
+		}
+		var evaluated object.Object
+		if *useVM {
+			evaluated = runLine(machine, program)
+		} else {
+			evaluated = evaluator.Eval(program, environment, builtinSymbols)
+		}
 		if evaluated != nil {
 			io.WriteString(out, evaluated.Inspect())
 			io.WriteString(out, "\n")
@@ -69,6 +236,14 @@ func start(in io.Reader, out io.Writer) {
 	}
 }
 
+func runLine(machine *vm.VM, program *ast.Program) object.Object {
+	fn, err := compiler.New().Compile(program)
+	if err != nil {
+		return &object.ErrorObject{Error: err.Error()}
+	}
+	return machine.Run(fn)
+}
+
 func printParserErrors(out io.Writer, errors []string) {
 	for _, error := range errors {
 		io.WriteString(out, fmt.Sprintf("%s\n", error))
@@ -0,0 +1,221 @@
+package object
+
+import (
+  "bytes"
+  "strings"
+)
+
+const (
+  listBits  = 5
+  listWidth = 1 << listBits
+  listMask  = listWidth - 1
+)
+
+// List is a persistent vector, a trie of 32 way nodes plus a tail with the last elements. Lists are never changed,
+// Append, Prepend, Set and Concat return a new list that shares the nodes of the old one it did not touch. The elements
+// prepended are kept in front, another list in reverse order, so Prepend is an Append to it and the trie is shared
+type List struct {
+  count int
+  shift uint
+  root  *listNode
+  tail  []Object
+  front *List
+}
+
+// listNode is a branch of the trie when children is set and a leaf with 32 elements otherwise
+type listNode struct {
+  children []*listNode
+  values   []Object
+}
+
+func NewList(elements []Object) *List {
+  tailStart := tailOffset(len(elements))
+  nodes := make([]*listNode, 0, tailStart/listWidth)
+  for i := 0; i < tailStart; i += listWidth {
+    values := make([]Object, listWidth)
+    copy(values, elements[i:])
+    nodes = append(nodes, &listNode{values: values})
+  }
+  shift := uint(listBits)
+  for len(nodes) > listWidth {
+    parents := make([]*listNode, 0, len(nodes)/listWidth+1)
+    for i := 0; i < len(nodes); i += listWidth {
+      end := i + listWidth
+      if end > len(nodes) {
+        end = len(nodes)
+      }
+      parents = append(parents, &listNode{children: nodes[i:end:end]})
+    }
+    nodes = parents
+    shift += listBits
+  }
+  tail := make([]Object, len(elements)-tailStart)
+  copy(tail, elements[tailStart:])
+  return &List{count: len(elements), shift: shift, root: &listNode{children: nodes}, tail: tail}
+}
+
+func tailOffset(count int) int {
+  if count < listWidth {
+    return 0
+  }
+  return ((count - 1) >> listBits) << listBits
+}
+
+func (l *List) Type() ObjectType {
+  return LIST_OBJ
+}
+
+func (l *List) Inspect() string {
+  buffer := bytes.NewBufferString("[")
+  elements := make([]string, 0)
+  for _, element := range l.Elements() {
+    elements = append(elements, element.Inspect())
+  }
+  buffer.WriteString(strings.Join(elements, ","))
+  buffer.WriteString("]")
+  return buffer.String()
+}
+
+func (l *List) Len() int {
+  return l.count + l.frontLen()
+}
+
+func (l *List) frontLen() int {
+  if l.front == nil {
+    return 0
+  }
+  return l.front.count
+}
+
+// Get returns the element at index, the index must be between 0 and Len() - 1
+func (l *List) Get(index int) Object {
+  frontLen := l.frontLen()
+  if index < frontLen {
+    return l.front.Get(frontLen - 1 - index)
+  }
+  index -= frontLen
+  return l.leafFor(index)[index&listMask]
+}
+
+func (l *List) leafFor(index int) []Object {
+  if index >= tailOffset(l.count) {
+    return l.tail
+  }
+  node := l.root
+  for level := l.shift; level > 0; level -= listBits {
+    node = node.children[(index>>level)&listMask]
+  }
+  return node.values
+}
+
+// Elements returns a new slice with the elements of the list
+func (l *List) Elements() []Object {
+  elements := make([]Object, 0, l.Len())
+  if l.front != nil {
+    front := l.front.Elements()
+    for i := len(front) - 1; i >= 0; i-- {
+      elements = append(elements, front[i])
+    }
+  }
+  for i := 0; i < tailOffset(l.count); i += listWidth {
+    elements = append(elements, l.leafFor(i)...)
+  }
+  return append(elements, l.tail...)
+}
+
+func (l *List) Append(value Object) *List {
+  if l.count-tailOffset(l.count) < listWidth {
+    tail := make([]Object, len(l.tail), len(l.tail)+1)
+    copy(tail, l.tail)
+    return &List{count: l.count + 1, shift: l.shift, root: l.root, tail: append(tail, value), front: l.front}
+  }
+  leaf := &listNode{values: l.tail}
+  root, shift := l.root, l.shift
+  if (l.count >> listBits) > (1 << l.shift) {
+    root = &listNode{children: []*listNode{l.root, newPath(l.shift, leaf)}}
+    shift += listBits
+  } else {
+    root = l.pushTail(l.shift, l.root, leaf)
+  }
+  return &List{count: l.count + 1, shift: shift, root: root, tail: []Object{value}, front: l.front}
+}
+
+func (l *List) pushTail(level uint, parent *listNode, leaf *listNode) *listNode {
+  index := ((l.count - 1) >> level) & listMask
+  children := make([]*listNode, len(parent.children), index+1)
+  copy(children, parent.children)
+  var child *listNode
+  if level == listBits {
+    child = leaf
+  } else if index < len(parent.children) {
+    child = l.pushTail(level-listBits, parent.children[index], leaf)
+  } else {
+    child = newPath(level-listBits, leaf)
+  }
+  if index < len(children) {
+    children[index] = child
+  } else {
+    children = append(children, child)
+  }
+  return &listNode{children: children}
+}
+
+func newPath(level uint, node *listNode) *listNode {
+  if level == 0 {
+    return node
+  }
+  return &listNode{children: []*listNode{newPath(level-listBits, node)}}
+}
+
+// Set returns a list with value at index, only the nodes in the path to the index are copied
+func (l *List) Set(index int, value Object) *List {
+  frontLen := l.frontLen()
+  if index < frontLen {
+    return &List{count: l.count, shift: l.shift, root: l.root, tail: l.tail, front: l.front.Set(frontLen-1-index, value)}
+  }
+  index -= frontLen
+  if index >= tailOffset(l.count) {
+    tail := make([]Object, len(l.tail))
+    copy(tail, l.tail)
+    tail[index&listMask] = value
+    return &List{count: l.count, shift: l.shift, root: l.root, tail: tail, front: l.front}
+  }
+  return &List{count: l.count, shift: l.shift, root: setInNode(l.root, l.shift, index, value), tail: l.tail, front: l.front}
+}
+
+func setInNode(node *listNode, level uint, index int, value Object) *listNode {
+  if level == 0 {
+    values := make([]Object, len(node.values))
+    copy(values, node.values)
+    values[index&listMask] = value
+    return &listNode{values: values}
+  }
+  children := make([]*listNode, len(node.children))
+  copy(children, node.children)
+  childIndex := (index >> level) & listMask
+  children[childIndex] = setInNode(children[childIndex], level-listBits, index, value)
+  return &listNode{children: children}
+}
+
+// Prepend returns a list starting with value, it is appended to the front so the nodes of l are all shared
+func (l *List) Prepend(value Object) *List {
+  front := l.front
+  if front == nil {
+    front = NewList(nil)
+  }
+  return &List{count: l.count, shift: l.shift, root: l.root, tail: l.tail, front: front.Append(value)}
+}
+
+// Concat returns a list with the elements of other after the ones of l, sharing the nodes of l
+func (l *List) Concat(other *List) *List {
+  list := l
+  for _, element := range other.Elements() {
+    list = list.Append(element)
+  }
+  return list
+}
+
+// Slice returns a new list with the elements between from and to - 1
+func (l *List) Slice(from, to int) *List {
+  return NewList(l.Elements()[from:to])
+}
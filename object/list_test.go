@@ -0,0 +1,97 @@
+package object
+
+import (
+  "testing"
+)
+
+func integers(from, to int) []Object {
+  elements := make([]Object, 0)
+  for i := from; i < to; i++ {
+    elements = append(elements, &Integer{Value: int64(i)})
+  }
+  return elements
+}
+
+func checkList(t *testing.T, name string, list *List, expected []Object) {
+  if list.Len() != len(expected) {
+    t.Fatalf("%s: expected length %d and got %d", name, len(expected), list.Len())
+  }
+  elements := list.Elements()
+  for i := range expected {
+    if list.Get(i) != expected[i] || elements[i] != expected[i] {
+      t.Fatalf("%s: expected %s at %d and got %s", name, expected[i].Inspect(), i, list.Get(i).Inspect())
+    }
+  }
+}
+
+func TestListAppend(t *testing.T) {
+  // the sizes cross the tail, the first level of the trie and the growth of the root
+  for _, size := range []int{0, 1, 31, 32, 33, 64, 1024, 1056, 1057, 33 * 1024, 33*1024 + 33} {
+    elements := integers(0, size)
+    list := NewList(nil)
+    for _, element := range elements {
+      list = list.Append(element)
+    }
+    checkList(t, "append", list, elements)
+    checkList(t, "new list", NewList(elements), elements)
+  }
+}
+
+func TestListSharing(t *testing.T) {
+  elements := integers(0, 2000)
+  list := NewList(elements)
+  last := &Integer{Value: -1}
+  appended := list.Append(last)
+  set := list.Set(500, &Integer{Value: -2}).Set(1999, &Integer{Value: -3})
+  prepended := list.Prepend(&Integer{Value: -4})
+  concat := list.Concat(NewList(integers(0, 40)))
+  checkList(t, "original", list, elements)
+  checkList(t, "append", appended, append(elements[:2000:2000], last))
+  if set.Get(500).Inspect() != "-2" || set.Get(1999).Inspect() != "-3" || set.Get(501) != elements[501] {
+    t.Errorf("set changed the wrong elements")
+  }
+  if prepended.Get(0).Inspect() != "-4" || prepended.Get(2000) != elements[1999] {
+    t.Errorf("prepend did not move the elements")
+  }
+  if prepended.root != list.root {
+    t.Errorf("prepend should keep the nodes of the list")
+  }
+  if concat.Len() != 2040 || concat.Get(2039).Inspect() != "39" || concat.root.children[0] != list.root.children[0] {
+    t.Errorf("concat should keep the nodes of the first list")
+  }
+  if set.root.children[1] != list.root.children[1] {
+    t.Errorf("set should only copy the path to the index")
+  }
+  checkList(t, "slice", list.Slice(10, 100), elements[10:100])
+}
+
+func TestListPrepend(t *testing.T) {
+  for _, size := range []int{0, 1, 32, 33, 1057} {
+    elements := integers(0, size)
+    list := NewList(nil)
+    for i := size - 1; i >= 0; i-- {
+      list = list.Prepend(elements[i])
+    }
+    checkList(t, "prepend", list, elements)
+    first, last := &Integer{Value: -1}, &Integer{Value: -2}
+    mixed := NewList(elements).Prepend(first).Append(last)
+    checkList(t, "prepend and append", mixed, append(append([]Object{first}, elements...), last))
+    checkList(t, "concat", list.Concat(mixed), append(elements[:size:size], mixed.Elements()...))
+    if size != 0 {
+      changed := list.Set(0, first).Set(size-1, last)
+      expected := append([]Object{first}, elements[1:]...)
+      expected[size-1] = last
+      checkList(t, "set", changed, expected)
+      checkList(t, "slice", mixed.Slice(1, size+1), elements)
+    }
+  }
+}
+
+func BenchmarkListPrepend(b *testing.B) {
+  for i := 0; i < b.N; i++ {
+    list := NewList(nil)
+    for j := 0; j < 1000; j++ {
+      list = list.Prepend(&Integer{Value: int64(j)})
+    }
+  }
+}
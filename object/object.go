@@ -4,22 +4,30 @@ import (
   "fmt"
   "rootlang/ast"
   "bytes"
+  "rootlang/lexer"
+  "strconv"
   "strings"
+  "math"
+  "math/big"
 )
 
 type ObjectType string
 
 const (
   INTEGER_OBJ          = "INTEGER"
+  FLOAT_OBJ            = "FLOAT"
   BOOLEAN_OBJ          = "BOOLEAN"
   NULL_OBJ             = "NULL"
   RETURN_OBJ           = "RETURN"
+  TAIL_CALL_OBJ        = "TAIL_CALL"
   ERROR_OBJ            = "ERROR"
+  ERROR_VALUE_OBJ      = "ERROR_VALUE"
   FUNCTION_OBJ         = "FUNCTION"
   BUILTIN_FUNCTION_OBJ = "NATIVE_FUNCTION"
   STRING_OBJ           = "STRING"
   LIST_OBJ             = "LIST"
   DICT_OBJ             = "DICT"
+  SEQUENCE_OBJ         = "SEQUENCE"
   MODULE_OBJ           = "MODULE"
 )
 
@@ -34,10 +42,56 @@ type Object interface {
   Inspect() string
 }
 
+// HashKey is the key of a value in a dict, strings and big integers keep their text so two of them never share a key
+type HashKey struct {
+  Type  ObjectType
+  Value uint64
+  Text  string
+}
+
+type Hashable interface {
+  Object
+  HashKey() HashKey
+}
+
 
 
+// Integer is an int64 until an operation overflows, then Big holds the value and Value the closest int64 to it
 type Integer struct {
   Value int64
+  Big   *big.Int
+}
+
+// NewBigInteger returns the integer for value, it only keeps the big form when value does not fit in an int64
+func NewBigInteger(value *big.Int) *Integer {
+  if value.IsInt64() {
+    return &Integer{Value: value.Int64()}
+  }
+  if value.Sign() > 0 {
+    return &Integer{Value: math.MaxInt64, Big: value}
+  }
+  return &Integer{Value: math.MinInt64, Big: value}
+}
+
+func (integer *Integer) BigValue() *big.Int {
+  if integer.Big != nil {
+    return integer.Big
+  }
+  return big.NewInt(integer.Value)
+}
+
+// Cmp returns -1, 0 or 1 when integer is less, equal or greater than other
+func (integer *Integer) Cmp(other *Integer) int {
+  if integer.Big == nil && other.Big == nil {
+    switch {
+    case integer.Value < other.Value:
+      return -1
+    case integer.Value > other.Value:
+      return 1
+    }
+    return 0
+  }
+  return integer.BigValue().Cmp(other.BigValue())
 }
 
 func (integer *Integer) Type() ObjectType {
@@ -45,26 +99,33 @@ func (integer *Integer) Type() ObjectType {
 }
 
 func (integer *Integer) Inspect() string {
+  if integer.Big != nil {
+    return integer.Big.String()
+  }
   return fmt.Sprintf("%d", integer.Value)
 }
 
-type List struct {
-  Elements []Object
+func (integer *Integer) HashKey() HashKey {
+  if integer.Big != nil {
+    return HashKey{Type: "BIG_INTEGER", Text: integer.Big.String()}
+  }
+  return HashKey{Type: integer.Type(), Value: uint64(integer.Value)}
 }
 
-func (l *List) Type() ObjectType {
-  return LIST_OBJ
+type Float struct {
+  Value float64
 }
 
-func (l *List) Inspect() string {
-  buffer := bytes.NewBufferString("[")
-  elements := make([]string, 0)
-  for _, element := range l.Elements {
-    elements = append(elements, element.Inspect())
+func (float *Float) Type() ObjectType {
+  return FLOAT_OBJ
+}
+
+func (float *Float) Inspect() string {
+  text := strconv.FormatFloat(float.Value, 'g', -1, 64)
+  if strings.ContainsAny(text, ".eIN") {
+    return text
   }
-  buffer.WriteString(strings.Join(elements, ","))
-  buffer.WriteString("]")
-  return buffer.String()
+  return text + ".0"
 }
 
 type Boolean struct {
@@ -73,6 +134,12 @@ type Boolean struct {
 
 func (b *Boolean) Type() ObjectType { return BOOLEAN_OBJ }
 func (b *Boolean) Inspect() string  { return fmt.Sprintf("%t", b.Value) }
+func (b *Boolean) HashKey() HashKey {
+  if b.Value {
+    return HashKey{Type: b.Type(), Value: 1}
+  }
+  return HashKey{Type: b.Type(), Value: 0}
+}
 
 type String struct {
   Value string
@@ -80,6 +147,84 @@ type String struct {
 
 func (s *String) Type() ObjectType { return STRING_OBJ }
 func (s *String) Inspect() string  { return s.Value }
+func (s *String) HashKey() HashKey {
+  return HashKey{Type: s.Type(), Text: s.Value}
+}
+
+type DictPair struct {
+  Key   Object
+  Value Object
+}
+
+type Dict struct {
+  Pairs map[HashKey]DictPair
+  Keys  []HashKey
+}
+
+func NewDict() *Dict {
+  return &Dict{Pairs: make(map[HashKey]DictPair), Keys: make([]HashKey, 0)}
+}
+
+func (d *Dict) Type() ObjectType { return DICT_OBJ }
+func (d *Dict) Inspect() string {
+  buffer := bytes.NewBufferString("{")
+  pairs := make([]string, 0)
+  for _, key := range d.Keys {
+    pair := d.Pairs[key]
+    pairs = append(pairs, fmt.Sprintf("%s:%s", pair.Key.Inspect(), pair.Value.Inspect()))
+  }
+  buffer.WriteString(strings.Join(pairs, ","))
+  buffer.WriteString("}")
+  return buffer.String()
+}
+
+func (d *Dict) Get(key Hashable) (Object, bool) {
+  pair, ok := d.Pairs[key.HashKey()]
+  if !ok {
+    return nil, false
+  }
+  return pair.Value, true
+}
+
+func (d *Dict) Set(key Hashable, value Object) {
+  hashKey := key.HashKey()
+  if _, ok := d.Pairs[hashKey]; !ok {
+    d.Keys = append(d.Keys, hashKey)
+  }
+  d.Pairs[hashKey] = DictPair{Key: key, Value: value}
+}
+
+func (d *Dict) Delete(key Hashable) {
+  hashKey := key.HashKey()
+  if _, ok := d.Pairs[hashKey]; !ok {
+    return
+  }
+  delete(d.Pairs, hashKey)
+  for i, k := range d.Keys {
+    if k == hashKey {
+      d.Keys = append(d.Keys[:i:i], d.Keys[i+1:]...)
+      break
+    }
+  }
+}
+
+func (d *Dict) Copy() *Dict {
+  dict := &Dict{Pairs: make(map[HashKey]DictPair, len(d.Pairs)), Keys: make([]HashKey, len(d.Keys))}
+  copy(dict.Keys, d.Keys)
+  for key, pair := range d.Pairs {
+    dict.Pairs[key] = pair
+  }
+  return dict
+}
+
+// Sequence is a lazy list of values, Iterator starts a new pass over them so a sequence can be consumed many times.
+// The function of a pass returns false once the sequence ends and an *ErrorObject value stops it
+type Sequence struct {
+  Iterator func() func() (Object, bool)
+}
+
+func (s *Sequence) Type() ObjectType { return SEQUENCE_OBJ }
+func (s *Sequence) Inspect() string  { return "sequence" }
 
 type Null struct{}
 
@@ -91,40 +236,152 @@ type ReturnObject struct{ Value Object }
 func (r *ReturnObject) Type() ObjectType { return RETURN_OBJ }
 func (r *ReturnObject) Inspect() string  { return r.Inspect() }
 
+// TailCall is a call in return position, it is run by the caller loop instead of growing the stack
+type TailCall struct {
+  Function *Function
+  Params   []Object
+  Position lexer.Position
+}
+
+func (t *TailCall) Type() ObjectType { return TAIL_CALL_OBJ }
+func (t *TailCall) Inspect() string  { return fmt.Sprintf("tail call %s", t.Function.Inspect()) }
+
+type StackFrame struct {
+  Function string
+  Module   string
+  Position lexer.Position
+}
+
+func (frame StackFrame) String() string {
+  name := frame.Function
+  if name == "" {
+    name = "<lambda>"
+  }
+  if frame.Module != "" {
+    name = fmt.Sprintf("%s::%s", frame.Module, name)
+  }
+  if !frame.Position.IsValid() {
+    return fmt.Sprintf("at %s (native)", name)
+  }
+  return fmt.Sprintf("at %s (%s)", name, frame.Position)
+}
+
 type ErrorObject struct {
-  Error string
+  Error    string
+  Position lexer.Position
+  Stack    []StackFrame
+  callSite lexer.Position
+}
+
+// AddFrame records the function the error is leaving, callPosition is where that function was called from
+func (e *ErrorObject) AddFrame(function, module string, callPosition lexer.Position) {
+  position := e.Position
+  if len(e.Stack) > 0 {
+    position = e.callSite
+  }
+  e.Stack = append(e.Stack, StackFrame{Function: function, Module: module, Position: position})
+  e.callSite = callPosition
+}
+
+func (e *ErrorObject) StackTrace() string {
+  buffer := bytes.NewBufferString("")
+  for _, frame := range e.Stack {
+    buffer.WriteString("  ")
+    buffer.WriteString(frame.String())
+    buffer.WriteString("\n")
+  }
+  return buffer.String()
 }
 
 func (e *ErrorObject) Type() ObjectType { return ERROR_OBJ }
-func (e *ErrorObject) Inspect() string  { return e.Error }
+func (e *ErrorObject) Inspect() string  {
+  if !e.Position.IsValid() {
+    return e.Error
+  }
+  return fmt.Sprintf("%s: %s", e.Position, e.Error)
+}
 
+// ErrorValue is an error caught by a try expression or built with the error builtin, unlike ErrorObject it does not stop the evaluation
+type ErrorValue struct {
+  Message  string
+  Position lexer.Position
+  Stack    []StackFrame
+}
+
+func (e *ErrorValue) Type() ObjectType { return ERROR_VALUE_OBJ }
+func (e *ErrorValue) Inspect() string  {
+  if !e.Position.IsValid() {
+    return fmt.Sprintf("error(%s)", e.Message)
+  }
+  return fmt.Sprintf("error(%s: %s)", e.Position, e.Message)
+}
+
+// Module with Exports only lets the modules that import it use those names, every name is visible when Exports is nil
 type Module struct{
   Path string
   Name string
   Env    *Environment
+  Exports map[string]bool
 }
 
+func (m *Module) IsExported(name string) bool {
+  return m.Exports == nil || m.Exports[name]
+}
 
 func (m *Module) Type() ObjectType { return MODULE_OBJ }
 func (m *Module) Inspect() string  {
   buffer := bytes.NewBufferString("[")
   keys := make([]string, 0)
   for key := range m.Env.vars{
-    keys  = append(keys, key)
+    if m.IsExported(key) {
+      keys  = append(keys, key)
+    }
   }
   buffer.WriteString(strings.Join(keys, ","))
   buffer.WriteString("]")
   return buffer.String()
 }
 
+// Function with Locals runs its body in a frame with those slots, Bound are the params given by a partial application
 type Function struct {
+  Name   string
   Params []*ast.Identifier
   Body   *ast.BlockStatement
   Env    *Environment
+  Locals []string
+  Bound  []Object
 }
 
 func (f *Function) Clone(newParams []*ast.Identifier, env *Environment) *Function {
-  return &Function{Body:f.Body, Env:env, Params:newParams}
+  return &Function{Name:f.Name, Body:f.Body, Env:env, Params:newParams}
+}
+
+// CallEnvironment returns the environment where the body runs with the params bound
+func (f *Function) CallEnvironment(params []Object) *Environment {
+  if f.Locals == nil {
+    newEnvironment := f.Env.ExtendNewEnvironment()
+    for i := 0; i < len(params); i++ {
+      newEnvironment.SetVar(f.Params[i].Value, params[i])
+    }
+    return newEnvironment
+  }
+  frame := NewFrame(f.Env, f.Locals)
+  for i, param := range f.Bound {
+    frame.SetSlot(i, param)
+  }
+  for i, param := range params {
+    frame.SetSlot(len(f.Bound)+i, param)
+  }
+  return frame
+}
+
+// Partial returns the function waiting for the params left after a call with fewer params than it takes
+func (f *Function) Partial(params []Object) *Function {
+  if f.Locals == nil {
+    return f.Clone(f.Params[len(params):], f.CallEnvironment(params))
+  }
+  bound := append(append(make([]Object, 0, len(f.Bound)+len(params)), f.Bound...), params...)
+  return &Function{Name:f.Name, Body:f.Body, Env:f.Env, Params:f.Params[len(params):], Locals:f.Locals, Bound:bound}
 }
 
 func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
@@ -144,11 +401,30 @@ func (f *Function) Inspect() string {
   return buffer.String()
 }
 
+// Callable is a function value run outside the tree walking evaluator, like the closures of the vm
+type Callable interface {
+  Object
+  FunctionName() string
+  Params() []*ast.Identifier
+  Call(params ...Object) Object
+}
+
+// Environment is a map of names for the top level of programs and modules and a frame of slots for calls and match arms
 type Environment struct {
   vars  map[string]Object
+  slots []Object
+  names []string
   outer *Environment
 }
 
+// nilSlot marks a slot bound to nil so it is not taken as a slot that was never set
+type nilSlot struct{}
+
+func (n *nilSlot) Type() ObjectType { return "NIL_SLOT" }
+func (n *nilSlot) Inspect() string  { return "nil" }
+
+var nilValue = &nilSlot{}
+
 func (e*Environment) ExtendNewEnvironment() *Environment {
   newEnvironment := NewEnvironment()
   newEnvironment.outer = e
@@ -159,16 +435,77 @@ func NewEnvironment() *Environment {
   return &Environment{vars:make(map[string]Object), outer:nil}
 }
 
+// NewFrame returns an environment with a slot for each name, the names are shared with the resolved program
+func NewFrame(outer *Environment, names []string) *Environment {
+  return &Environment{slots:make([]Object, len(names)), names:names, outer:outer}
+}
+
 func (e*Environment) GetVar(name string) (Object, bool) {
-  value, ok := e.vars[name]
-  if ok {
+  if e.vars == nil {
+    for i, slotName := range e.names {
+      if slotName == name && e.slots[i] != nil {
+        return slotValue(e.slots[i]), true
+      }
+    }
+  } else if value, ok := e.vars[name]; ok {
     return value, ok
   }
   if e.outer != nil {
-    value, ok = e.outer.GetVar(name)
+    return e.outer.GetVar(name)
   }
-  return value, ok
+  return nil, false
 }
+
 func (e*Environment) SetVar(name string, value Object) {
-  e.vars[name] = value
+  if e.vars != nil {
+    e.vars[name] = value
+    return
+  }
+  for i, slotName := range e.names {
+    if slotName == name {
+      e.SetSlot(i, value)
+      return
+    }
+  }
+  e.names = append(e.names[:len(e.names):len(e.names)], name)
+  e.slots = append(e.slots, nil)
+  e.SetSlot(len(e.slots)-1, value)
+}
+
+// GetSlot reads the slot of the frame depth environments away, a slot not set yet is looked up by name from the outer environments
+func (e*Environment) GetSlot(depth, slot int, name string) (Object, bool) {
+  frame := e
+  for ; depth > 0; depth-- {
+    frame = frame.outer
+  }
+  if value := frame.slots[slot]; value != nil {
+    return slotValue(value), true
+  }
+  if frame.outer == nil {
+    return nil, false
+  }
+  return frame.outer.GetVar(name)
+}
+
+func (e*Environment) SetSlot(slot int, value Object) {
+  if value == nil {
+    value = nilValue
+  }
+  e.slots[slot] = value
+}
+
+// GetGlobal looks up a name in the top level environment
+func (e*Environment) GetGlobal(name string) (Object, bool) {
+  global := e
+  for global.outer != nil {
+    global = global.outer
+  }
+  return global.GetVar(name)
+}
+
+func slotValue(value Object) Object {
+  if value == nilValue {
+    return nil
+  }
+  return value
 }
@@ -0,0 +1,33 @@
+package object
+
+import (
+  "math/big"
+  "testing"
+)
+
+func TestDictKeys(t *testing.T) {
+  big1, _ := new(big.Int).SetString("18446744073709551616", 10)
+  keys := []Hashable{
+    &String{Value: "a"}, &String{Value: "b"}, &String{Value: ""}, &String{Value: "1"},
+    &Integer{Value: 1}, &Integer{Value: 0}, TRUE, FALSE,
+    NewBigInteger(big1), NewBigInteger(new(big.Int).Neg(big1)),
+  }
+  dict := NewDict()
+  for i, key := range keys {
+    dict.Set(key, &Integer{Value: int64(i)})
+  }
+  if len(dict.Keys) != len(keys) {
+    t.Fatalf("expected %d keys and got %d", len(keys), len(dict.Keys))
+  }
+  same := []Hashable{
+    &String{Value: "a"}, &String{Value: "b"}, &String{Value: ""}, &String{Value: "1"},
+    &Integer{Value: 1}, &Integer{Value: 0}, &Boolean{Value: true}, &Boolean{Value: false},
+    NewBigInteger(new(big.Int).Set(big1)), NewBigInteger(new(big.Int).Neg(big1)),
+  }
+  for i, key := range same {
+    value, ok := dict.Get(key)
+    if !ok || value.Inspect() != (&Integer{Value: int64(i)}).Inspect() {
+      t.Errorf("expected %d for key %s and got %v", i, key.Inspect(), value)
+    }
+  }
+}
@@ -0,0 +1,292 @@
+// Package optimizer rewrites a program before it runs, folding constant expressions, removing the branches of an if
+// whose condition is a literal and inlining the calls to the identity function. Any rewrite that could change the
+// result or the error of the program, like an integer division by zero, is left to be done at runtime.
+package optimizer
+
+import (
+	"rootlang/ast"
+	"rootlang/lexer"
+	"fmt"
+	"math/big"
+)
+
+// Optimize rewrites the program in place and returns it, the new nodes keep the position of the nodes they replace
+func Optimize(program *ast.Program) *ast.Program {
+	program.Statements = optimizeStatements(program.Statements)
+	return program
+}
+
+// optimizeStatements splices the chosen block of an if statement in the list, blocks share the environment so the
+// names it declares are still visible after it
+func optimizeStatements(statements []ast.Statement) []ast.Statement {
+	optimized := make([]ast.Statement, 0, len(statements))
+	for i, statement := range statements {
+		statement = optimizeStatement(statement)
+		last := i == len(statements)-1
+		if expressionStatement, ok := statement.(*ast.ExpressionStatement); ok {
+			if ifExpression, ok := expressionStatement.Exp.(*ast.IfExpression); ok {
+				if block, known := chosenBlock(ifExpression); known {
+					if block == nil || len(block.Statements) == 0 {
+						if !last {
+							continue
+						}
+					} else {
+						optimized = append(optimized, block.Statements...)
+						continue
+					}
+				}
+			}
+		}
+		optimized = append(optimized, statement)
+	}
+	return optimized
+}
+
+func optimizeStatement(statement ast.Statement) ast.Statement {
+	switch statementType := statement.(type) {
+	case *ast.LetStatement:
+		statementType.Value = optimizeExpression(statementType.Value)
+	case *ast.ReturnStatement:
+		statementType.Value = optimizeExpression(statementType.Value)
+	case *ast.ExpressionStatement:
+		statementType.Exp = optimizeExpression(statementType.Exp)
+	case *ast.BlockStatement:
+		optimizeBlock(statementType)
+	}
+	return statement
+}
+
+func optimizeBlock(block *ast.BlockStatement) {
+	if block != nil {
+		block.Statements = optimizeStatements(block.Statements)
+	}
+}
+
+func optimizeExpressions(expressions []ast.Expression) {
+	for i := range expressions {
+		expressions[i] = optimizeExpression(expressions[i])
+	}
+}
+
+func optimizeExpression(expression ast.Expression) ast.Expression {
+	switch expressionType := expression.(type) {
+	case *ast.PrefixExpression:
+		expressionType.RightExpression = optimizeExpression(expressionType.RightExpression)
+		return foldPrefix(expressionType)
+	case *ast.InfixExpression:
+		expressionType.LeftExpression = optimizeExpression(expressionType.LeftExpression)
+		if expressionType.Operator == "::" {
+			if call, ok := expressionType.RightExpression.(*ast.CallFunctionExpression); ok {
+				optimizeExpressions(call.Arguments)
+			}
+			return expressionType
+		}
+		expressionType.RightExpression = optimizeExpression(expressionType.RightExpression)
+		return foldInfix(expressionType)
+	case *ast.IfExpression:
+		expressionType.Condition = optimizeExpression(expressionType.Condition)
+		optimizeBlock(expressionType.ConditionalBlock)
+		optimizeBlock(expressionType.AlternativeBlock)
+		return removeDeadBranch(expressionType)
+	case *ast.FunctionExpression:
+		optimizeBlock(expressionType.Block)
+	case *ast.CallFunctionExpression:
+		expressionType.Function = optimizeExpression(expressionType.Function)
+		optimizeExpressions(expressionType.Arguments)
+		if isIdentity(expressionType.Function) && len(expressionType.Arguments) == 1 {
+			return expressionType.Arguments[0]
+		}
+	case *ast.PipeExpression:
+		expressionType.Left = optimizeExpression(expressionType.Left)
+		expressionType.Right = optimizeExpression(expressionType.Right)
+		if isIdentity(expressionType.Right) {
+			return expressionType.Left
+		}
+	case *ast.TryExpression:
+		optimizeBlock(expressionType.Block)
+		optimizeBlock(expressionType.CatchBlock)
+	case *ast.MatchExpression:
+		expressionType.Value = optimizeExpression(expressionType.Value)
+		for _, arm := range expressionType.Arms {
+			if arm.Guard != nil {
+				arm.Guard = optimizeExpression(arm.Guard)
+			}
+			if block, ok := arm.Body.(*ast.BlockStatement); ok {
+				optimizeBlock(block)
+			} else if body, ok := arm.Body.(ast.Expression); ok {
+				arm.Body = optimizeExpression(body)
+			}
+		}
+	case *ast.ListLiteral:
+		optimizeExpressions(expressionType.Elements)
+	case *ast.DictLiteral:
+		optimizeExpressions(expressionType.Keys)
+		optimizeExpressions(expressionType.Values)
+	case *ast.IndexExpression:
+		expressionType.Left = optimizeExpression(expressionType.Left)
+		expressionType.Index = optimizeExpression(expressionType.Index)
+	case *ast.SliceExpression:
+		expressionType.Left = optimizeExpression(expressionType.Left)
+		if expressionType.Start != nil {
+			expressionType.Start = optimizeExpression(expressionType.Start)
+		}
+		if expressionType.End != nil {
+			expressionType.End = optimizeExpression(expressionType.End)
+		}
+	}
+	return expression
+}
+
+func foldPrefix(prefix *ast.PrefixExpression) ast.Expression {
+	switch right := prefix.RightExpression.(type) {
+	case *ast.IntegerLiteral:
+		if right.Big != nil {
+			return prefix
+		}
+		switch prefix.Operator {
+		case "-":
+			return foldedInteger(prefix, new(big.Int).Neg(big.NewInt(right.Value)))
+		case "!":
+			return boolLiteral(right.Value == 0, prefix.Position())
+		}
+	case *ast.BoolExpression:
+		if prefix.Operator == "!" {
+			return boolLiteral(right.Value != "true", prefix.Position())
+		}
+	}
+	return prefix
+}
+
+// foldInfix folds the operations the evaluator defines on two literals of the same type, string == compares the
+// objects and not the text so it is never folded
+func foldInfix(infix *ast.InfixExpression) ast.Expression {
+	position := infix.Position()
+	switch left := infix.LeftExpression.(type) {
+	case *ast.IntegerLiteral:
+		right, ok := infix.RightExpression.(*ast.IntegerLiteral)
+		if !ok || left.Big != nil || right.Big != nil {
+			return infix
+		}
+		leftBig, rightBig := big.NewInt(left.Value), big.NewInt(right.Value)
+		switch infix.Operator {
+		case "+":
+			return foldedInteger(infix, new(big.Int).Add(leftBig, rightBig))
+		case "-":
+			return foldedInteger(infix, new(big.Int).Sub(leftBig, rightBig))
+		case "*":
+			return foldedInteger(infix, new(big.Int).Mul(leftBig, rightBig))
+		case "/":
+			if right.Value != 0 {
+				return foldedInteger(infix, new(big.Int).Quo(leftBig, rightBig))
+			}
+		case "%":
+			if right.Value != 0 {
+				return foldedInteger(infix, new(big.Int).Rem(leftBig, rightBig))
+			}
+		case "==":
+			return boolLiteral(left.Value == right.Value, position)
+		case "!=":
+			return boolLiteral(left.Value != right.Value, position)
+		case "<":
+			return boolLiteral(left.Value < right.Value, position)
+		case ">":
+			return boolLiteral(left.Value > right.Value, position)
+		}
+	case *ast.StringExpression:
+		right, ok := infix.RightExpression.(*ast.StringExpression)
+		if !ok {
+			return infix
+		}
+		switch infix.Operator {
+		case "+":
+			return &ast.StringExpression{Token: lexer.Token{Type: lexer.STRING, Literal: left.Value + right.Value, Position: position}, Value: left.Value + right.Value}
+		case "!=":
+			return boolLiteral(left.Value != right.Value, position)
+		}
+	case *ast.BoolExpression:
+		right, ok := infix.RightExpression.(*ast.BoolExpression)
+		if !ok {
+			return infix
+		}
+		switch infix.Operator {
+		case "==":
+			return boolLiteral(left.Value == right.Value, position)
+		case "!=":
+			return boolLiteral(left.Value != right.Value, position)
+		}
+	}
+	return infix
+}
+
+// removeDeadBranch replaces an if whose condition is a literal by the chosen branch, when it is a single expression,
+// or by an if that only keeps the chosen branch
+func removeDeadBranch(ifExpression *ast.IfExpression) ast.Expression {
+	block, known := chosenBlock(ifExpression)
+	if !known || block == nil {
+		return ifExpression
+	}
+	if len(block.Statements) == 1 {
+		if statement, ok := block.Statements[0].(*ast.ExpressionStatement); ok {
+			return statement.Exp
+		}
+	}
+	condition := boolLiteral(true, ifExpression.Condition.Position())
+	return &ast.IfExpression{Token: ifExpression.Token, Condition: condition, ConditionalBlock: block}
+}
+
+// chosenBlock returns the branch an if with a literal condition runs, known is false when the condition is not a literal
+func chosenBlock(ifExpression *ast.IfExpression) (*ast.BlockStatement, bool) {
+	var truth bool
+	switch condition := ifExpression.Condition.(type) {
+	case *ast.BoolExpression:
+		truth = condition.Value == "true"
+	case *ast.IntegerLiteral:
+		truth = condition.Value != 0
+	case *ast.FloatLiteral:
+		truth = condition.Value != 0
+	case *ast.StringExpression:
+		truth = false
+	default:
+		return nil, false
+	}
+	if truth {
+		return ifExpression.ConditionalBlock, true
+	}
+	return ifExpression.AlternativeBlock, true
+}
+
+// isIdentity tells if the expression is a function literal that returns its only param
+func isIdentity(expression ast.Expression) bool {
+	function, ok := expression.(*ast.FunctionExpression)
+	if !ok || len(function.Params) != 1 || len(function.Block.Statements) != 1 {
+		return false
+	}
+	var body ast.Expression
+	switch statement := function.Block.Statements[0].(type) {
+	case *ast.ReturnStatement:
+		body = statement.Value
+	case *ast.ExpressionStatement:
+		body = statement.Exp
+	}
+	identifier, ok := body.(*ast.Identifier)
+	return ok && identifier.Value == function.Params[0].Value
+}
+
+// foldedInteger replaces the expression by the literal of value, results that overflow an int64 are computed at runtime
+func foldedInteger(expression ast.Expression, value *big.Int) ast.Expression {
+	if !value.IsInt64() {
+		return expression
+	}
+	return integerLiteral(value.Int64(), expression.Position())
+}
+
+func integerLiteral(value int64, position lexer.Position) *ast.IntegerLiteral {
+	return &ast.IntegerLiteral{Token: lexer.Token{Type: lexer.INT, Literal: fmt.Sprintf("%d", value), Position: position}, Value: value}
+}
+
+func boolLiteral(value bool, position lexer.Position) *ast.BoolExpression {
+	if value {
+		return &ast.BoolExpression{Token: lexer.Token{Type: lexer.TRUE, Literal: "true", Position: position}, Value: "true"}
+	}
+	return &ast.BoolExpression{Token: lexer.Token{Type: lexer.FALSE, Literal: "false", Position: position}, Value: "false"}
+}
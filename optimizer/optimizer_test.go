@@ -0,0 +1,60 @@
+package optimizer
+
+import (
+	"testing"
+	"rootlang/ast"
+	"rootlang/lexer"
+	"rootlang/parser"
+)
+
+func optimize(input string) *ast.Program {
+	l := lexer.NewWithFile(input, "main.rl")
+	return Optimize(parser.New(l).ParseProgram())
+}
+
+func TestOptimize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`1 + 2 * 3`, `7;`},
+		{`-(4 - 10) % 4`, `2;`},
+		{`let x = 1 < 2;`, `let x = true;`},
+		{`!true == false`, `true;`},
+		{`!0`, `true;`},
+		{`"a" + "b" + "c"`, `abc;`},
+		{`"a" != "b"`, `true;`},
+		{`"a" == "a"`, `(a == a);`},
+		{`1 / 0`, `(1 / 0);`},
+		{`10 % (2 - 2)`, `(10 % 0);`},
+		{`x + 1 * 2`, `(x + 2);`},
+		{`9223372036854775807 + 1`, `(9223372036854775807 + 1);`},
+		{`-(2 * 4611686018427387904)`, `-((2 * 4611686018427387904));`},
+		{`let y = if (1 > 2) { 1 } else { 2 };`, `let y = 2;`},
+		{`let y = if (false) { 1 };`, `let y = if(false){1;};`},
+		{`if (true) { let a = 1; a } else { 2 }; a`, `let a = 1;a;a;`},
+		{`if (0) { 1 }; 2`, `2;`},
+		{`1; if (0) { 1 }`, `1;if(0){1;};`},
+		{`let f = () => { if (true) { return 1; } 2 };`, `let f = ()=>{return 1;2;};`},
+		{`let z = if ("") { 1 } else { let b = 2; b };`, `let z = if(true){let b = 2;b;};`},
+		{`(x => x)(f(1))`, `f(1);`},
+		{`3 |> (x => x) |> f`, `(3 |> f);`},
+		{`(x => x + 0)(1)`, `(x)=>{return (x + 0);}(1);`},
+		{`match 1 + 1 { n if n > 1 + 1 => 2 * 2, _ => 0 }`, `match(2){n if (n > 2) => 4, _ => 0};`},
+	}
+	for _, test := range tests {
+		program := optimize(test.input)
+		if program.String() != test.expected {
+			t.Errorf("%s: expected %s and got %s", test.input, test.expected, program.String())
+		}
+	}
+}
+
+func TestFoldedPosition(t *testing.T) {
+	program := optimize("let x = 1;\nlet y = x + 2 * 3;")
+	infix := program.Statements[1].(*ast.LetStatement).Value.(*ast.InfixExpression)
+	folded := infix.RightExpression.(*ast.IntegerLiteral)
+	if folded.Position().String() != "main.rl:2:15" {
+		t.Errorf("expected the folded literal at main.rl:2:15 and got %s", folded.Position().String())
+	}
+}
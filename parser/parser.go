@@ -6,16 +6,21 @@ import (
 	"strconv"
 	"fmt"
 	"strings"
+	"math"
+	"math/big"
 )
 
 const (
 	_        int = iota
 	LOWEST
+	PIPE
 	EQUALS
+	COMPOSE
 	SUM
 	PRODUCT
 	PREFIX
 	CALL
+	INDEX
 	FUNCTION
 )
 
@@ -23,6 +28,8 @@ var precedences = map[lexer.TokenType]int{lexer.EQUAL: EQUALS,
 	lexer.NOTEQUAL:                                EQUALS,
 	lexer.LESSTHAN:                                EQUALS,
 	lexer.MORETHAN:                                EQUALS,
+	lexer.COMPOSE:                                 COMPOSE,
+	lexer.PIPE:                                    PIPE,
 	lexer.PLUS:                                    SUM,
 	lexer.MINUS:                                   SUM,
 	lexer.MODULE:                                  PRODUCT,
@@ -30,6 +37,7 @@ var precedences = map[lexer.TokenType]int{lexer.EQUAL: EQUALS,
 	lexer.MOD:                                     PRODUCT,
 	lexer.DIV:                                     PRODUCT,
 	lexer.LPAREN:                                  CALL,
+	lexer.LBRACKET:                                INDEX,
 	lexer.FUNCTION:                                FUNCTION,
 
 }
@@ -46,6 +54,7 @@ type Parser struct {
 	l               *lexer.Lexer
 	curToken        lexer.Token
 	peekToken       lexer.Token
+	inMatchGuard    bool
 }
 
 func New(l *lexer.Lexer) *Parser {
@@ -65,6 +74,7 @@ func (p *Parser) GetErrors() []string {
 
 func (p *Parser) registerPrefixFunction() {
 	p.prefixFunctions[lexer.INT] = p.parseIntExpression
+	p.prefixFunctions[lexer.FLOAT] = p.parseFloatExpression
 	p.prefixFunctions[lexer.IDENT] = p.parseIdentifierExpression
 	p.prefixFunctions[lexer.STRING] = p.parseStringExpression
 	p.prefixFunctions[lexer.MINUS] = p.parsePrefixExpression
@@ -73,6 +83,10 @@ func (p *Parser) registerPrefixFunction() {
 	p.prefixFunctions[lexer.FALSE] = p.parseBoolExpression
 	p.prefixFunctions[lexer.LPAREN] = p.parseGroupedExpression
 	p.prefixFunctions[lexer.IF] = p.parseIfExpression
+	p.prefixFunctions[lexer.TRY] = p.parseTryExpression
+	p.prefixFunctions[lexer.MATCH] = p.parseMatchExpression
+	p.prefixFunctions[lexer.LBRACE] = p.parseDictExpression
+	p.prefixFunctions[lexer.LBRACKET] = p.parseListExpression
 
 }
 
@@ -83,29 +97,29 @@ func (p *Parser) parseStringExpression() ast.Expression {
 func (p *Parser) parseIfExpression() ast.Expression {
 	ifExpression := &ast.IfExpression{Token: p.curToken}
 	if !p.isNextTokenExpected(lexer.LPAREN) {
-		p.errors = append(p.errors, "left parent is expected in if expression")
+		p.addError(p.peekToken, "left parent is expected in if expression")
 		return nil
 	}
 	p.nextToken()
 	p.nextToken()
 	condition := p.parseExpression(LOWEST)
 	if condition == nil {
-		p.errors = append(p.errors, "condition is required on if expression")
+		p.addError(p.curToken, "condition is required on if expression")
 		return nil
 	}
 	if !p.isNextTokenExpected(lexer.RPAREN) {
-		p.errors = append(p.errors, "right parent is expected in if expression")
+		p.addError(p.peekToken, "right parent is expected in if expression")
 		return nil
 	}
 	p.nextToken()
 	if !p.isNextTokenExpected(lexer.LBRACE) {
-		p.errors = append(p.errors, "block for if expression is required")
+		p.addError(p.peekToken, "block for if expression is required")
 		return nil
 	}
 	p.nextToken()
 	conditionalBlock := p.parserBlockStatement()
 	if conditionalBlock == nil {
-		p.errors = append(p.errors, "block for if expression is required")
+		p.addError(p.curToken, "block for if expression is required")
 		return nil
 	}
 	ifExpression.Condition = condition
@@ -115,29 +129,254 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	}
 	p.nextToken()
 	if !p.isNextTokenExpected(lexer.LBRACE) {
-		p.errors = append(p.errors, "begin of block for else expression is expected")
+		p.addError(p.peekToken, "begin of block for else expression is expected")
 		return nil
 	}
 	p.nextToken()
 	alternativeBlock := p.parserBlockStatement()
 	if alternativeBlock == nil {
-		p.errors = append(p.errors, "block for else is expected")
+		p.addError(p.curToken, "block for else is expected")
 		return nil
 	}
 	ifExpression.AlternativeBlock = alternativeBlock.(*ast.BlockStatement)
 	return ifExpression
 }
 
+func (p *Parser) parseTryExpression() ast.Expression {
+	tryExpression := &ast.TryExpression{Token: p.curToken}
+	if !p.moveNextTokenExpected(lexer.LBRACE) {
+		p.addError(p.peekToken, "block for try expression is required")
+		return nil
+	}
+	block, ok := p.parserBlockStatement().(*ast.BlockStatement)
+	if !ok {
+		p.addError(p.curToken, "block for try expression is required")
+		return nil
+	}
+	tryExpression.Block = block
+	if !p.moveNextTokenExpected(lexer.CATCH) {
+		p.addError(p.peekToken, "catch is expected after try block")
+		return nil
+	}
+	if p.moveNextTokenExpected(lexer.LPAREN) {
+		if !p.moveNextTokenExpected(lexer.IDENT) {
+			p.addError(p.peekToken, "identifier is expected in catch")
+			return nil
+		}
+		tryExpression.ErrorName = p.parseIdentifierExpression().(*ast.Identifier)
+		if !p.moveNextTokenExpected(lexer.RPAREN) {
+			p.addError(p.peekToken, "right parent is expected in catch")
+			return nil
+		}
+	}
+	if !p.moveNextTokenExpected(lexer.LBRACE) {
+		p.addError(p.peekToken, "block for catch is required")
+		return nil
+	}
+	catchBlock, ok := p.parserBlockStatement().(*ast.BlockStatement)
+	if !ok {
+		p.addError(p.curToken, "block for catch is required")
+		return nil
+	}
+	tryExpression.CatchBlock = catchBlock
+	return tryExpression
+}
+
+func (p *Parser) parseMatchExpression() ast.Expression {
+	matchExpression := &ast.MatchExpression{Token: p.curToken, Arms: make([]*ast.MatchArm, 0)}
+	p.nextToken()
+	value := p.parseExpression(LOWEST)
+	if value == nil {
+		p.addError(p.curToken, "value is expected in match expression")
+		return nil
+	}
+	matchExpression.Value = value
+	if !p.moveNextTokenExpected(lexer.LBRACE) {
+		p.addError(p.peekToken, "begin of block for match expression is expected")
+		return nil
+	}
+	for !p.isNextTokenExpected(lexer.RBRACE) {
+		p.nextToken()
+		arm := p.parseMatchArm()
+		if arm == nil {
+			return nil
+		}
+		matchExpression.Arms = append(matchExpression.Arms, arm)
+		p.moveNextTokenExpected(lexer.COMMA)
+	}
+	p.nextToken()
+	if len(matchExpression.Arms) == 0 {
+		p.addError(p.curToken, "at least one pattern is expected in match expression")
+		return nil
+	}
+	return matchExpression
+}
+
+func (p *Parser) parseMatchArm() *ast.MatchArm {
+	pattern := p.parsePattern()
+	if pattern == nil {
+		return nil
+	}
+	arm := &ast.MatchArm{Pattern: pattern}
+	if p.moveNextTokenExpected(lexer.IF) {
+		p.nextToken()
+		inMatchGuard := p.inMatchGuard
+		p.inMatchGuard = true
+		arm.Guard = p.parseExpression(LOWEST)
+		p.inMatchGuard = inMatchGuard
+		if arm.Guard == nil {
+			p.addError(p.curToken, "condition is required on match guard")
+			return nil
+		}
+	}
+	if !p.moveNextTokenExpected(lexer.FUNCTION) {
+		p.addError(p.peekToken, "=> is expected after pattern")
+		return nil
+	}
+	p.nextToken()
+	if p.isTokenExpected(lexer.LBRACE) {
+		block, ok := p.parserBlockStatement().(*ast.BlockStatement)
+		if !ok {
+			p.addError(p.curToken, "block for match arm is expected")
+			return nil
+		}
+		arm.Body = block
+		return arm
+	}
+	body := p.parseExpression(LOWEST)
+	if body == nil {
+		p.addError(p.curToken, "expression is expected in match arm")
+		return nil
+	}
+	arm.Body = body
+	return arm
+}
+
+func (p *Parser) parsePattern() ast.Pattern {
+	token := p.curToken
+	switch token.Type {
+	case lexer.INT, lexer.FLOAT, lexer.STRING, lexer.TRUE, lexer.FALSE:
+		value := p.prefixFunctions[token.Type]()
+		if value == nil {
+			return nil
+		}
+		return &ast.LiteralPattern{Token: token, Value: value}
+	case lexer.MINUS:
+		if !p.isNextTokenExpected(lexer.INT) && !p.isNextTokenExpected(lexer.FLOAT) {
+			p.addError(p.peekToken, "number is expected in negative pattern")
+			return nil
+		}
+		p.nextToken()
+		number := p.prefixFunctions[p.curToken.Type]()
+		if number == nil {
+			return nil
+		}
+		value := &ast.PrefixExpression{Token: token, Operator: token.Literal, RightExpression: number}
+		return &ast.LiteralPattern{Token: token, Value: value}
+	case lexer.IDENT:
+		if token.Literal == "_" {
+			return &ast.WildcardPattern{Token: token}
+		}
+		return &ast.IdentifierPattern{Token: token, Name: p.parseIdentifierExpression().(*ast.Identifier)}
+	case lexer.LBRACKET:
+		return p.parseListPattern()
+	}
+	p.addError(token, "pattern is expected")
+	return nil
+}
+
+func (p *Parser) parseListPattern() ast.Pattern {
+	listPattern := &ast.ListPattern{Token: p.curToken, Elements: make([]ast.Pattern, 0)}
+	for !p.isNextTokenExpected(lexer.RBRACKET) {
+		p.nextToken()
+		if p.isTokenExpected(lexer.ELLIPSIS) {
+			if !p.moveNextTokenExpected(lexer.IDENT) {
+				p.addError(p.peekToken, "identifier is expected after ...")
+				return nil
+			}
+			listPattern.Rest = p.parsePattern()
+			if !p.isNextTokenExpected(lexer.RBRACKET) {
+				p.addError(p.peekToken, "rest pattern must be the last element of a list pattern")
+				return nil
+			}
+			break
+		}
+		element := p.parsePattern()
+		if element == nil {
+			return nil
+		}
+		listPattern.Elements = append(listPattern.Elements, element)
+		if !p.moveNextTokenExpected(lexer.COMMA) && !p.isNextTokenExpected(lexer.RBRACKET) {
+			p.addError(p.peekToken, "comma is expected in list pattern")
+			return nil
+		}
+	}
+	p.nextToken()
+	return listPattern
+}
+
+func (p *Parser) parseDictExpression() ast.Expression {
+	dictExpression := &ast.DictLiteral{Token: p.curToken, Keys: make([]ast.Expression, 0), Values: make([]ast.Expression, 0)}
+	for !p.isNextTokenExpected(lexer.RBRACE) {
+		p.nextToken()
+		key := p.parseExpression(LOWEST)
+		if key == nil {
+			p.addError(p.curToken, "key is expected in dict expression")
+			return nil
+		}
+		if !p.moveNextTokenExpected(lexer.COLON) {
+			p.addError(p.peekToken, "colon is expected after dict key")
+			return nil
+		}
+		p.nextToken()
+		value := p.parseExpression(LOWEST)
+		if value == nil {
+			p.addError(p.curToken, "value is expected in dict expression")
+			return nil
+		}
+		dictExpression.Keys = append(dictExpression.Keys, key)
+		dictExpression.Values = append(dictExpression.Values, value)
+		if !p.moveNextTokenExpected(lexer.COMMA) && !p.isNextTokenExpected(lexer.RBRACE) {
+			p.addError(p.peekToken, "right brace is expected")
+			return nil
+		}
+	}
+	p.nextToken()
+	return dictExpression
+}
+
+func (p *Parser) parseListExpression() ast.Expression {
+	listExpression := &ast.ListLiteral{Token: p.curToken, Elements: make([]ast.Expression, 0)}
+	for !p.isNextTokenExpected(lexer.RBRACKET) {
+		p.nextToken()
+		element := p.parseExpression(LOWEST)
+		if element == nil {
+			p.addError(p.curToken, "element is expected in list expression")
+			return nil
+		}
+		listExpression.Elements = append(listExpression.Elements, element)
+		if !p.moveNextTokenExpected(lexer.COMMA) && !p.isNextTokenExpected(lexer.RBRACKET) {
+			p.addError(p.peekToken, "right bracket is expected")
+			return nil
+		}
+	}
+	p.nextToken()
+	return listExpression
+}
+
 func (p *Parser) parseBoolExpression() ast.Expression {
 	return &ast.BoolExpression{Token: p.curToken, Value: p.curToken.Literal}
 }
 
 func (p *Parser) parseGroupedExpression() ast.Expression {
+	inMatchGuard := p.inMatchGuard
+	p.inMatchGuard = false
+	defer func() { p.inMatchGuard = inMatchGuard }()
 	p.nextToken()
 	if p.isTokenExpected(lexer.IDENT) && p.isNextTokenExpected(lexer.COMMA) {
 		return p.parseParams()
 	} else if p.isTokenExpected(lexer.RPAREN) {
-		return &ast.ParamsExpression{Params: make([]*ast.Identifier, 0)}
+		return &ast.ParamsExpression{Token: p.curToken, Params: make([]*ast.Identifier, 0)}
 	} else {
 		groupExpression := p.parseExpression(LOWEST)
 		if !p.isNextTokenExpected(lexer.RPAREN) {
@@ -149,6 +388,7 @@ func (p *Parser) parseGroupedExpression() ast.Expression {
 }
 
 func (p *Parser) parseParams() *ast.ParamsExpression {
+	token := p.curToken
 	params := make([]*ast.Identifier, 0)
 	for p.isTokenExpected(lexer.IDENT) {
 		expression := p.parseIdentifierExpression()
@@ -158,7 +398,7 @@ func (p *Parser) parseParams() *ast.ParamsExpression {
 		p.nextToken()
 
 	}
-	return &ast.ParamsExpression{Params: params}
+	return &ast.ParamsExpression{Token: token, Params: params}
 }
 
 func (p *Parser) registerInfixFunction() {
@@ -172,7 +412,10 @@ func (p *Parser) registerInfixFunction() {
 	p.infixFunctions[lexer.LESSTHAN] = p.parseInfixExpression
 	p.infixFunctions[lexer.MORETHAN] = p.parseInfixExpression
 	p.infixFunctions[lexer.MODULE] = p.parseInfixExpression
+	p.infixFunctions[lexer.COMPOSE] = p.parseInfixExpression
+	p.infixFunctions[lexer.PIPE] = p.parsePipeExpression
 	p.infixFunctions[lexer.LPAREN] = p.parseCallFunctionExpression
+	p.infixFunctions[lexer.LBRACKET] = p.parseIndexExpression
 	p.infixFunctions[lexer.FUNCTION] = p.parseFunctionExpression
 
 }
@@ -189,65 +432,107 @@ func (p *Parser) parseFunctionExpression(params ast.Expression) ast.Expression {
 	case *ast.Identifier:
 		paramsExpression = []*ast.Identifier{paramsType}
 	default:
-		p.errors = append(p.errors, "params are expected")
+		p.addError(functionToken, "params are expected")
 		return nil
 	}
 	if !p.isTokenExpected(lexer.LBRACE) {
 		expression := p.parseExpression(LOWEST)
 		if expression == nil {
-			p.errors = append(p.errors, "expression was expected on lambda function")
+			p.addError(p.curToken, "expression was expected on lambda function")
 			return nil
 		}
-		token := lexer.Token{Type: lexer.RETURN, Literal: "return"}
-		blockStatementToken := lexer.Token{Type: lexer.LBRACE, Literal: "{"}
+		token := lexer.Token{Type: lexer.RETURN, Literal: "return", Position: expression.Position()}
+		blockStatementToken := lexer.Token{Type: lexer.LBRACE, Literal: "{", Position: expression.Position()}
 		returnStatement := &ast.ReturnStatement{Value: expression, Token: token}
 		statements := []ast.Statement{returnStatement}
 		blogStatement = &ast.BlockStatement{Token: blockStatementToken, Statements: statements}
 	} else {
 		blogStatement, ok = p.parserBlockStatement().(*ast.BlockStatement)
 		if !ok {
-			p.errors = append(p.errors, "function definition block is expected")
+			p.addError(p.curToken, "function definition block is expected")
 			return nil
 		}
 	}
 
-	return &ast.FunctionExpression{functionToken, paramsExpression, blogStatement}
+	return &ast.FunctionExpression{Token: functionToken, Params: paramsExpression, Block: blogStatement}
 
 }
 
 func (p *Parser) parseCallFunctionExpression(function ast.Expression) ast.Expression {
 	callFunctionExpression := &ast.CallFunctionExpression{Token: p.curToken, Function: function}
+	inMatchGuard := p.inMatchGuard
+	p.inMatchGuard = false
+	defer func() { p.inMatchGuard = inMatchGuard }()
 	p.nextToken()
 	arguments := p.parseArguments()
 	if arguments == nil {
-		p.errors = append(p.errors, "error parsing function arguments")
+		p.addError(p.curToken, "error parsing function arguments")
 		return nil
 	}
 	callFunctionExpression.Arguments = arguments
 	return callFunctionExpression
 }
+// parseArguments starts at the token after ( and ends at the ) closing the call, the current token after the last
+// argument is the end of that argument, which can be the ) of an inner call
 func (p *Parser) parseArguments() []ast.Expression {
 	arguments := make([]ast.Expression, 0)
-	for !p.isTokenExpected(lexer.RPAREN) {
+	if p.isTokenExpected(lexer.RPAREN) {
+		return arguments
+	}
+	for {
 		expression := p.parseExpression(LOWEST)
 		if expression == nil {
 			return nil
 		}
 		arguments = append(arguments, expression)
-		if (p.moveNextTokenExpected(lexer.COMMA)) {
-			p.nextToken()
-		} else {
+		if !p.moveNextTokenExpected(lexer.COMMA) {
 			break
 		}
-
+		if p.nextToken(); p.isTokenExpected(lexer.RPAREN) {
+			return arguments
+		}
 	}
-	if !p.isNextTokenExpected(lexer.SEMICOLON) {
-		p.nextToken()
+	if !p.moveNextTokenExpected(lexer.RPAREN) {
+		return nil
 	}
-
 	return arguments
 }
 
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	token := p.curToken
+	p.nextToken()
+	var index ast.Expression
+	if !p.isTokenExpected(lexer.COLON) {
+		index = p.parseExpression(LOWEST)
+		if index == nil {
+			p.addError(p.curToken, "index is expected")
+			return nil
+		}
+		if p.moveNextTokenExpected(lexer.RBRACKET) {
+			return &ast.IndexExpression{Token: token, Left: left, Index: index}
+		}
+		if !p.moveNextTokenExpected(lexer.COLON) {
+			p.addError(p.peekToken, "right bracket is expected")
+			return nil
+		}
+	}
+	sliceExpression := &ast.SliceExpression{Token: token, Left: left, Start: index}
+	if p.moveNextTokenExpected(lexer.RBRACKET) {
+		return sliceExpression
+	}
+	p.nextToken()
+	sliceExpression.End = p.parseExpression(LOWEST)
+	if sliceExpression.End == nil {
+		p.addError(p.curToken, "end of slice is expected")
+		return nil
+	}
+	if !p.moveNextTokenExpected(lexer.RBRACKET) {
+		p.addError(p.peekToken, "right bracket is expected")
+		return nil
+	}
+	return sliceExpression
+}
+
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
 	p.peekToken = p.l.NextToken()
@@ -277,6 +562,8 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parserBlockStatement()
 	} else if p.curToken.Type == lexer.IMPORT {
 		return p.parseImportStatement()
+	} else if p.curToken.Type == lexer.EXPORT {
+		return p.parseExportStatement()
 	} else {
 		return p.parseExpressionStatement()
 	}
@@ -285,9 +572,12 @@ func (p *Parser) parseStatement() ast.Statement {
 
 func (p *Parser) parseImportStatement() ast.Statement {
 	token := p.curToken
+	if p.isNextTokenExpected(lexer.LBRACE) {
+		return p.parseSelectiveImport(token)
+	}
 	p.nextToken()
 	if !p.isTokenExpected(lexer.STRING) {
-		p.errors = append(p.errors, "string path is expected")
+		p.addError(p.curToken, "string path is expected")
 		return nil
 	}
 	var identity *ast.Identifier
@@ -295,11 +585,11 @@ func (p *Parser) parseImportStatement() ast.Statement {
 	if !p.isNextTokenExpected(lexer.AS) {
 		names := strings.Split(path, "/")
 		name := names[len(names)-1]
-		identity = &ast.Identifier{Token: lexer.Token{Literal: name, Type: lexer.IDENT}, Value: name}
+		identity = &ast.Identifier{Token: lexer.Token{Literal: name, Type: lexer.IDENT, Position: p.curToken.Position}, Value: name}
 	} else {
 		p.nextToken()
 		if !p.isNextTokenExpected(lexer.IDENT) {
-			p.errors = append(p.errors, "identity is expected")
+			p.addError(p.peekToken, "identity is expected")
 			return nil
 		}
 		p.nextToken()
@@ -309,6 +599,77 @@ func (p *Parser) parseImportStatement() ast.Statement {
 	return &ast.ImportStatement{Token: token, Path: path, Name: identity}
 }
 
+// parseSelectiveImport parses import { a, b as c } from "path", the module is named by the last part of its path
+func (p *Parser) parseSelectiveImport(token lexer.Token) ast.Statement {
+	p.nextToken()
+	symbols := make([]*ast.ImportSymbol, 0)
+	for !p.moveNextTokenExpected(lexer.RBRACE) {
+		if len(symbols) != 0 && !p.moveNextTokenExpected(lexer.COMMA) {
+			p.addError(p.peekToken, "comma is expected between the imported names")
+			return nil
+		}
+		if !p.moveNextTokenExpected(lexer.IDENT) {
+			p.addError(p.peekToken, "identity is expected")
+			return nil
+		}
+		name := p.parseIdentifierExpression().(*ast.Identifier)
+		alias := &ast.Identifier{Token: name.Token, Value: name.Value}
+		if p.moveNextTokenExpected(lexer.AS) {
+			if !p.moveNextTokenExpected(lexer.IDENT) {
+				p.addError(p.peekToken, "identity is expected")
+				return nil
+			}
+			alias = p.parseIdentifierExpression().(*ast.Identifier)
+		}
+		symbols = append(symbols, &ast.ImportSymbol{Name: name, Alias: alias})
+	}
+	if !p.moveNextTokenExpected(lexer.FROM) {
+		p.addError(p.peekToken, "from is expected after the imported names")
+		return nil
+	}
+	if !p.moveNextTokenExpected(lexer.STRING) {
+		p.addError(p.peekToken, "string path is expected")
+		return nil
+	}
+	path := p.curToken.Literal
+	names := strings.Split(path, "/")
+	name := names[len(names)-1]
+	identity := &ast.Identifier{Token: lexer.Token{Literal: name, Type: lexer.IDENT, Position: p.curToken.Position}, Value: name}
+	return &ast.ImportStatement{Token: token, Path: path, Name: identity, Symbols: symbols}
+}
+
+// parseExportStatement parses export let name = value; and the list of names export (a, b);
+func (p *Parser) parseExportStatement() ast.Statement {
+	token := p.curToken
+	if p.moveNextTokenExpected(lexer.LET) {
+		statement := p.parseLetStatement()
+		if let, ok := statement.(*ast.LetStatement); ok {
+			let.Exported = true
+		}
+		return statement
+	}
+	if !p.moveNextTokenExpected(lexer.LPAREN) {
+		p.addError(p.peekToken, "let or a list of names is expected after export")
+		return nil
+	}
+	names := make([]*ast.Identifier, 0)
+	for !p.moveNextTokenExpected(lexer.RPAREN) {
+		if len(names) != 0 && !p.moveNextTokenExpected(lexer.COMMA) {
+			p.addError(p.peekToken, "comma is expected between the exported names")
+			return nil
+		}
+		if !p.moveNextTokenExpected(lexer.IDENT) {
+			p.addError(p.peekToken, "identity is expected")
+			return nil
+		}
+		names = append(names, p.parseIdentifierExpression().(*ast.Identifier))
+	}
+	if p.isNextTokenExpected(lexer.SEMICOLON) {
+		p.nextToken()
+	}
+	return &ast.ExportStatement{Token: token, Names: names}
+}
+
 func (p *Parser) parserBlockStatement() ast.Statement {
 	statements := make([]ast.Statement, 0)
 	blockStatement := &ast.BlockStatement{Token: p.curToken}
@@ -322,7 +683,7 @@ func (p *Parser) parserBlockStatement() ast.Statement {
 	}
 	blockStatement.Statements = statements
 	if !p.isNextTokenExpected(lexer.RBRACE) {
-		p.errors = append(p.errors, "right brace is expected")
+		p.addError(p.peekToken, "right brace is expected")
 		return nil
 	}
 	p.nextToken()
@@ -345,12 +706,12 @@ func (p *Parser) parseExpressionStatement() ast.Statement {
 func (p *Parser) parseLetStatement() ast.Statement {
 	token := p.curToken
 	if p.peekToken.Type != lexer.IDENT {
-		p.errors = append(p.errors, "ident is expected after let")
+		p.addError(p.peekToken, "ident is expected after let")
 		return nil
 	}
 	p.nextToken()
 	if p.peekToken.Type != lexer.ASSIGN {
-		p.errors = append(p.errors, "after declaration equal sign is expected")
+		p.addError(p.peekToken, "after declaration equal sign is expected")
 		return nil
 	}
 	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
@@ -371,7 +732,7 @@ func (p *Parser) parseReturnStatement() ast.Statement {
 	p.nextToken()
 	expression := p.parseExpression(LOWEST)
 	if !p.moveNextTokenExpected(lexer.SEMICOLON) {
-		p.errors = append(p.errors, "semicolon is expected")
+		p.addError(p.peekToken, "semicolon is expected")
 		return nil
 	}
 	var returnStatement *ast.ReturnStatement = &ast.ReturnStatement{Token: token, Value: expression}
@@ -383,9 +744,25 @@ func (p *Parser) parseInfixExpression(leftExpression ast.Expression) ast.Express
 	precedence := p.getCurrentPrecedenceToken()
 	p.nextToken()
 	infixExpression.RightExpression = p.parseExpression(precedence)
+	if infixExpression.RightExpression == nil {
+		p.addError(infixExpression.Token, fmt.Sprintf("expression is expected after %s", infixExpression.Operator))
+		return nil
+	}
 	return infixExpression
 }
 
+func (p *Parser) parsePipeExpression(leftExpression ast.Expression) ast.Expression {
+	pipeExpression := &ast.PipeExpression{Token: p.curToken, Left: leftExpression}
+	precedence := p.getCurrentPrecedenceToken()
+	p.nextToken()
+	pipeExpression.Right = p.parseExpression(precedence)
+	if pipeExpression.Right == nil {
+		p.addError(p.curToken, "function is expected after pipe")
+		return nil
+	}
+	return pipeExpression
+}
+
 func (p *Parser) parsePrefixExpression() ast.Expression {
 	prefixExpresion := &ast.PrefixExpression{Token: p.curToken, Operator: p.curToken.Literal}
 	p.nextToken()
@@ -401,6 +778,9 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 	}
 	leftExpression := prefixFunction()
 	for !p.isNextTokenExpected(lexer.SEMICOLON) && precedence < p.getPeekPrecedenceToken() {
+		if p.inMatchGuard && p.isNextTokenExpected(lexer.FUNCTION) {
+			return leftExpression
+		}
 		infixFunction, ok := p.infixFunctions[p.peekToken.Type]
 		if !ok {
 			return leftExpression
@@ -428,18 +808,46 @@ func (p *Parser) getPeekPrecedenceToken() int {
 }
 
 func (p *Parser) registerPrefixParserError() {
-	p.prefixErrors = append(p.prefixErrors, fmt.Sprintf("function for %s tokne not found", p.curToken.Type))
+	p.prefixErrors = append(p.prefixErrors, fmt.Sprintf("%s: function for %s tokne not found", p.curToken.Position, p.curToken.Type))
+}
+
+func (p *Parser) addError(token lexer.Token, message string) {
+	p.errors = append(p.errors, fmt.Sprintf("%s: %s", token.Position, message))
 }
 
 func (p *Parser) parseIntExpression() ast.Expression {
-	val, err := strconv.ParseInt(p.curToken.Literal, 10, 0)
+	literal := strings.Replace(p.curToken.Literal, "_", "", -1)
+	base := 10
+	if len(literal) > 2 && literal[0] == '0' {
+		switch literal[1] {
+		case 'x', 'X':
+			base = 16
+			literal = literal[2:]
+		case 'b', 'B':
+			base = 2
+			literal = literal[2:]
+		}
+	}
+	val, err := strconv.ParseInt(literal, base, 64)
 	if err != nil {
-		p.errors = append(p.errors, "integer is expected")
+		if bigValue, ok := new(big.Int).SetString(literal, base); ok {
+			return &ast.IntegerLiteral{Token: p.curToken, Value: math.MaxInt64, Big: bigValue}
+		}
+		p.addError(p.curToken, "integer is expected")
 		return nil
 	}
 	return &ast.IntegerLiteral{Token: p.curToken, Value: val}
 }
 
+func (p *Parser) parseFloatExpression() ast.Expression {
+	val, err := strconv.ParseFloat(strings.Replace(p.curToken.Literal, "_", "", -1), 64)
+	if err != nil {
+		p.addError(p.curToken, "float is expected")
+		return nil
+	}
+	return &ast.FloatLiteral{Token: p.curToken, Value: val}
+}
+
 func (p *Parser) parseIdentifierExpression() ast.Expression {
 	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 }
@@ -83,6 +83,93 @@ func TestIntegerExpression(t *testing.T) {
 
 }
 
+func TestNumberLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"0xFF", "255"},
+		{"0b1010", "10"},
+		{"1_000_000", "1000000"},
+		{"010", "10"},
+		{"3.14", "3.14"},
+		{"1e-9", "1e-09"},
+		{"2.5e3", "2500"},
+		{"18_446_744_073_709_551_616", "18446744073709551616"},
+		{"0xFFFFFFFFFFFFFFFFFF", "4722366482869645213695"},
+	}
+	for _, test := range tests {
+		l := lexer.New(test.input)
+		p := New(l)
+		program := p.ParseProgram()
+		if len(program.Statements) != 1 {
+			showParserErrors(p, t)
+			t.Errorf("should statements 1 %s", test.input)
+			return
+		}
+		if program.Statements[0].String() != test.expected+";" {
+			t.Errorf("number literal expected %s and got %s", test.expected, program.Statements[0].String())
+			return
+		}
+	}
+}
+
+func TestDictExpression(t *testing.T) {
+	input := `
+		   let x = {"a": 1, "b": 2 + 3};
+		   let y = {};
+		   let z = x["a"];
+		   return f()[1 + 2];
+   	`
+	expectedStatements := []string{`let x = {a:1,b:(2 + 3)};`, "let y = {};", "let z = (x[a]);", "return (f()[(1 + 2)]);"}
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	if len(program.Statements) != len(expectedStatements) {
+		showParserErrors(p, t)
+		showPrefixParserError(p, t)
+		t.Errorf("number of statement expected is %d and got %d", len(expectedStatements), len(program.Statements))
+		return
+	}
+	for index, statement := range program.Statements {
+		if expectedStatements[index] != statement.String() {
+			showParserErrors(p, t)
+			showPrefixParserError(p, t)
+			t.Errorf("statement expected is %s and got %s", expectedStatements[index], statement.String())
+		}
+	}
+}
+
+func TestListExpression(t *testing.T) {
+	input := `
+		   let x = [1, 2 + 3, "a"];
+		   let y = [];
+		   let z = x[-1];
+		   let w = x[1:2];
+		   let v = x[:2];
+		   let u = x[1:];
+		   return [[1], [2]][0][0];
+   	`
+	expectedStatements := []string{"let x = [1,(2 + 3),a];", "let y = [];", "let z = (x[-(1)]);", "let w = (x[1:2]);",
+		"let v = (x[:2]);", "let u = (x[1:]);", "return (([[1],[2]][0])[0]);"}
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	if len(program.Statements) != len(expectedStatements) {
+		showParserErrors(p, t)
+		showPrefixParserError(p, t)
+		t.Errorf("number of statement expected is %d and got %d", len(expectedStatements), len(program.Statements))
+		return
+	}
+	for index, statement := range program.Statements {
+		if expectedStatements[index] != statement.String() {
+			showParserErrors(p, t)
+			showPrefixParserError(p, t)
+			t.Errorf("statement expected is %s and got %s", expectedStatements[index], statement.String())
+		}
+	}
+}
+
 func TestBooleanExpression(t *testing.T) {
 	input := `false`
 	l := lexer.New(input)
@@ -379,6 +466,34 @@ func TestCallFunctionExpression(t *testing.T) {
 	}
 }
 
+func TestNestedCallExpression(t *testing.T) {
+	input := `
+		   let x = len(list(1, 2)) * 10;
+		   f(f(1)) + 1;
+		   f(g(1), (2))[0];
+		   filter(x => x > 0, range(0, 3, 1)) |> collect;
+		   if (len(list(1)) > 5) { 1 } else { 2 };
+		   f(g(), 1,);
+   	`
+	expectedStatements := []string{"let x = (len(list(1,2)) * 10);", "(f(f(1)) + 1);", "(f(g(1),2)[0]);",
+		"(filter((x)=>{return (x > 0);},range(0,3,1)) |> collect);", "if((len(list(1)) > 5)){1;}else{2;};", "f(g(),1);"}
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	if len(program.Statements) != len(expectedStatements) {
+		showParserErrors(p, t)
+		showPrefixParserError(p, t)
+		t.Errorf("number of statement expected is %d and got %d", len(expectedStatements), len(program.Statements))
+		return
+	}
+	for index, statement := range program.Statements {
+		if expectedStatements[index] != statement.String() {
+			showParserErrors(p, t)
+			t.Errorf("statement expected is %s and got %s", expectedStatements[index], statement.String())
+		}
+	}
+}
+
 func TestFunctionExpressionWithoutParams(t *testing.T) {
 	input := ` () =>
 	{
@@ -523,6 +638,134 @@ func TestPrefixExpression(t *testing.T) {
 
 }
 
+func TestComposeExpression(t *testing.T) {
+	input := `
+		   let h = f . g;
+		   let h = f . g . k;
+		   let h = net::f . bytes::g;
+		   let h = f . g(x) + 1;
+		   return (f . g)(x);
+   	`
+	expectedStatements := []string{"let h = (f . g);", "let h = ((f . g) . k);", "let h = ((net :: f) . (bytes :: g));",
+		"let h = (f . (g(x) + 1));", "return (f . g)(x);"}
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	if len(program.Statements) != len(expectedStatements) {
+		showParserErrors(p, t)
+		showPrefixParserError(p, t)
+		t.Errorf("number of statement expected is %d and got %d", len(expectedStatements), len(program.Statements))
+		return
+	}
+	for index, statement := range program.Statements {
+		if expectedStatements[index] != statement.String() {
+			showParserErrors(p, t)
+			t.Errorf("statement expected is %s and got %s", expectedStatements[index], statement.String())
+		}
+	}
+}
+
+func TestPipeExpression(t *testing.T) {
+	input := `
+		   let r = xs |> map(h) |> filter(g) |> reduce(f);
+		   let r = data |> bytes::read_string;
+		   let r = a + b |> f . g;
+   	`
+	expectedStatements := []string{"let r = (((xs |> map(h)) |> filter(g)) |> reduce(f));",
+		"let r = (data |> (bytes :: read_string));", "let r = ((a + b) |> (f . g));"}
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	if len(program.Statements) != len(expectedStatements) {
+		showParserErrors(p, t)
+		showPrefixParserError(p, t)
+		t.Errorf("number of statement expected is %d and got %d", len(expectedStatements), len(program.Statements))
+		return
+	}
+	for index, statement := range program.Statements {
+		if expectedStatements[index] != statement.String() {
+			showParserErrors(p, t)
+			t.Errorf("statement expected is %s and got %s", expectedStatements[index], statement.String())
+		}
+	}
+}
+
+func TestTryExpression(t *testing.T) {
+	input := `
+		   let x = try { 10 / y; } catch (e) { 0; };
+		   try { f(); } catch { 1; }
+   	`
+	expectedStatements := []string{"let x = try{(10 / y);}catch(e){0;};", "try{f();}catch{1;};"}
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	if len(program.Statements) != len(expectedStatements) {
+		showParserErrors(p, t)
+		showPrefixParserError(p, t)
+		t.Errorf("number of statement expected is %d and got %d", len(expectedStatements), len(program.Statements))
+		return
+	}
+	for index, statement := range program.Statements {
+		if expectedStatements[index] != statement.String() {
+			showParserErrors(p, t)
+			t.Errorf("statement expected is %s and got %s", expectedStatements[index], statement.String())
+		}
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	input := `
+		   let r = match x { 0 => "zero", -1 => "minus", "a" => true, true => 1, _ => 2 };
+		   let r = match xs { [] => 0, [x, ...rest] if x > 1 => x, [_, y] => { y; } };
+		   let r = match x { n if any(x => x > n, xs) => n, n if (ok) => 0 };
+   	`
+	expectedStatements := []string{`let r = match(x){0 => zero, -(1) => minus, "a" => true, true => 1, _ => 2};`,
+		"let r = match(xs){[] => 0, [x, ...rest] if (x > 1) => x, [_, y] => {y;}};",
+		"let r = match(x){n if any((x)=>{return (x > n);},xs) => n, n if ok => 0};"}
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	if len(program.Statements) != len(expectedStatements) {
+		showParserErrors(p, t)
+		showPrefixParserError(p, t)
+		t.Errorf("number of statement expected is %d and got %d", len(expectedStatements), len(program.Statements))
+		return
+	}
+	for index, statement := range program.Statements {
+		if expectedStatements[index] != statement.String() {
+			showParserErrors(p, t)
+			t.Errorf("statement expected is %s and got %s", expectedStatements[index], statement.String())
+		}
+	}
+}
+
+func TestParserErrorPosition(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 5;\nlet = 4;", "main.rl:2:5: ident is expected after let"},
+		{"let f = () => {\n  return 5\n};", "main.rl:3:1: semicolon is expected"},
+		{"if (x > 1 {\n}", "main.rl:1:11: right parent is expected in if expression"},
+		{"let f = x => x;\nf .", "main.rl:2:3: expression is expected after ."},
+		{"1.", "main.rl:1:2: expression is expected after ."},
+		{"let x = 1 +", "main.rl:1:11: expression is expected after +"},
+	}
+	for _, test := range tests {
+		l := lexer.NewWithFile(test.input, "main.rl")
+		p := New(l)
+		p.ParseProgram()
+		if len(p.GetErrors()) == 0 {
+			t.Errorf("parser error expected %s", test.input)
+			return
+		}
+		if p.GetErrors()[0] != test.expected {
+			t.Errorf("parser error expected %s and got %s", test.expected, p.GetErrors()[0])
+			return
+		}
+	}
+}
+
 func showParserErrors(p *Parser, t *testing.T) {
 	for _, errorText := range p.errors {
 		t.Logf("%s\n", errorText)
@@ -664,6 +907,60 @@ func TestImportStatement(t *testing.T) {
 	}
 }
 
+func TestSelectiveImportStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		name     string
+	}{
+		{`import { get_clients, write_to_client as send } from "/net";`, `import { get_clients, write_to_client as send } from "/net"`, "net"},
+		{`import { value } from "./lib/util"`, `import { value } from "./lib/util"`, "util"},
+		{`import {} from "util"`, `import {  } from "util"`, "util"},
+	}
+	for _, test := range tests {
+		p := New(lexer.New(test.input))
+		program := p.ParseProgram()
+		showParserErrors(p, t)
+		importStatement, ok := program.Statements[0].(*ast.ImportStatement)
+		if !ok || importStatement.String() != test.expected || importStatement.Name.Value != test.name {
+			t.Errorf("expected %s and got %s", test.expected, program.String())
+		}
+	}
+	for _, input := range []string{`import { a } "net"`, `import { a b } from "net"`, `import { a as } from "net"`, `import { a } from net`} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.GetErrors()) == 0 {
+			t.Errorf("%s should be a parser error", input)
+		}
+	}
+}
+
+func TestExportStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`export let f = x => x;`, "export let f = (x)=>{return x;};"},
+		{`export (f, g);`, "export (f, g);"},
+		{`export ();`, "export ();"},
+	}
+	for _, test := range tests {
+		p := New(lexer.New(test.input))
+		program := p.ParseProgram()
+		showParserErrors(p, t)
+		if len(program.Statements) != 1 || program.Statements[0].String() != test.expected {
+			t.Errorf("expected %s and got %s", test.expected, program.String())
+		}
+	}
+	for _, input := range []string{`export f;`, `export (f g);`, `export (1);`} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.GetErrors()) == 0 {
+			t.Errorf("%s should be a parser error", input)
+		}
+	}
+}
+
 func TestReturnStatementLiteralValue(t *testing.T) {
 	input := `
 		   return 5;
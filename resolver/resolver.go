@@ -0,0 +1,336 @@
+// Package resolver binds the identifiers of a program to the slot of the frame that declares them before it is evaluated.
+// Function calls, match arms and catch blocks get a frame, the names declared by the top level of the program stay in its environment.
+package resolver
+
+import (
+	"rootlang/ast"
+	"rootlang/object"
+	"rootlang/builtin"
+	"fmt"
+)
+
+type frame struct {
+	slots map[string]int
+	names []string
+	outer *frame
+}
+
+func newFrame(outer *frame) *frame {
+	return &frame{slots: make(map[string]int), names: make([]string, 0), outer: outer}
+}
+
+func (f *frame) declare(name string) {
+	if _, ok := f.slots[name]; ok {
+		return
+	}
+	f.slots[name] = len(f.names)
+	f.names = append(f.names, name)
+}
+
+type Resolver struct {
+	frame       *frame
+	globals     map[string]bool
+	environment *object.Environment
+	builtins    *builtin.Builtin
+	errors      []string
+	lateGlobals bool
+}
+
+// Resolve annotates the program and returns the names used without being declared, environment is the one the program
+// will run in so the names set by previous programs, like the lines of the repl, are known
+func Resolve(program *ast.Program, environment *object.Environment, builtinSymbols *builtin.Builtin) []string {
+	return resolve(&Resolver{environment: environment, builtins: builtinSymbols}, program)
+}
+
+// ResolveLine resolves a line of the repl, the globals not declared yet are looked up when the line runs because a later
+// line can declare them, like a function calling another one entered after it
+func ResolveLine(program *ast.Program, environment *object.Environment, builtinSymbols *builtin.Builtin) []string {
+	return resolve(&Resolver{environment: environment, builtins: builtinSymbols, lateGlobals: true}, program)
+}
+
+func resolve(r *Resolver, program *ast.Program) []string {
+	r.globals, r.errors = make(map[string]bool), make([]string, 0)
+	for _, statement := range program.Statements {
+		declareNames(statement, func(name string) { r.globals[name] = true })
+	}
+	for _, statement := range program.Statements {
+		r.resolveTopLevel(statement)
+	}
+	return r.errors
+}
+
+// resolveTopLevel resolves a statement of the top level of the program, the only place where export can be used
+func (r *Resolver) resolveTopLevel(statement ast.Statement) {
+	switch statementType := statement.(type) {
+	case *ast.ExportStatement:
+		for _, name := range statementType.Names {
+			r.resolveIdentifier(name)
+		}
+	case *ast.LetStatement:
+		if statementType.Exported {
+			r.resolve(statementType.Value)
+			r.bind(statementType.Name)
+			return
+		}
+		r.resolve(statement)
+	default:
+		r.resolve(statement)
+	}
+}
+
+// declareNames finds the names a node sets in the frame it runs in, functions, match arms and catch blocks have their own frame
+func declareNames(node ast.Node, declare func(name string)) {
+	switch nodeType := node.(type) {
+	case *ast.BlockStatement:
+		for _, statement := range nodeType.Statements {
+			declareNames(statement, declare)
+		}
+	case *ast.ExpressionStatement:
+		declareNames(nodeType.Exp, declare)
+	case *ast.LetStatement:
+		declare(nodeType.Name.Value)
+		declareNames(nodeType.Value, declare)
+	case *ast.ImportStatement:
+		for _, name := range importedNames(nodeType) {
+			declare(name.Value)
+		}
+	case *ast.ReturnStatement:
+		declareNames(nodeType.Value, declare)
+	case *ast.IfExpression:
+		declareNames(nodeType.Condition, declare)
+		declareNames(nodeType.ConditionalBlock, declare)
+		if nodeType.AlternativeBlock != nil {
+			declareNames(nodeType.AlternativeBlock, declare)
+		}
+	case *ast.TryExpression:
+		declareNames(nodeType.Block, declare)
+	case *ast.MatchExpression:
+		declareNames(nodeType.Value, declare)
+	case *ast.PrefixExpression:
+		declareNames(nodeType.RightExpression, declare)
+	case *ast.InfixExpression:
+		declareNames(nodeType.LeftExpression, declare)
+		declareNames(nodeType.RightExpression, declare)
+	case *ast.PipeExpression:
+		declareNames(nodeType.Left, declare)
+		declareNames(nodeType.Right, declare)
+	case *ast.CallFunctionExpression:
+		declareNames(nodeType.Function, declare)
+		for _, argument := range nodeType.Arguments {
+			declareNames(argument, declare)
+		}
+	case *ast.ListLiteral:
+		for _, element := range nodeType.Elements {
+			declareNames(element, declare)
+		}
+	case *ast.DictLiteral:
+		for i := range nodeType.Keys {
+			declareNames(nodeType.Keys[i], declare)
+			declareNames(nodeType.Values[i], declare)
+		}
+	case *ast.IndexExpression:
+		declareNames(nodeType.Left, declare)
+		declareNames(nodeType.Index, declare)
+	case *ast.SliceExpression:
+		declareNames(nodeType.Left, declare)
+		declareNames(nodeType.Start, declare)
+		declareNames(nodeType.End, declare)
+	}
+}
+
+// importedNames returns the names an import binds, the aliases of the symbols it chose or the name of the module
+func importedNames(importStatement *ast.ImportStatement) []*ast.Identifier {
+	if importStatement.Symbols == nil {
+		return []*ast.Identifier{importStatement.Name}
+	}
+	names := make([]*ast.Identifier, 0, len(importStatement.Symbols))
+	for _, symbol := range importStatement.Symbols {
+		names = append(names, symbol.Alias)
+	}
+	return names
+}
+
+func (r *Resolver) resolve(node ast.Node) {
+	switch nodeType := node.(type) {
+	case *ast.BlockStatement:
+		for _, statement := range nodeType.Statements {
+			r.resolve(statement)
+		}
+	case *ast.ExpressionStatement:
+		r.resolve(nodeType.Exp)
+	case *ast.LetStatement:
+		if nodeType.Exported {
+			r.exportError(nodeType)
+		}
+		r.resolve(nodeType.Value)
+		r.bind(nodeType.Name)
+	case *ast.ExportStatement:
+		r.exportError(nodeType)
+	case *ast.ImportStatement:
+		for _, name := range importedNames(nodeType) {
+			r.bind(name)
+		}
+	case *ast.ReturnStatement:
+		r.resolve(nodeType.Value)
+	case *ast.Identifier:
+		r.resolveIdentifier(nodeType)
+	case *ast.FunctionExpression:
+		r.resolveFunction(nodeType)
+	case *ast.IfExpression:
+		r.resolve(nodeType.Condition)
+		r.resolve(nodeType.ConditionalBlock)
+		if nodeType.AlternativeBlock != nil {
+			r.resolve(nodeType.AlternativeBlock)
+		}
+	case *ast.TryExpression:
+		r.resolve(nodeType.Block)
+		r.resolveCatch(nodeType)
+	case *ast.MatchExpression:
+		r.resolve(nodeType.Value)
+		for _, arm := range nodeType.Arms {
+			r.resolveArm(arm)
+		}
+	case *ast.PrefixExpression:
+		r.resolve(nodeType.RightExpression)
+	case *ast.InfixExpression:
+		r.resolve(nodeType.LeftExpression)
+		if nodeType.Operator == "::" {
+			r.resolveModuleAccess(nodeType.RightExpression)
+			return
+		}
+		r.resolve(nodeType.RightExpression)
+	case *ast.PipeExpression:
+		r.resolve(nodeType.Left)
+		r.resolve(nodeType.Right)
+	case *ast.CallFunctionExpression:
+		r.resolve(nodeType.Function)
+		for _, argument := range nodeType.Arguments {
+			r.resolve(argument)
+		}
+	case *ast.ListLiteral:
+		for _, element := range nodeType.Elements {
+			r.resolve(element)
+		}
+	case *ast.DictLiteral:
+		for i := range nodeType.Keys {
+			r.resolve(nodeType.Keys[i])
+			r.resolve(nodeType.Values[i])
+		}
+	case *ast.IndexExpression:
+		r.resolve(nodeType.Left)
+		r.resolve(nodeType.Index)
+	case *ast.SliceExpression:
+		r.resolve(nodeType.Left)
+		if nodeType.Start != nil {
+			r.resolve(nodeType.Start)
+		}
+		if nodeType.End != nil {
+			r.resolve(nodeType.End)
+		}
+	}
+}
+
+// resolveModuleAccess leaves the names read from a module unresolved, they are looked up in the environment of the module
+func (r *Resolver) resolveModuleAccess(expression ast.Expression) {
+	if call, ok := expression.(*ast.CallFunctionExpression); ok {
+		for _, argument := range call.Arguments {
+			r.resolve(argument)
+		}
+	}
+}
+
+// bind annotates a name set by a let, an import or a catch in the current frame
+func (r *Resolver) bind(identifier *ast.Identifier) {
+	if r.frame == nil {
+		identifier.Scope = ast.GlobalScope
+		return
+	}
+	identifier.Scope, identifier.Depth, identifier.Slot = ast.LocalScope, 0, r.frame.slots[identifier.Value]
+}
+
+func (r *Resolver) resolveIdentifier(identifier *ast.Identifier) {
+	depth := 0
+	for f := r.frame; f != nil; f = f.outer {
+		if slot, ok := f.slots[identifier.Value]; ok {
+			identifier.Scope, identifier.Depth, identifier.Slot = ast.LocalScope, depth, slot
+			return
+		}
+		depth++
+	}
+	identifier.Scope = ast.GlobalScope
+	if r.globals[identifier.Value] {
+		return
+	}
+	if _, ok := r.environment.GetVar(identifier.Value); ok {
+		return
+	}
+	if _, ok := r.builtins.GetObject(identifier.Value); ok || r.lateGlobals {
+		return
+	}
+	r.errors = append(r.errors, fmt.Sprintf("%s: %s was not declare", identifier.Position(), identifier.Value))
+}
+
+func (r *Resolver) exportError(statement ast.Statement) {
+	r.errors = append(r.errors, fmt.Sprintf("%s: export is only allowed at the top level of a module", statement.Position()))
+}
+
+// resolveFunction gives the params the first slots of the frame in order, so a call can fill them by position
+func (r *Resolver) resolveFunction(function *ast.FunctionExpression) {
+	f := newFrame(r.frame)
+	for i, param := range function.Params {
+		f.slots[param.Value] = i
+		f.names = append(f.names, param.Value)
+		param.Scope, param.Depth, param.Slot = ast.LocalScope, 0, i
+	}
+	declareNames(function.Block, f.declare)
+	r.frame = f
+	r.resolve(function.Block)
+	r.frame = f.outer
+	function.Locals = f.names
+}
+
+func (r *Resolver) resolveArm(arm *ast.MatchArm) {
+	f := newFrame(r.frame)
+	declarePattern(arm.Pattern, f.declare)
+	if arm.Guard != nil {
+		declareNames(arm.Guard, f.declare)
+	}
+	declareNames(arm.Body, f.declare)
+	r.frame = f
+	if arm.Guard != nil {
+		r.resolve(arm.Guard)
+	}
+	r.resolve(arm.Body)
+	r.frame = f.outer
+	arm.Locals = f.names
+}
+
+// resolveCatch gives the catch block a frame like a match arm, the error name and its lets do not leak to the try scope
+func (r *Resolver) resolveCatch(try *ast.TryExpression) {
+	f := newFrame(r.frame)
+	if try.ErrorName != nil {
+		f.declare(try.ErrorName.Value)
+	}
+	declareNames(try.CatchBlock, f.declare)
+	r.frame = f
+	if try.ErrorName != nil {
+		r.bind(try.ErrorName)
+	}
+	r.resolve(try.CatchBlock)
+	r.frame = f.outer
+	try.Locals = f.names
+}
+
+func declarePattern(pattern ast.Pattern, declare func(name string)) {
+	switch patternType := pattern.(type) {
+	case *ast.IdentifierPattern:
+		declare(patternType.Name.Value)
+	case *ast.ListPattern:
+		for _, element := range patternType.Elements {
+			declarePattern(element, declare)
+		}
+		if patternType.Rest != nil {
+			declarePattern(patternType.Rest, declare)
+		}
+	}
+}
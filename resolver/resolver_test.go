@@ -0,0 +1,82 @@
+package resolver
+
+import (
+	"testing"
+	"rootlang/ast"
+	"rootlang/lexer"
+	"rootlang/parser"
+	"rootlang/object"
+	"rootlang/builtin"
+)
+
+func parse(input string) *ast.Program {
+	l := lexer.NewWithFile(input, "main.rl")
+	return parser.New(l).ParseProgram()
+}
+
+func TestUndeclaredNames(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`let x = 5; let y = x + len("a");`, []string{}},
+		{"let x = 5;\nlet y = x + z;", []string{"main.rl:2:13: z was not declare"}},
+		{`let f = n => g(n); let g = n => n;`, []string{}},
+		{`let f = () => { let a = 1; }; a`, []string{"main.rl:1:31: a was not declare"}},
+		{`match 1 { n => n }; n`, []string{"main.rl:1:21: n was not declare"}},
+		{`try { 1; } catch (e) { e; }; e`, []string{"main.rl:1:30: e was not declare"}},
+		{`import "lib" as lib; lib::anything(other)`, []string{"main.rl:1:36: other was not declare"}},
+		{`let f = () => [a, b];`, []string{"main.rl:1:16: a was not declare", "main.rl:1:19: b was not declare"}},
+		{`export (f, g); export let f = () => 1;`, []string{"main.rl:1:12: g was not declare"}},
+		{`let f = () => { export let a = 1; a; };`, []string{"main.rl:1:24: export is only allowed at the top level of a module"}},
+	}
+	for _, test := range tests {
+		errors := Resolve(parse(test.input), object.NewEnvironment(), builtin.New())
+		if len(errors) != len(test.expected) {
+			t.Errorf("%s: expected errors %v and got %v", test.input, test.expected, errors)
+			continue
+		}
+		for i := range errors {
+			if errors[i] != test.expected[i] {
+				t.Errorf("%s: expected error %s and got %s", test.input, test.expected[i], errors[i])
+			}
+		}
+	}
+}
+
+func TestEnvironmentNames(t *testing.T) {
+	environment := object.NewEnvironment()
+	environment.SetVar("previous", &object.Integer{Value: 1})
+	if errors := Resolve(parse(`previous + 1`), environment, builtin.New()); len(errors) != 0 {
+		t.Errorf("names of the environment should be declared and got %v", errors)
+	}
+}
+
+func TestSlots(t *testing.T) {
+	program := parse(`let g = 1; let f = (a, b) => { let c = a; let h = x => match x { y => [y, c, b, g] }; h; };`)
+	Resolve(program, object.NewEnvironment(), builtin.New())
+	function := program.Statements[1].(*ast.LetStatement).Value.(*ast.FunctionExpression)
+	expectedLocals := []string{"a", "b", "c", "h"}
+	if len(function.Locals) != len(expectedLocals) {
+		t.Fatalf("expected locals %v and got %v", expectedLocals, function.Locals)
+	}
+	for i, name := range expectedLocals {
+		if function.Locals[i] != name {
+			t.Errorf("expected local %s at %d and got %s", name, i, function.Locals[i])
+		}
+	}
+	inner := function.Block.Statements[1].(*ast.LetStatement).Value.(*ast.FunctionExpression)
+	match := inner.Block.Statements[0].(*ast.ReturnStatement).Value.(*ast.MatchExpression)
+	list := match.Arms[0].Body.(*ast.ListLiteral)
+	expected := []struct {
+		scope ast.IdentifierScope
+		depth int
+		slot  int
+	}{{ast.LocalScope, 0, 0}, {ast.LocalScope, 2, 2}, {ast.LocalScope, 2, 1}, {ast.GlobalScope, 0, 0}}
+	for i, element := range list.Elements {
+		identifier := element.(*ast.Identifier)
+		if identifier.Scope != expected[i].scope || identifier.Depth != expected[i].depth || identifier.Slot != expected[i].slot {
+			t.Errorf("%s: expected %v and got %d %d %d", identifier.Value, expected[i], identifier.Scope, identifier.Depth, identifier.Slot)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+export let identity = x => x;
+export let constant = x => ignored => x;
+export let flip = f => (a, b) => f(b, a);
+export let negate = f => x => !f(x);
+export let compose_all = fs => reduce((f, g) => f . g, fs, identity);
+export let pipe_all = fs => reduce((f, g) => g . f, fs, identity);
+
+let times_from = (n, f, x) => if (n > 0) { times_from(n - 1, f, f(x)); } else { x; };
+export let times = (n, f, x) => times_from(n, f, x);
+export let until = (done, f, x) => if (done(x)) { x; } else { until(done, f, f(x)); };
+export let tap = (f, x) => {
+	f(x);
+	x;
+};
+export let on = (combine, key) => (a, b) => combine(key(a), key(b));
@@ -0,0 +1,46 @@
+let equal = (a, b) => !(a != b);
+
+export let is_empty = xs => len(xs) == 0;
+export let head = xs => xs[0];
+export let last = xs => xs[-1];
+export let tail = xs => xs[1:];
+
+let reverse_from = (xs, i, acc) => if (i < 0) { acc; } else { reverse_from(xs, i - 1, append(acc, xs[i])); };
+export let reverse = xs => reverse_from(xs, len(xs) - 1, []);
+
+export let sum = xs => reduce((a, b) => a + b, xs, 0);
+export let product = xs => reduce((a, b) => a * b, xs, 1);
+export let count = (f, xs) => len(filter(f, xs));
+
+let index_from = (f, xs, i) => if (i == len(xs)) { -1; } else { if (f(xs[i])) { i; } else { index_from(f, xs, i + 1); }; };
+export let find_index = (f, xs) => index_from(f, xs, 0);
+export let find = (f, default, xs) => {
+	let i = find_index(f, xs);
+	if (i < 0) { default; } else { xs[i]; };
+};
+export let any = (f, xs) => find_index(f, xs) > -1;
+export let all = (f, xs) => find_index(x => !f(x), xs) < 0;
+export let contains = (value, xs) => any(x => equal(x, value), xs);
+
+export let flatten = xss => reduce((acc, xs) => concat(acc, xs), xss, []);
+export let flat_map = (f, xs) => flatten(map(f, xs));
+export let enumerate = xs => collect(zip(range(0), xs));
+
+let chunk_from = (n, xs, acc) => if (len(xs) > n) { chunk_from(n, xs[n:], append(acc, xs[:n])); } else { if (len(xs) == 0) { acc; } else { append(acc, xs); }; };
+export let chunk = (n, xs) => if (n > 0) { chunk_from(n, xs, []); } else { raise("chunk size should be greater than 0"); };
+
+let unique_from = (xs, i, seen, acc) => if (i == len(xs)) { acc; } else {
+	if (has(seen, xs[i])) { unique_from(xs, i + 1, seen, acc); } else { unique_from(xs, i + 1, put(seen, xs[i], true), append(acc, xs[i])); };
+};
+export let unique = xs => unique_from(xs, 0, {}, []);
+
+let merge = (key, left, right, i, j, acc) => if (i == len(left)) { concat(acc, right[j:]); } else {
+	if (j == len(right)) { concat(acc, left[i:]); } else {
+		if (key(right[j]) < key(left[i])) { merge(key, left, right, i, j + 1, append(acc, right[j])); } else { merge(key, left, right, i + 1, j, append(acc, left[i])); };
+	};
+};
+export let sort_by = (key, xs) => if (len(xs) < 2) { xs; } else {
+	let middle = len(xs) / 2;
+	merge(key, sort_by(key, xs[:middle]), sort_by(key, xs[middle:]), 0, 0, []);
+};
+export let sort = xs => sort_by(x => x, xs);
@@ -0,0 +1,28 @@
+// Package std is the standard library of rootlang, modules written in rootlang that are shipped inside the binary and
+// imported with import "std/list" before the module search paths are tried. list has utilities for lists, strings has
+// helpers for strings and functional has combinators of functions. The value a function works on is its last param so
+// the functions can be used with the pipe operator, xs |> list::sum, and sort_by of list is a stable merge sort.
+package std
+
+import (
+	"embed"
+	"strings"
+)
+
+//go:embed *.rl
+var modules embed.FS
+
+// PREFIX starts the import path of the standard library modules
+const PREFIX = "std/"
+
+// Source returns the code of the module of the import path, false when the path is not a module of the library
+func Source(importPath string) (string, bool) {
+	if !strings.HasPrefix(importPath, PREFIX) {
+		return "", false
+	}
+	content, err := modules.ReadFile(strings.TrimPrefix(importPath, PREFIX) + ".rl")
+	if err != nil {
+		return "", false
+	}
+	return string(content), true
+}
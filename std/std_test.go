@@ -0,0 +1,27 @@
+package std
+
+import (
+	"testing"
+	"rootlang/lexer"
+	"rootlang/parser"
+)
+
+func TestSource(t *testing.T) {
+	for _, path := range []string{"std/list", "std/strings", "std/functional"} {
+		source, ok := Source(path)
+		if !ok {
+			t.Errorf("%s should be a module of the library", path)
+			continue
+		}
+		p := parser.New(lexer.NewWithFile(source, path+".rl"))
+		p.ParseProgram()
+		if len(p.GetErrors()) != 0 {
+			t.Errorf("%s has parser errors %v", path, p.GetErrors())
+		}
+	}
+	for _, path := range []string{"list", "std/missing", "/std/list", "std/../std/list"} {
+		if _, ok := Source(path); ok {
+			t.Errorf("%s should not be a module of the library", path)
+		}
+	}
+}
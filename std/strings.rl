@@ -0,0 +1,31 @@
+let equal = (a, b) => !(a != b);
+
+export let starts_with = (prefix, s) => if (len(prefix) > len(s)) { false; } else { equal(s[:len(prefix)], prefix); };
+export let ends_with = (suffix, s) => if (len(suffix) > len(s)) { false; } else { equal(s[len(s) - len(suffix):], suffix); };
+
+let index_from = (part, s, i) => if (i + len(part) > len(s)) { -1; } else {
+	if (equal(s[i:i + len(part)], part)) { i; } else { index_from(part, s, i + 1); };
+};
+export let index_of = (part, s) => index_from(part, s, 0);
+export let contains = (part, s) => index_of(part, s) > -1;
+
+export let chars = s => collect(map(i => s[i], range(0, len(s))));
+export let join = (separator, xs) => if (len(xs) == 0) { ""; } else { reduce((acc, x) => acc + separator + x, xs[1:], "" + xs[0]); };
+
+let split_from = (separator, s, acc) => {
+	let i = index_of(separator, s);
+	if (i < 0) { append(acc, s); } else { split_from(separator, s[i + len(separator):], append(acc, s[:i])); };
+};
+export let split = (separator, s) => if (len(separator) == 0) { chars(s); } else { split_from(separator, s, []); };
+export let replace = (old, new, s) => join(new, split(old, s));
+
+let repeat_into = (n, s, acc) => if (n > 0) { repeat_into(n - 1, s, acc + s); } else { acc; };
+export let repeat = (n, s) => repeat_into(n, s, "");
+export let reverse = s => reduce((acc, c) => c + acc, chars(s), "");
+export let pad_left = (width, pad, s) => if (len(s) < width) { pad_left(width, pad, pad + s); } else { s; };
+export let pad_right = (width, pad, s) => if (len(s) < width) { pad_right(width, pad, s + pad); } else { s; };
+
+let is_space = c => if (equal(c, " ")) { true; } else { if (equal(c, "\t")) { true; } else { if (equal(c, "\n")) { true; } else { equal(c, "\r"); }; }; };
+export let trim_left = s => if (len(s) == 0) { s; } else { if (is_space(s[0])) { trim_left(s[1:]); } else { s; }; };
+export let trim_right = s => if (len(s) == 0) { s; } else { if (is_space(s[-1])) { trim_right(s[:-1]); } else { s; }; };
+export let trim = s => trim_right(trim_left(s));
@@ -0,0 +1,510 @@
+package vm
+
+import (
+	"rootlang/ast"
+	"rootlang/object"
+	"rootlang/builtin"
+	"rootlang/compiler"
+	"rootlang/evaluator"
+	"rootlang/lexer"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// scope holds the slots of one function call, closures keep a reference to the scope where they were created
+type scope struct {
+	slots []object.Object
+	outer *scope
+}
+
+// nilLocal marks a slot bound to nil so it is not taken as a slot that was never set, like the nil slots of an environment
+type nilLocal struct{}
+
+func (n *nilLocal) Type() object.ObjectType { return "NIL_SLOT" }
+func (n *nilLocal) Inspect() string         { return "nil" }
+
+var nilSlot = &nilLocal{}
+
+func toSlot(value object.Object) object.Object {
+	if value == nil {
+		return nilSlot
+	}
+	return value
+}
+
+func fromSlot(value object.Object) object.Object {
+	if value == nilSlot {
+		return nil
+	}
+	return value
+}
+
+type Closure struct {
+	Name     string
+	Fn       *compiler.CompiledFunction
+	Bound    []object.Object
+	scope    *scope
+	globals  *object.Environment
+	builtins *builtin.Builtin
+}
+
+func (c *Closure) Type() object.ObjectType { return object.FUNCTION_OBJ }
+
+func (c *Closure) Inspect() string {
+	buffer := bytes.NewBufferString("(")
+	paramsText := make([]string, 0)
+	for _, param := range c.Params() {
+		paramsText = append(paramsText, param.String())
+	}
+	buffer.WriteString(strings.Join(paramsText, ","))
+	buffer.WriteString(")=>")
+	buffer.WriteString(c.Fn.Body.String())
+	return buffer.String()
+}
+
+func (c *Closure) FunctionName() string {
+	return c.Name
+}
+
+func (c *Closure) Params() []*ast.Identifier {
+	return c.Fn.Params[len(c.Bound):]
+}
+
+// Call runs the closure on a new machine, it is how builtins and the evaluator call back into compiled code
+func (c *Closure) Call(params ...object.Object) object.Object {
+	if len(params) > len(c.Params()) {
+		return newError(fmt.Sprintf("this function takes at least %d arguments (%d given)", len(c.Params()), len(params)))
+	}
+	if len(params) < len(c.Params()) {
+		return c.bind(params)
+	}
+	machine := New(c.globals, c.builtins)
+	machine.pushFrame(c, params, "", lexer.Position{})
+	return machine.run()
+}
+
+func (c *Closure) bind(params []object.Object) *Closure {
+	bound := make([]object.Object, 0, len(c.Bound)+len(params))
+	bound = append(append(bound, c.Bound...), params...)
+	return &Closure{Name: c.Name, Fn: c.Fn, Bound: bound, scope: c.scope, globals: c.globals, builtins: c.builtins}
+}
+
+type frame struct {
+	closure      *Closure
+	called       *Closure
+	function     *compiler.CompiledFunction
+	scope        *scope
+	ip           int
+	base         int
+	module       string
+	callPosition lexer.Position
+	tailCall     *Closure
+	tailPosition lexer.Position
+}
+
+type handler struct {
+	frame   int
+	sp      int
+	catchIP int
+}
+
+type VM struct {
+	globals  *object.Environment
+	builtins *builtin.Builtin
+	stack    []object.Object
+	frames   []*frame
+	handlers []handler
+	err      *object.ErrorObject
+}
+
+func New(globals *object.Environment, builtins *builtin.Builtin) *VM {
+	return &VM{globals: globals, builtins: builtins, stack: make([]object.Object, 0, 64), frames: make([]*frame, 0, 16), handlers: make([]handler, 0)}
+}
+
+// Run executes a compiled program, its lets are stored in the globals environment of the machine
+func (vm *VM) Run(fn *compiler.CompiledFunction) object.Object {
+	vm.frames = append(vm.frames, &frame{function: fn, scope: &scope{slots: make([]object.Object, fn.NumSlots)}})
+	return vm.run()
+}
+
+func CallMainFunction(function *Closure) object.Object {
+	returnValue := function.Call()
+	if errorObject, ok := returnValue.(*object.ErrorObject); ok {
+		errorObject.AddFrame(function.Name, "", lexer.Position{})
+	}
+	return returnValue
+}
+
+func (vm *VM) pushFrame(closure *Closure, params []object.Object, module string, callPosition lexer.Position) {
+	vm.frames = append(vm.frames, &frame{closure: closure, called: closure, function: closure.Fn, scope: closure.newScope(params),
+		base: len(vm.stack), module: module, callPosition: callPosition})
+}
+
+func (c *Closure) newScope(params []object.Object) *scope {
+	slots := make([]object.Object, c.Fn.NumSlots)
+	for i, value := range c.Bound {
+		slots[i] = toSlot(value)
+	}
+	for i, param := range params {
+		slots[len(c.Bound)+i] = toSlot(param)
+	}
+	return &scope{slots: slots, outer: c.scope}
+}
+
+func (vm *VM) push(value object.Object) {
+	vm.stack = append(vm.stack, value)
+}
+
+func (vm *VM) pop() object.Object {
+	value := vm.stack[len(vm.stack)-1]
+	vm.stack = vm.stack[:len(vm.stack)-1]
+	return value
+}
+
+func (vm *VM) popN(n int) []object.Object {
+	values := make([]object.Object, n)
+	copy(values, vm.stack[len(vm.stack)-n:])
+	vm.stack = vm.stack[:len(vm.stack)-n]
+	return values
+}
+
+func (vm *VM) run() object.Object {
+	for {
+		current := vm.frames[len(vm.frames)-1]
+		ip := current.ip
+		op := compiler.Opcode(current.function.Instructions[ip])
+		current.ip++
+		switch op {
+		case compiler.OpConstant:
+			constant := current.function.Unit.Constants[vm.readUint16(current)]
+			if str, ok := constant.(*object.String); ok {
+				constant = &object.String{Value: str.Value}
+			}
+			vm.push(constant)
+		case compiler.OpNil:
+			vm.push(nil)
+		case compiler.OpTrue:
+			vm.push(object.TRUE)
+		case compiler.OpFalse:
+			vm.push(object.FALSE)
+		case compiler.OpPop:
+			vm.pop()
+		case compiler.OpAdd, compiler.OpSub, compiler.OpMul, compiler.OpDiv, compiler.OpMod,
+			compiler.OpEqual, compiler.OpNotEqual, compiler.OpLessThan, compiler.OpGreaterThan:
+			right := vm.pop()
+			left := vm.pop()
+			vm.pushResult(evaluator.Infix(compiler.Operators[op], left, right))
+		case compiler.OpCompose:
+			right := vm.pop()
+			left := vm.pop()
+			vm.pushResult(evaluator.Compose(left, right, current.function.PositionAt(ip).Position))
+		case compiler.OpMinus:
+			vm.pushResult(evaluator.Prefix("-", vm.pop()))
+		case compiler.OpNot:
+			vm.pushResult(evaluator.Prefix("!", vm.pop()))
+		case compiler.OpJump:
+			current.ip = vm.readUint16(current)
+		case compiler.OpJumpNotTruthy:
+			target := vm.readUint16(current)
+			if !evaluator.TruthValue(vm.pop()) {
+				current.ip = target
+			}
+		case compiler.OpGetGlobal:
+			vm.pushResult(vm.getGlobal(current.function.Unit.Names[vm.readUint16(current)]))
+		case compiler.OpSetGlobal:
+			name := current.function.Unit.Names[vm.readUint16(current)]
+			vm.globals.SetVar(name, nameFunction(vm.pop(), name))
+		case compiler.OpGetLocal:
+			vm.pushResult(vm.getLocal(current, current.function.Lookups[vm.readUint16(current)]))
+		case compiler.OpSetLocal:
+			slot := vm.readUint16(current)
+			current.scope.slots[slot] = toSlot(nameFunction(vm.pop(), current.function.SlotNames[slot]))
+		case compiler.OpClosure:
+			function := current.function.Unit.Constants[vm.readUint16(current)].(*compiler.CompiledFunction)
+			vm.push(&Closure{Fn: function, scope: current.scope, globals: vm.globals, builtins: vm.builtins})
+		case compiler.OpCall, compiler.OpTailCall:
+			argc := vm.readUint8(current)
+			function := vm.pop()
+			params := vm.popN(argc)
+			vm.call(current, function, params, "", current.function.PositionAt(ip).CallPosition, op == compiler.OpTailCall)
+		case compiler.OpPipeCall:
+			argc := vm.readUint8(current)
+			function := vm.pop()
+			params := vm.popN(argc + 1)
+			params = append(params[1:], params[0])
+			vm.call(current, function, params, "", current.function.PositionAt(ip).CallPosition, false)
+		case compiler.OpReturn:
+			value := vm.pop()
+			vm.leaveFrame()
+			if len(vm.frames) == 0 {
+				return value
+			}
+			vm.push(value)
+		case compiler.OpList:
+			vm.push(object.NewList(vm.popN(vm.readUint16(current))))
+		case compiler.OpDict:
+			pairs := vm.popN(vm.readUint16(current) * 2)
+			keys := make([]object.Object, 0, len(pairs)/2)
+			values := make([]object.Object, 0, len(pairs)/2)
+			for i := 0; i < len(pairs); i += 2 {
+				keys = append(keys, pairs[i])
+				values = append(values, pairs[i+1])
+			}
+			vm.pushResult(evaluator.NewDict(keys, values))
+		case compiler.OpIndex:
+			index := vm.pop()
+			left := vm.pop()
+			vm.pushResult(evaluator.Index(left, index))
+		case compiler.OpSlice:
+			bounds := vm.readUint8(current)
+			var start, end object.Object
+			if bounds&2 != 0 {
+				end = vm.pop()
+			}
+			if bounds&1 != 0 {
+				start = vm.pop()
+			}
+			vm.pushResult(evaluator.Slice(vm.pop(), start, end))
+		case compiler.OpModuleGet:
+			expression := current.function.Unit.Nodes[vm.readUint16(current)].(ast.Expression)
+			piped := vm.readUint8(current) == 1
+			vm.moduleGet(current, expression, piped, current.function.PositionAt(ip).CallPosition)
+		case compiler.OpModuleCall:
+			call := current.function.Unit.Nodes[vm.readUint16(current)].(*ast.CallFunctionExpression)
+			params := vm.popN(vm.readUint8(current))
+			piped := vm.readUint8(current) == 1
+			vm.moduleCall(current, call, params, piped, current.function.PositionAt(ip).CallPosition)
+		case compiler.OpImport:
+			importStatement := current.function.Unit.Nodes[vm.readUint16(current)].(*ast.ImportStatement)
+			if importStatement.Symbols == nil {
+				vm.pushResult(evaluator.Import(importStatement, vm.builtins))
+				break
+			}
+			values, err := evaluator.ImportSymbols(importStatement, vm.builtins)
+			if err != nil {
+				vm.pushResult(err)
+				break
+			}
+			for _, value := range values {
+				vm.push(value)
+			}
+		case compiler.OpTry:
+			catchIP := vm.readUint16(current)
+			vm.handlers = append(vm.handlers, handler{frame: len(vm.frames) - 1, sp: len(vm.stack), catchIP: catchIP})
+		case compiler.OpEndTry:
+			vm.handlers = vm.handlers[:len(vm.handlers)-1]
+		case compiler.OpMatch:
+			pattern := current.function.Patterns[vm.readUint16(current)]
+			subject := fromSlot(current.scope.slots[vm.readUint16(current)])
+			matched := evaluator.MatchPattern(pattern.Pattern, subject, func(name string, value object.Object) {
+				current.scope.slots[pattern.Bindings[name]] = toSlot(value)
+			})
+			vm.push(nativeToBooleanObject(matched))
+		case compiler.OpMatchFail:
+			subject := current.scope.slots[vm.readUint16(current)]
+			vm.pushResult(newError(fmt.Sprintf("no pattern matched %s", subject.Inspect())))
+		default:
+			vm.pushResult(newError(fmt.Sprintf("unknown opcode %d", op)))
+		}
+		if vm.err == nil {
+			continue
+		}
+		errorObject := vm.err
+		vm.err = nil
+		if !errorObject.Position.IsValid() {
+			errorObject.Position = current.function.PositionAt(ip).Position
+		}
+		if !vm.throw(errorObject) {
+			return errorObject
+		}
+	}
+}
+
+// pushResult pushes the result of an operation, errors are kept to be thrown after the instruction
+func (vm *VM) pushResult(value object.Object) {
+	if errorObject, ok := value.(*object.ErrorObject); ok {
+		vm.err = errorObject
+		return
+	}
+	vm.push(value)
+}
+
+// call runs closures in a new frame of the same loop, or reuses the current frame for tail calls, other functions are called through the evaluator
+func (vm *VM) call(current *frame, function object.Object, params []object.Object, module string, position lexer.Position, tail bool) {
+	closure, ok := function.(*Closure)
+	if !ok {
+		vm.pushResult(evaluator.CallFunction(function, params, module, position, vm.globals, vm.builtins))
+		return
+	}
+	arity := len(closure.Params())
+	if len(params) > arity {
+		vm.pushResult(newError(fmt.Sprintf("this function takes at least %d arguments (%d given)", arity, len(params))))
+		return
+	}
+	if len(params) < arity {
+		vm.push(closure.bind(params))
+		return
+	}
+	if tail {
+		current.closure, current.function, current.scope, current.ip = closure, closure.Fn, closure.newScope(params), 0
+		current.tailCall, current.tailPosition = closure, position
+		vm.stack = vm.stack[:current.base]
+		return
+	}
+	vm.pushFrame(closure, params, module, position)
+}
+
+func (vm *VM) leaveFrame() {
+	current := vm.frames[len(vm.frames)-1]
+	vm.frames = vm.frames[:len(vm.frames)-1]
+	for len(vm.handlers) > 0 && vm.handlers[len(vm.handlers)-1].frame >= len(vm.frames) {
+		vm.handlers = vm.handlers[:len(vm.handlers)-1]
+	}
+	vm.stack = vm.stack[:current.base]
+}
+
+// throw unwinds the frames recording them in the stack trace until a try takes the error, the caller of the machine records the entry frame
+func (vm *VM) throw(errorObject *object.ErrorObject) bool {
+	for index := len(vm.frames) - 1; index >= 0; index-- {
+		current := vm.frames[index]
+		if len(vm.handlers) > 0 && vm.handlers[len(vm.handlers)-1].frame == index {
+			tryHandler := vm.handlers[len(vm.handlers)-1]
+			vm.handlers = vm.handlers[:len(vm.handlers)-1]
+			vm.frames = vm.frames[:index+1]
+			vm.stack = vm.stack[:tryHandler.sp]
+			vm.push(&object.ErrorValue{Message: errorObject.Error, Position: errorObject.Position, Stack: errorObject.Stack})
+			current.ip = tryHandler.catchIP
+			return true
+		}
+		if current.tailCall != nil {
+			errorObject.AddFrame(current.tailCall.Name, "", current.tailPosition)
+		}
+		if index > 0 {
+			errorObject.AddFrame(current.called.Name, current.module, current.callPosition)
+		}
+	}
+	vm.frames = vm.frames[:0]
+	return false
+}
+
+func (vm *VM) getGlobal(name string) object.Object {
+	value, ok := vm.globals.GetVar(name)
+	if !ok {
+		value, ok = vm.builtins.GetObject(name)
+		if !ok {
+			return newError(fmt.Sprintf("%s was not declare", name))
+		}
+	}
+	return value
+}
+
+func (vm *VM) getLocal(current *frame, lookup compiler.Lookup) object.Object {
+	for _, slot := range lookup.Slots {
+		target := current.scope
+		for depth := 0; depth < slot.Depth; depth++ {
+			target = target.outer
+		}
+		if value := target.slots[slot.Slot]; value != nil {
+			return fromSlot(value)
+		}
+	}
+	return vm.getGlobal(lookup.Name)
+}
+
+func (vm *VM) moduleGet(current *frame, expression ast.Expression, piped bool, position lexer.Position) {
+	var pipedValue object.Object
+	module, err := vm.popModule()
+	if piped {
+		pipedValue = vm.pop()
+	}
+	if err != nil {
+		vm.pushResult(err)
+		return
+	}
+	identifier, ok := expression.(*ast.Identifier)
+	if !ok {
+		vm.pushResult(newError("expression not expected on module"))
+		return
+	}
+	value := evaluator.ModuleSymbol(module, identifier.Value)
+	if errorObject, ok := value.(*object.ErrorObject); ok {
+		vm.pushResult(errorObject)
+		return
+	}
+	if piped {
+		vm.call(current, value, []object.Object{pipedValue}, module.Name, position, false)
+		return
+	}
+	vm.push(value)
+}
+
+func (vm *VM) moduleCall(current *frame, call *ast.CallFunctionExpression, params []object.Object, piped bool, position lexer.Position) {
+	module, err := vm.popModule()
+	if piped {
+		params = append(params, vm.pop())
+	}
+	if err != nil {
+		vm.pushResult(err)
+		return
+	}
+	value := evaluator.ModuleFunction(module, call.Function, vm.builtins)
+	if errorObject, ok := value.(*object.ErrorObject); ok {
+		vm.pushResult(errorObject)
+		return
+	}
+	if value.Type() != object.FUNCTION_OBJ && value.Type() != object.BUILTIN_FUNCTION_OBJ {
+		vm.pushResult(newError(fmt.Sprintf("expected function and got %s", value.Type())))
+		return
+	}
+	vm.call(current, value, params, module.Name, position, false)
+}
+
+func (vm *VM) popModule() (*object.Module, *object.ErrorObject) {
+	value := vm.pop()
+	module, ok := value.(*object.Module)
+	if !ok {
+		return nil, newError("module was expected")
+	}
+	return module, nil
+}
+
+func (vm *VM) readUint16(current *frame) int {
+	value := int(compiler.ReadUint16(current.function.Instructions[current.ip:]))
+	current.ip += 2
+	return value
+}
+
+func (vm *VM) readUint8(current *frame) int {
+	value := int(current.function.Instructions[current.ip])
+	current.ip++
+	return value
+}
+
+func nameFunction(value object.Object, name string) object.Object {
+	if name == "" {
+		return value
+	}
+	switch function := value.(type) {
+	case *Closure:
+		if function.Name == "" {
+			function.Name = name
+		}
+	case *object.Function:
+		if function.Name == "" {
+			function.Name = name
+		}
+	}
+	return value
+}
+
+func nativeToBooleanObject(input bool) *object.Boolean {
+	if input {
+		return object.TRUE
+	}
+	return object.FALSE
+}
+
+func newError(message string) *object.ErrorObject {
+	return &object.ErrorObject{Error: message}
+}
@@ -0,0 +1,276 @@
+package vm
+
+import (
+	"testing"
+	"rootlang/lexer"
+	"rootlang/parser"
+	"rootlang/object"
+	"rootlang/builtin"
+	"rootlang/compiler"
+	"rootlang/evaluator"
+	"rootlang/evaluator/evaluatortest"
+	"rootlang/optimizer"
+	"rootlang/resolver"
+	"rootlang/ast"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// parse runs the passes main runs before a program is evaluated or compiled, the errors they find are the result
+func parse(input string, environment *object.Environment, builtinSymbols *builtin.Builtin) (*ast.Program, object.Object) {
+	programParser := parser.New(lexer.NewWithFile(input, "main.rl"))
+	program := programParser.ParseProgram()
+	if errors := programParser.GetErrors(); len(errors) != 0 {
+		return nil, &object.ErrorObject{Error: strings.Join(errors, "\n")}
+	}
+	optimizer.Optimize(program)
+	if errors := resolver.Resolve(program, environment, builtinSymbols); len(errors) != 0 {
+		return nil, &object.ErrorObject{Error: strings.Join(errors, "\n")}
+	}
+	return program, nil
+}
+
+func runVM(input string, builtinSymbols *builtin.Builtin) object.Object {
+	environment := object.NewEnvironment()
+	program, errorObject := parse(input, environment, builtinSymbols)
+	if errorObject != nil {
+		return errorObject
+	}
+	fn, err := compiler.New().Compile(program)
+	if err != nil {
+		return &object.ErrorObject{Error: err.Error()}
+	}
+	return New(environment, builtinSymbols).Run(fn)
+}
+
+func runEvaluator(input string, builtinSymbols *builtin.Builtin) object.Object {
+	environment := object.NewEnvironment()
+	program, errorObject := parse(input, environment, builtinSymbols)
+	if errorObject != nil {
+		return errorObject
+	}
+	return evaluator.Eval(program, environment, builtinSymbols)
+}
+
+func describe(value object.Object) string {
+	if value == nil {
+		return "<nil>"
+	}
+	if errorObject, ok := value.(*object.ErrorObject); ok {
+		return errorObject.Inspect() + "\n" + errorObject.StackTrace()
+	}
+	return value.Inspect()
+}
+
+func TestBackendsAgree(t *testing.T) {
+	inputs := []string{
+		`5`, `false`, `!!5`, `!0`, `(2 > 3) == true`, `0xFF == 255`,
+		`if(false){20}`, `if(true){}else{10}`, `if(2<3){40+20}else{10}`,
+		`9;return 20;10`, `return 9;return 20;10`, `if (20>1){if(20>1){return 11;} return 12;}`,
+		`"abc"[1:]`,
+		`let inc = x => x + 1; let double = x => x * 2; (inc . double)(5)`,
+		`let add = (x, y) => x + y; let h = add(1) . len; h("abc")`,
+		`let is_even = x => x % 2 == 0; filter(is_even . len, ["a", "ab", "abcd"])`,
+		`[1, 2, 3, 4] |> map(x => x * 2) |> filter(x => x > 4) |> reduce((x, y) => x + y)`,
+		`let inc = x => x + 1; 5 |> inc |> inc`, `"hola" |> bytes::create_writer("ab")`,
+		`try { 10 / 0; } catch (e) { error_message(e); }`, `try { x; } catch { "missing"; }`,
+		`let f = () => { try { return 1; } catch (e) { return 2; }; return 3; }; f()`,
+		`let f = x => { return 10 / x; }; map(x => try { f(x); } catch (e) { -1; }, [1, 0, 5])`,
+		`let e = error("boom"); [1, e][1]`, `raise("boom")`, `try { try { 1 / 0; } catch (e) { raise(e); }; } catch (e) { error_message(e); }`,
+		`let e = 5; let v = try { 1 / 0; } catch (e) { 0; }; [e, v]`,
+		`let f = () => { let e = 5; try { 1 / 0; } catch (e) { let k = e; }; let g = try { 1 / 0; } catch (e) { () => error_message(e); }; [e, g()]; }; f()`,
+		`import "std/list" as list; import { split, join } from "std/strings"; [list::sort([3, 1, 2]), join("+", split(",", "a,b"))]`,
+		`match 1 { 0 => "zero", 1 => "one", _ => "many" }`, `match -1 { -1 => "minus", _ => "other" }`,
+		`match 20 { n if n > 10 => n * 2, n => n }`, `match [1, 2, 3] { [] => 0, [x, ...rest] => rest }`,
+		`let fact = (n, acc) => if (n < 2) { acc; } else { fact(n - 1, acc * n); }; fact(30, 1)`,
+		`let big = 9223372036854775807 + 1; [big, big - 1, -big, big % 7, big > 1, big == big * 1]`,
+		`match [1, [2, 3]] { [a, [b, c]] => a + b + c }`, `match "xs" { [x, ..._] => 1, _ => 0 }`,
+		`let sum = xs => match xs { [] => 0, [x, ...rest] => x + sum(rest) }; sum([1, 2, 3, 4])`,
+		`let f = x => { match x { 0 => { return "early"; }, _ => 1 }; return "late"; }; f(0)`,
+		`let n = 1; match 5 { n => n }; n`, `match 3 { 1 => 1 }`, `match 3 { n if n / 0 => 1 }`,
+		`let count = (n, acc) => if (n == 0) { acc; } else { count(n - 1, acc + 1); }; let add = count(2000); add(1)`,
+		`let f = n => if (n == 0) { 10 / n; } else { f(n - 1); }; try { f(2000); } catch (e) { error_message(e); }`,
+		`let f = x => { return x + 1; }; return f(1);`,
+		`let x = 1; let f = () => { let x = if (false) { 1; }; x; }; f()`, `let f = () => { let y = if (false) { 1; }; y; }; f()`,
+		`let a = 7; let f = (a, b) => a; f(if (false) { 1; }, 2)`, `let a = 7; let f = (a, b) => a; let g = f(if (false) { 1; }); g(3)`,
+		"let x = 5;\nlet y = x + z;", "let x = 5;\n  len(x);",
+		"let f = n => {\n  return 10 / n;\n};\nlet g = n => f(n) + 1;\ng(0);",
+		"let fail = n => 10 / n;\nlet loop = n => if (n == 0) { fail(n); } else { loop(n - 1); };\nloop(3) + 1;",
+		`let add = (x,y)=>{return x+y;}; add(5)`, `(x => x)`,
+		`{"a": [1, 2], "b": {"c": 3}}["b"]["c"]`, `"a" + "b"`, `-true`, `let x = 1; let x = 2; x`,
+	}
+	for _, cases := range [][]evaluatortest.Case{evaluatortest.Integers, evaluatortest.Booleans, evaluatortest.Ifs, evaluatortest.Arithmetic,
+		evaluatortest.FunctionCalls, evaluatortest.Closures, evaluatortest.Strings, evaluatortest.Dicts, evaluatortest.Lists,
+		evaluatortest.Matches, evaluatortest.Tries, evaluatortest.Errors, evaluatortest.Modules} {
+		for _, test := range cases {
+			inputs = append(inputs, test.Input)
+		}
+	}
+	remove, err := evaluatortest.WriteModules("/tmp/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer remove()
+	withModules := func() *builtin.Builtin {
+		builtinSymbols := builtin.New()
+		builtinSymbols.RegisterPath("/tmp/")
+		return builtinSymbols
+	}
+	for _, input := range inputs {
+		expected := describe(runEvaluator(input, withModules()))
+		got := describe(runVM(input, withModules()))
+		if expected != got {
+			t.Errorf("%s\nevaluator returned\n%s\nvm returned\n%s", input, expected, got)
+		}
+	}
+}
+
+func TestReplLines(t *testing.T) {
+	lines := []struct {
+		input    string
+		expected string
+	}{
+		{`let even = n => if (n == 0) { true; } else { odd(n - 1); };`, "<nil>"},
+		{`let odd = n => if (n == 0) { false; } else { even(n - 1); };`, "<nil>"},
+		{`[even(10), odd(7), even(3)]`, "[true,true,false]"},
+		{`let later = () => missing;`, "<nil>"},
+		{`later()`, "1:19: missing was not declare"},
+	}
+	environment := object.NewEnvironment()
+	builtinSymbols := builtin.New()
+	machine := New(environment, builtinSymbols)
+	for _, line := range lines {
+		program := parser.New(lexer.New(line.input)).ParseProgram()
+		if errors := resolver.ResolveLine(program, environment, builtinSymbols); len(errors) != 0 {
+			t.Fatalf("%s: %v", line.input, errors)
+		}
+		fn, err := compiler.New().Compile(program)
+		if err != nil {
+			t.Fatalf("%s: %v", line.input, err)
+		}
+		returnValue := machine.Run(fn)
+		got := "<nil>"
+		if returnValue != nil {
+			got = returnValue.Inspect()
+		}
+		if got != line.expected {
+			t.Errorf("%s: expected %s and got %s", line.input, line.expected, got)
+		}
+	}
+}
+
+func TestClosuresShareVariables(t *testing.T) {
+	input := `let counter = () => { let n = 0; let inc = () => { let n = n + 1; n; }; [inc, () => n]; };
+	let c = counter(); c[0](); c[0](); c[1]()`
+	expected := describe(runEvaluator(input, builtin.New()))
+	if got := describe(runVM(input, builtin.New())); got != expected {
+		t.Errorf("expected %s and got %s", expected, got)
+	}
+}
+
+func TestDeepTailRecursion(t *testing.T) {
+	input := `let count = (n, acc) => { if (n == 0) { return acc; } return count(n - 1, acc + 1); }; count(1000000, 0)`
+	returnValue := runVM(input, builtin.New())
+	if returnValue == nil || returnValue.Inspect() != "1000000" {
+		t.Errorf("expected 1000000 and got %v", returnValue)
+	}
+}
+
+func TestModuleCall(t *testing.T) {
+	modulePath := "/tmp/testVM.rl"
+	ioutil.WriteFile(modulePath, []byte("let add = (x, y) => x + y; let inc = x => x + 1;"), 0644)
+	defer os.Remove(modulePath)
+	input := `import "testVM" as test; let x = 10 |> test::add(5) |> test::inc; [x, test::inc(1), test::add(1)(2)]`
+	builtinSymbols := builtin.New()
+	builtinSymbols.RegisterPath("/tmp/")
+	returnValue := runVM(input, builtinSymbols)
+	if returnValue == nil || returnValue.Inspect() != "[16,2,3]" {
+		t.Errorf("expected [16,2,3] and got %v", returnValue)
+	}
+}
+
+func TestModuleExports(t *testing.T) {
+	modulePath := "/tmp/testVMExport.rl"
+	ioutil.WriteFile(modulePath, []byte("let helper = x => x + 1; export let inc = x => helper(x);"), 0644)
+	defer os.Remove(modulePath)
+	builtinSymbols := builtin.New()
+	builtinSymbols.RegisterPath("/tmp/")
+	returnValue := runVM(`import "testVMExport" as test; [test::inc(1), 2 |> test::inc]`, builtinSymbols)
+	if returnValue == nil || returnValue.Inspect() != "[2,3]" {
+		t.Errorf("expected [2,3] and got %v", returnValue)
+	}
+	for _, input := range []string{`import "testVMExport" as test; test::helper(1)`, `import "testVMExport" as test; test::helper`} {
+		returnValue = runVM(input, builtinSymbols)
+		if returnValue == nil || !strings.HasSuffix(returnValue.Inspect(), "symbol helper is not exported by module test") {
+			t.Errorf("%s: expected the not exported error and got %v", input, returnValue)
+		}
+	}
+}
+
+func TestSelectiveImport(t *testing.T) {
+	modulePath := "/tmp/testVMSelective.rl"
+	ioutil.WriteFile(modulePath, []byte("let add = (x, y) => x + y; let base = 10;"), 0644)
+	defer os.Remove(modulePath)
+	builtinSymbols := builtin.New()
+	builtinSymbols.RegisterPath("/tmp/")
+	input := `import { add as plus, base } from "testVMSelective"; let f = () => { import { base as b } from "testVMSelective"; b; }; [plus(base, 1), f()]`
+	returnValue := runVM(input, builtinSymbols)
+	if returnValue == nil || returnValue.Inspect() != "[11,10]" {
+		t.Errorf("expected [11,10] and got %v", returnValue)
+	}
+	returnValue = runVM(`import { missing } from "testVMSelective";`, builtinSymbols)
+	if returnValue == nil || !strings.HasSuffix(returnValue.Inspect(), "symbol missing not found in module testVMSelective") {
+		t.Errorf("expected the not found error and got %v", returnValue)
+	}
+}
+
+func TestNativeCallback(t *testing.T) {
+	builtinSymbols := builtin.New()
+	builtinSymbols.RegisterModule("company", map[string]builtin.NativeFunction{
+		"apply": func(caller *builtin.Caller, params ...object.Object) object.Object {
+			return caller.Call(params[0], params[1])
+		},
+	})
+	returnValue := runVM(`import "/company" as company; let offset = 10; company::apply(x => x + offset, 5)`, builtinSymbols)
+	if returnValue == nil || returnValue.Inspect() != "15" {
+		t.Errorf("expected 15 and got %v", returnValue)
+	}
+}
+
+func TestCallMainFunction(t *testing.T) {
+	input := `let fail = n => {
+  return 10 / n;
+};
+let loop = n => if (n == 0) { fail(n); } else { loop(n - 1); };
+let main = () => {
+  return loop(3) + 1;
+};`
+	expectedTrace := "  at fail (main.rl:2:13)\n" +
+		"  at loop (main.rl:4:31)\n" +
+		"  at main (main.rl:6:10)\n"
+	l := lexer.NewWithFile(input, "main.rl")
+	program := parser.New(l).ParseProgram()
+	fn, err := compiler.New().Compile(program)
+	if err != nil {
+		t.Fatal(err)
+	}
+	globals := object.NewEnvironment()
+	New(globals, builtin.New()).Run(fn)
+	mainFunction, _ := globals.GetVar("main")
+	returnValue := CallMainFunction(mainFunction.(*Closure))
+	errorObject, ok := returnValue.(*object.ErrorObject)
+	if !ok {
+		t.Fatalf("should return error object and got %v", returnValue)
+	}
+	if errorObject.StackTrace() != expectedTrace {
+		t.Errorf("stack trace expected\n%s and got\n%s", expectedTrace, errorObject.StackTrace())
+	}
+}
+
+func BenchmarkFib(b *testing.B) {
+	input := `let fib = n => if (n < 2) { n; } else { fib(n - 1) + fib(n - 2); }; fib(20)`
+	for i := 0; i < b.N; i++ {
+		runVM(input, builtin.New())
+	}
+}